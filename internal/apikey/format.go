@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Keys look like tb_<lookup id>_<secret>. The lookup ID is stored as-is so the key's row can be
+// found with one query; only the secret is hashed.
+const (
+	keyPrefix   = "tb_"
+	lookupIDLen = 12
+	secretBytes = 32
+
+	// legacyPrefix marks the lookup IDs given to keys issued before the current format. Those keys
+	// have no public part, so their lookup ID is derived from a digest of the whole key instead.
+	legacyPrefix = "legacy_"
+)
+
+// Generate returns a new random key and its lookup ID.
+func Generate() (key, lookupID string, err error) {
+	id := make([]byte, lookupIDLen/2)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("generating lookup id: %w", err)
+	}
+
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("generating key: %w", err)
+	}
+
+	lookupID = hex.EncodeToString(id)
+	return keyPrefix + lookupID + "_" + base64.RawURLEncoding.EncodeToString(secret), lookupID, nil
+}
+
+// LookupID returns the lookup ID for a key in either the current or the legacy format.
+func LookupID(key string) string {
+	if id, ok := parse(key); ok {
+		return id
+	}
+
+	return legacyLookupID(key)
+}
+
+// parse returns the lookup ID embedded in a key, or false if the key isn't in the current format.
+func parse(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, keyPrefix)
+	if !ok || len(rest) <= lookupIDLen+1 || rest[lookupIDLen] != '_' {
+		return "", false
+	}
+
+	id := rest[:lookupIDLen]
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+
+	return id, true
+}
+
+func legacyLookupID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return legacyPrefix + hex.EncodeToString(sum[:12])
+}
@@ -0,0 +1,224 @@
+package apikey
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidKey = errors.New("invalid api key")
+
+const (
+	// cacheTTL is how long a key that passed bcrypt is trusted without checking the hash again. The
+	// row is still fetched on every request, so deleting or expiring a key takes effect immediately.
+	cacheTTL  = 5 * time.Minute
+	maxCached = 1024
+
+	// Keys that don't match any legacy key are remembered for missTTL, so repeating a bad key doesn't
+	// cost another scan.
+	missTTL   = 10 * time.Minute
+	maxMisses = 4096
+
+	// legacyScansPerWindow caps how many legacy scans run per legacyScanWindow across all clients.
+	// Each scan is a bcrypt comparison per legacy key, and any token not in the current format can
+	// start one.
+	legacyScansPerWindow = 30
+	legacyScanWindow     = time.Minute
+)
+
+// Verifier finds the stored key for a presented API key with a single lookup by its public ID,
+// instead of comparing it against every stored hash.
+type Verifier struct {
+	keys repos.APIKeyRepository
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]verifiedKey
+
+	// legacyMu guards misses and the scan window
+	legacyMu    sync.Mutex
+	misses      map[[sha256.Size]byte]time.Time
+	windowStart time.Time
+	windowScans int
+	windowFull  bool
+	// noLegacy is set once no keys without a lookup ID are left. New keys always get one, so
+	// after that the scan is never needed again.
+	noLegacy atomic.Bool
+}
+
+type verifiedKey struct {
+	keyID      int
+	hash       []byte
+	verifiedOn time.Time
+}
+
+func NewVerifier(keys repos.APIKeyRepository) *Verifier {
+	return &Verifier{
+		keys:   keys,
+		cache:  make(map[[sha256.Size]byte]verifiedKey),
+		misses: make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+// Verify returns the stored key matching plain, or ErrInvalidKey if there isn't one. Legacy keys
+// without a lookup ID are found by checking each of them, and are given one on their first use so
+// later requests take the fast path. Those scans are rate limited and skipped for keys that
+// recently failed one.
+func (v *Verifier) Verify(ctx context.Context, plain string) (*models.APIKey, error) {
+	lookupID := LookupID(plain)
+	k, err := v.keys.GetByLookupID(ctx, lookupID)
+	if err != nil {
+		if !errors.Is(err, models.ErrAPIKeyNotFound) {
+			return nil, fmt.Errorf("getting key by lookup id: %w", err)
+		}
+		if _, ok := parse(plain); ok {
+			return nil, ErrInvalidKey
+		}
+		return v.migrateLegacy(ctx, plain, lookupID)
+	}
+
+	digest := sha256.Sum256([]byte(plain))
+	if v.cached(digest, k) {
+		return k, nil
+	}
+
+	if bcrypt.CompareHashAndPassword(k.KeyHash, []byte(plain)) != nil {
+		return nil, ErrInvalidKey
+	}
+
+	v.store(digest, k)
+	return k, nil
+}
+
+func (v *Verifier) migrateLegacy(ctx context.Context, plain, lookupID string) (*models.APIKey, error) {
+	if v.noLegacy.Load() {
+		return nil, ErrInvalidKey
+	}
+
+	digest := sha256.Sum256([]byte(plain))
+	if !v.allowScan(digest) {
+		return nil, ErrInvalidKey
+	}
+
+	legacy, err := v.keys.ListLegacy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing legacy keys: %w", err)
+	}
+
+	if len(legacy) == 0 {
+		v.noLegacy.Store(true)
+		slog.Info("no legacy api keys left, disabling legacy key scan")
+		return nil, ErrInvalidKey
+	}
+
+	for _, k := range legacy {
+		if bcrypt.CompareHashAndPassword(k.KeyHash, []byte(plain)) != nil {
+			continue
+		}
+
+		if err := v.keys.SetLookupID(ctx, k.ID, lookupID); err != nil {
+			slog.Warn("setting lookup id on legacy api key", "key_id", k.ID, "error", err.Error())
+		} else {
+			slog.Info("assigned lookup id to legacy api key", "key_id", k.ID)
+			k.LookupID = &lookupID
+		}
+
+		v.store(digest, k)
+		return k, nil
+	}
+
+	v.miss(digest)
+	return nil, ErrInvalidKey
+}
+
+// allowScan reports whether a legacy scan may run for the key with this digest: it hasn't failed
+// one within missTTL, and the global scan budget for the current window isn't used up.
+func (v *Verifier) allowScan(digest [sha256.Size]byte) bool {
+	v.legacyMu.Lock()
+	defer v.legacyMu.Unlock()
+
+	if t, ok := v.misses[digest]; ok {
+		if time.Since(t) < missTTL {
+			return false
+		}
+		delete(v.misses, digest)
+	}
+
+	if time.Since(v.windowStart) >= legacyScanWindow {
+		v.windowStart = time.Now()
+		v.windowScans = 0
+		v.windowFull = false
+	}
+	if v.windowScans >= legacyScansPerWindow {
+		if !v.windowFull {
+			v.windowFull = true
+			slog.Warn("legacy api key scan limit reached, rejecting unknown keys until the window resets", "window", legacyScanWindow.String())
+		}
+		return false
+	}
+
+	v.windowScans++
+	return true
+}
+
+func (v *Verifier) miss(digest [sha256.Size]byte) {
+	v.legacyMu.Lock()
+	defer v.legacyMu.Unlock()
+
+	if len(v.misses) >= maxMisses {
+		for d, t := range v.misses {
+			if time.Since(t) >= missTTL {
+				delete(v.misses, d)
+			}
+		}
+		if len(v.misses) >= maxMisses {
+			clear(v.misses)
+		}
+	}
+
+	v.misses[digest] = time.Now()
+}
+
+// cached reports whether this exact key was recently verified against k's current hash.
+func (v *Verifier) cached(digest [sha256.Size]byte, k *models.APIKey) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	e, ok := v.cache[digest]
+	if !ok {
+		return false
+	}
+	if e.keyID != k.ID || !bytes.Equal(e.hash, k.KeyHash) || time.Since(e.verifiedOn) >= cacheTTL {
+		delete(v.cache, digest)
+		return false
+	}
+
+	return true
+}
+
+func (v *Verifier) store(digest [sha256.Size]byte, k *models.APIKey) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.cache) >= maxCached {
+		for d, e := range v.cache {
+			if time.Since(e.verifiedOn) >= cacheTTL {
+				delete(v.cache, d)
+			}
+		}
+		if len(v.cache) >= maxCached {
+			clear(v.cache)
+		}
+	}
+
+	v.cache[digest] = verifiedKey{keyID: k.ID, hash: k.KeyHash, verifiedOn: time.Now()}
+}
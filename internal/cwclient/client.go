@@ -0,0 +1,171 @@
+package cwclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// Client wraps a psa.Client so every Connectwise request made by ticketbot, whether from a sync,
+// a webhook or the callback checks, shares one request budget and one circuit breaker.
+type Client struct {
+	cl  atomic.Pointer[psa.Client]
+	cfg *runtimecfg.Store
+
+	mu          sync.Mutex
+	tokens      float64
+	refilled    time.Time
+	inFlight    int
+	freed       chan struct{}
+	backoff     time.Duration
+	pausedUntil time.Time
+	failures    int
+	state       circuitState
+	openedOn    time.Time
+	probing     bool
+	stats       map[string]*endpointStats
+	deferred    []*deferredCall
+	draining    bool
+}
+
+func New(cl *psa.Client, cfg *runtimecfg.Store) *Client {
+	c := &Client{
+		cfg:   cfg,
+		freed: make(chan struct{}),
+		stats: make(map[string]*endpointStats),
+	}
+	c.cl.Store(cl)
+	cfg.Subscribe(c.limitsChanged)
+	return c
+}
+
+// SetClient swaps the underlying client, such as after the API keys are rotated. Requests already
+// under way finish on the old one.
+func (c *Client) SetClient(cl *psa.Client) {
+	c.cl.Store(cl)
+}
+
+// limitsChanged wakes requests waiting for a concurrency slot, so a raised or removed cap
+// applies to them straight away.
+func (c *Client) limitsChanged(prev, next *models.Config) {
+	if prev.CWMaxConcurrentRequests == next.CWMaxConcurrentRequests && prev.CWRequestsPerMinute == next.CWRequestsPerMinute {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	close(c.freed)
+	c.freed = make(chan struct{})
+}
+
+func (c *Client) GetTicket(ctx context.Context, id int, p map[string]string) (*psa.Ticket, error) {
+	return call(ctx, c, "GET service/tickets/:id", func(ctx context.Context) (*psa.Ticket, error) {
+		return c.cl.Load().GetTicket(ctx, id, p)
+	})
+}
+
+func (c *Client) GetMostRecentTicketNote(ctx context.Context, id int) (*psa.ServiceTicketNote, error) {
+	return call(ctx, c, "GET service/tickets/:id/notes", func(ctx context.Context) (*psa.ServiceTicketNote, error) {
+		return c.cl.Load().GetMostRecentTicketNote(ctx, id)
+	})
+}
+
+func (c *Client) ListTickets(ctx context.Context, p map[string]string) ([]psa.Ticket, error) {
+	return call(ctx, c, "GET service/tickets", func(ctx context.Context) ([]psa.Ticket, error) {
+		return c.cl.Load().ListTickets(ctx, p)
+	})
+}
+
+func (c *Client) GetBoard(ctx context.Context, id int, p map[string]string) (*psa.Board, error) {
+	return call(ctx, c, "GET service/boards/:id", func(ctx context.Context) (*psa.Board, error) {
+		return c.cl.Load().GetBoard(ctx, id, p)
+	})
+}
+
+func (c *Client) ListBoards(ctx context.Context, p map[string]string) ([]psa.Board, error) {
+	return call(ctx, c, "GET service/boards", func(ctx context.Context) ([]psa.Board, error) {
+		return c.cl.Load().ListBoards(ctx, p)
+	})
+}
+
+func (c *Client) GetBoardStatus(ctx context.Context, id int, p map[string]string, boardID int) (*psa.BoardStatus, error) {
+	return call(ctx, c, "GET service/boards/:id/statuses/:id", func(ctx context.Context) (*psa.BoardStatus, error) {
+		return c.cl.Load().GetBoardStatus(ctx, id, p, boardID)
+	})
+}
+
+func (c *Client) ListBoardStatuses(ctx context.Context, p map[string]string, boardID int) ([]psa.BoardStatus, error) {
+	return call(ctx, c, "GET service/boards/:id/statuses", func(ctx context.Context) ([]psa.BoardStatus, error) {
+		return c.cl.Load().ListBoardStatuses(ctx, p, boardID)
+	})
+}
+
+func (c *Client) GetCompany(ctx context.Context, id int, p map[string]string) (*psa.Company, error) {
+	return call(ctx, c, "GET company/companies/:id", func(ctx context.Context) (*psa.Company, error) {
+		return c.cl.Load().GetCompany(ctx, id, p)
+	})
+}
+
+func (c *Client) GetContact(ctx context.Context, id int, p map[string]string) (*psa.Contact, error) {
+	return call(ctx, c, "GET company/contacts/:id", func(ctx context.Context) (*psa.Contact, error) {
+		return c.cl.Load().GetContact(ctx, id, p)
+	})
+}
+
+func (c *Client) GetMember(ctx context.Context, id int, p map[string]string) (*psa.Member, error) {
+	return call(ctx, c, "GET system/members/:id", func(ctx context.Context) (*psa.Member, error) {
+		return c.cl.Load().GetMember(ctx, id, p)
+	})
+}
+
+func (c *Client) GetMemberByIdentifier(ctx context.Context, identifier string) (*psa.Member, error) {
+	return call(ctx, c, "GET system/members?identifier", func(ctx context.Context) (*psa.Member, error) {
+		return c.cl.Load().GetMemberByIdentifier(ctx, identifier)
+	})
+}
+
+func (c *Client) ListMembers(ctx context.Context, p map[string]string) ([]psa.Member, error) {
+	return call(ctx, c, "GET system/members", func(ctx context.Context) ([]psa.Member, error) {
+		return c.cl.Load().ListMembers(ctx, p)
+	})
+}
+
+func (c *Client) ListCallbacks(ctx context.Context, p map[string]string) ([]psa.Callback, error) {
+	return call(ctx, c, "GET system/callbacks", func(ctx context.Context) ([]psa.Callback, error) {
+		return c.cl.Load().ListCallbacks(ctx, p)
+	})
+}
+
+func (c *Client) PostCallback(ctx context.Context, cb *psa.Callback) (*psa.Callback, error) {
+	return call(ctx, c, "POST system/callbacks", func(ctx context.Context) (*psa.Callback, error) {
+		return c.cl.Load().PostCallback(ctx, cb)
+	})
+}
+
+func (c *Client) PutCallback(ctx context.Context, id int, cb *psa.Callback) (*psa.Callback, error) {
+	return call(ctx, c, "PUT system/callbacks/:id", func(ctx context.Context) (*psa.Callback, error) {
+		return c.cl.Load().PutCallback(ctx, id, cb)
+	})
+}
+
+func (c *Client) DeleteCallback(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE system/callbacks/:id", func(ctx context.Context) error {
+		return c.cl.Load().DeleteCallback(ctx, id)
+	})
+}
+
+// call adapts a psa method that returns a value to do.
+func call[T any](ctx context.Context, c *Client, endpoint string, fn func(context.Context) (T, error)) (T, error) {
+	var out T
+	err := c.do(ctx, endpoint, func(ctx context.Context) error {
+		var err error
+		out, err = fn(ctx)
+		return err
+	})
+	return out, err
+}
@@ -0,0 +1,100 @@
+package cwclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// maxDeferred caps the deferred queue so a long outage can't grow it without bound.
+const maxDeferred = 1000
+
+type deferredCall struct {
+	key string
+	ctx context.Context
+	fn  func(context.Context) error
+}
+
+// RunOrDefer runs fn, and if it fails because the circuit is open, queues it to run again once
+// Connectwise recovers rather than returning the error. Queued calls with the same key replace each
+// other, so repeated webhooks for one entity only run once.
+func (c *Client) RunOrDefer(ctx context.Context, key string, fn func(context.Context) error) error {
+	err := fn(ctx)
+	if !errors.Is(err, ErrCircuitOpen) {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enqueue(&deferredCall{key: key, ctx: context.WithoutCancel(ctx), fn: fn})
+	slog.WarnContext(ctx, "connectwise unavailable, deferring", "key", key, "deferred", len(c.deferred))
+
+	return nil
+}
+
+// enqueue must be called with c.mu held.
+func (c *Client) enqueue(d *deferredCall) {
+	for i, q := range c.deferred {
+		if q.key == d.key {
+			c.deferred[i] = d
+			return
+		}
+	}
+
+	if len(c.deferred) >= maxDeferred {
+		slog.Warn("connectwise deferred queue full, dropping oldest", "key", c.deferred[0].key)
+		c.deferred = c.deferred[1:]
+	}
+	c.deferred = append(c.deferred, d)
+	c.startDrain()
+}
+
+// startDrain must be called with c.mu held.
+func (c *Client) startDrain() {
+	if c.draining || len(c.deferred) == 0 {
+		return
+	}
+	c.draining = true
+	go c.drain()
+}
+
+// drain works through the deferred queue one call at a time. While the circuit is open, it waits
+// for the cooldown so the first call it runs is the probe that decides whether to close it.
+func (c *Client) drain() {
+	for {
+		c.mu.Lock()
+		if len(c.deferred) == 0 {
+			c.draining = false
+			c.mu.Unlock()
+			return
+		}
+		d := c.deferred[0]
+		c.deferred = c.deferred[1:]
+		wait := c.untilHalfOpen()
+		c.mu.Unlock()
+
+		time.Sleep(wait)
+
+		err := d.fn(d.ctx)
+		if errors.Is(err, ErrCircuitOpen) {
+			c.mu.Lock()
+			c.deferred = append([]*deferredCall{d}, c.deferred...)
+			wait = max(c.untilHalfOpen(), time.Second)
+			c.mu.Unlock()
+			time.Sleep(wait)
+			continue
+		}
+		if err != nil {
+			slog.Error("running deferred connectwise call", "key", d.key, "error", err.Error())
+		}
+	}
+}
+
+// untilHalfOpen must be called with c.mu held.
+func (c *Client) untilHalfOpen() time.Duration {
+	if c.state != circuitOpen {
+		return 0
+	}
+	return max(0, openCooldown-time.Since(c.openedOn))
+}
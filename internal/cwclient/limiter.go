@@ -0,0 +1,285 @@
+package cwclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/internal/metrics"
+	"github.com/thecoretg/ticketbot/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrCircuitOpen is returned without calling Connectwise while the circuit is open.
+var ErrCircuitOpen = errors.New("connectwise circuit open")
+
+var statusPattern = regexp.MustCompile(`(?i)status(?: code)?:?\s*(\d{3})\b`)
+
+const (
+	// failureThreshold is how many failed requests in a row open the circuit.
+	failureThreshold = 5
+	// openCooldown is how long the circuit stays open before a single probe request is let through.
+	openCooldown = 30 * time.Second
+
+	maxRetries = 3
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeThrottled
+	outcomeFailed
+	outcomeIgnored
+)
+
+// do runs fn once the request budget allows it, retrying after a backoff if Connectwise responds
+// with 429 or 503. It returns ErrCircuitOpen without running fn if the circuit is open.
+func (c *Client) do(ctx context.Context, endpoint string, fn func(context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		// the span starts before acquire so time spent waiting on the limiter shows in traces
+		sctx, span := tracing.Start(ctx, "connectwise "+endpoint,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.Int("connectwise.attempt", attempt+1)),
+		)
+		if err := c.acquire(sctx); err != nil {
+			if errors.Is(err, ErrCircuitOpen) {
+				c.recordRejected(endpoint)
+			}
+			tracing.End(span, err)
+			return err
+		}
+		span.AddEvent("acquired")
+
+		start := time.Now()
+		err := fn(sctx)
+		took := time.Since(start)
+
+		status := statusLabel(err)
+		metrics.APIRequest(metrics.ServiceConnectwise, endpoint, status, took)
+		if code, cerr := strconv.Atoi(status); cerr == nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(code))
+		}
+		tracing.End(span, err)
+
+		o := c.release(ctx, endpoint, took, err)
+		if o != outcomeThrottled || attempt >= maxRetries {
+			return err
+		}
+
+		slog.Debug("connectwise request throttled, retrying", "endpoint", endpoint, "attempt", attempt+1, "error", err.Error())
+	}
+}
+
+// acquire blocks until a request can be sent: the circuit allows it, any backoff has passed,
+// there is a token in the budget, and a concurrency slot is free.
+func (c *Client) acquire(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		now := time.Now()
+
+		if c.state == circuitOpen && now.Sub(c.openedOn) >= openCooldown {
+			c.state = circuitHalfOpen
+		}
+		if c.state == circuitOpen || (c.state == circuitHalfOpen && c.probing) {
+			c.mu.Unlock()
+			return ErrCircuitOpen
+		}
+
+		var wait time.Duration
+		var freed chan struct{}
+		cfg := c.cfg.Load()
+		rpm := cfg.CWRequestsPerMinute
+		maxConc := cfg.CWMaxConcurrentRequests
+
+		switch {
+		case now.Before(c.pausedUntil):
+			wait = c.pausedUntil.Sub(now)
+		case rpm > 0 && c.refill(now, rpm) < 1:
+			wait = time.Duration((1 - c.tokens) / perSecond(rpm) * float64(time.Second))
+		case maxConc > 0 && c.inFlight >= maxConc:
+			freed = c.freed
+		default:
+			if rpm > 0 {
+				c.tokens--
+			}
+			c.inFlight++
+			if c.state == circuitHalfOpen {
+				c.probing = true
+			}
+			c.mu.Unlock()
+			return nil
+		}
+		c.mu.Unlock()
+
+		if err := sleep(ctx, wait, freed); err != nil {
+			return err
+		}
+	}
+}
+
+// release records the result of a request and updates the backoff and circuit state.
+func (c *Client) release(ctx context.Context, endpoint string, took time.Duration, err error) outcome {
+	o := classify(ctx, err)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight--
+	close(c.freed)
+	c.freed = make(chan struct{})
+	c.recordRequest(endpoint, took, o)
+
+	wasProbe := c.state == circuitHalfOpen && c.probing
+	if wasProbe {
+		c.probing = false
+	}
+
+	switch o {
+	case outcomeSuccess:
+		c.failures = 0
+		c.backoff = 0
+		if c.state != circuitClosed {
+			c.state = circuitClosed
+			slog.Info("connectwise circuit closed", "endpoint", endpoint)
+			c.startDrain()
+		}
+	case outcomeThrottled, outcomeFailed:
+		c.failures++
+		if o == outcomeThrottled {
+			c.backoff = min(max(c.backoff*2, minBackoff), maxBackoff)
+			c.pausedUntil = time.Now().Add(c.backoff)
+			slog.Warn("connectwise request throttled", "endpoint", endpoint, "backoff_seconds", c.backoff.Seconds())
+		}
+		if wasProbe || (c.state == circuitClosed && c.failures >= failureThreshold) {
+			c.state = circuitOpen
+			c.openedOn = time.Now()
+			slog.Warn("connectwise circuit opened", "endpoint", endpoint, "consecutive_failures", c.failures, "error", err.Error())
+		}
+	}
+
+	return o
+}
+
+// refill tops up the token bucket for the time passed since the last refill and returns the
+// tokens available. The bucket holds ten seconds' worth of the budget, so short bursts are allowed.
+func (c *Client) refill(now time.Time, rpm int) float64 {
+	capacity := max(1, perSecond(rpm)*10)
+	if c.refilled.IsZero() {
+		c.tokens = capacity
+	}
+	c.tokens = min(capacity, c.tokens+now.Sub(c.refilled).Seconds()*perSecond(rpm))
+	c.refilled = now
+	return c.tokens
+}
+
+func perSecond(rpm int) float64 {
+	return float64(rpm) / 60
+}
+
+// classify decides how a request's error counts against the circuit. Not found and other client
+// errors mean Connectwise is up, so they count as successes; a cancelled caller counts as nothing.
+func classify(ctx context.Context, err error) outcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	if ctx.Err() != nil {
+		return outcomeIgnored
+	}
+	if errors.Is(err, psa.ErrNotFound) {
+		return outcomeSuccess
+	}
+
+	switch code := statusCode(err); {
+	case code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable:
+		return outcomeThrottled
+	case code >= 400 && code < 500:
+		return outcomeSuccess
+	default:
+		return outcomeFailed
+	}
+}
+
+// statusCode pulls the HTTP status out of an error from the psa client. psa doesn't export a typed
+// error for this, so it falls back to matching the status in the error text. It returns 0 if unknown.
+func statusCode(err error) int {
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) {
+		return sc.StatusCode()
+	}
+
+	msg := err.Error()
+	if m := statusPattern.FindStringSubmatch(msg); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return code
+	}
+	for _, code := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		if strings.Contains(msg, http.StatusText(code)) {
+			return code
+		}
+	}
+
+	return 0
+}
+
+// statusLabel is the status code of a request for metrics: the code if known, "2xx" for success
+// and "error" for failures without one, such as timeouts.
+func statusLabel(err error) string {
+	switch {
+	case err == nil:
+		return "2xx"
+	case errors.Is(err, psa.ErrNotFound):
+		return strconv.Itoa(http.StatusNotFound)
+	}
+
+	if code := statusCode(err); code != 0 {
+		return strconv.Itoa(code)
+	}
+	return "error"
+}
+
+func sleep(ctx context.Context, d time.Duration, freed <-chan struct{}) error {
+	var timer <-chan time.Time
+	if freed == nil {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer:
+	case <-freed:
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cwclient
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type endpointStats struct {
+	requests  int64
+	errors    int64
+	throttled int64
+	rejected  int64
+	total     time.Duration
+	max       time.Duration
+	last      time.Time
+}
+
+// recordRequest must be called with c.mu held.
+func (c *Client) recordRequest(endpoint string, took time.Duration, o outcome) {
+	s := c.endpoint(endpoint)
+	s.requests++
+	s.total += took
+	s.max = max(s.max, took)
+	s.last = time.Now()
+
+	switch o {
+	case outcomeThrottled:
+		s.throttled++
+		s.errors++
+	case outcomeFailed:
+		s.errors++
+	}
+}
+
+func (c *Client) recordRejected(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoint(endpoint).rejected++
+}
+
+func (c *Client) endpoint(name string) *endpointStats {
+	s, ok := c.stats[name]
+	if !ok {
+		s = &endpointStats{}
+		c.stats[name] = s
+	}
+	return s
+}
+
+// Stats reports the limiter and circuit state, and request counts and latencies per endpoint
+// since the server started.
+func (c *Client) Stats() *models.CWClientStats {
+	cfg := c.cfg.Load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := &models.CWClientStats{
+		Circuit:             c.state.String(),
+		ConsecutiveFailures: c.failures,
+		InFlight:            c.inFlight,
+		Deferred:            len(c.deferred),
+		RequestsPerMinute:   cfg.CWRequestsPerMinute,
+		MaxConcurrent:       cfg.CWMaxConcurrentRequests,
+		Endpoints:           make([]models.CWEndpointStats, 0, len(c.stats)),
+	}
+	if c.state != circuitClosed {
+		t := c.openedOn
+		out.OpenedOn = &t
+	}
+	if time.Now().Before(c.pausedUntil) {
+		t := c.pausedUntil
+		out.BackoffUntil = &t
+	}
+
+	for name, s := range c.stats {
+		e := models.CWEndpointStats{
+			Endpoint:     name,
+			Requests:     s.requests,
+			Errors:       s.errors,
+			Throttled:    s.throttled,
+			Rejected:     s.rejected,
+			MaxLatencyMS: float64(s.max) / float64(time.Millisecond),
+		}
+		if s.requests > 0 {
+			e.AvgLatencyMS = float64(s.total) / float64(s.requests) / float64(time.Millisecond)
+			last := s.last
+			e.LastRequest = &last
+		}
+		out.Endpoints = append(out.Endpoints, e)
+	}
+	sort.Slice(out.Endpoints, func(i, j int) bool {
+		return out.Endpoints[i].Endpoint < out.Endpoints[j].Endpoint
+	})
+
+	return out
+}
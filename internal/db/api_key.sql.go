@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const deleteAPIKey = `-- name: DeleteAPIKey :exec
@@ -20,7 +21,7 @@ func (q *Queries) DeleteAPIKey(ctx context.Context, id int) error {
 }
 
 const getAPIKey = `-- name: GetAPIKey :one
-SELECT id, user_id, key_hash, created_on, updated_on, key_hint FROM api_key
+SELECT id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id FROM api_key
 WHERE id = $1
 `
 
@@ -34,25 +35,72 @@ func (q *Queries) GetAPIKey(ctx context.Context, id int) (*ApiKey, error) {
 		&i.CreatedOn,
 		&i.UpdatedOn,
 		&i.KeyHint,
+		&i.Role,
+		&i.Label,
+		&i.Scopes,
+		&i.ExpiresOn,
+		&i.LastUsedOn,
+		&i.LastUsedIp,
+		&i.LookupID,
+	)
+	return &i, err
+}
+
+const getAPIKeyByLookupID = `-- name: GetAPIKeyByLookupID :one
+SELECT id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id FROM api_key
+WHERE lookup_id = $1
+`
+
+func (q *Queries) GetAPIKeyByLookupID(ctx context.Context, lookupID *string) (*ApiKey, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyByLookupID, lookupID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.KeyHash,
+		&i.CreatedOn,
+		&i.UpdatedOn,
+		&i.KeyHint,
+		&i.Role,
+		&i.Label,
+		&i.Scopes,
+		&i.ExpiresOn,
+		&i.LastUsedOn,
+		&i.LastUsedIp,
+		&i.LookupID,
 	)
 	return &i, err
 }
 
 const insertAPIKey = `-- name: InsertAPIKey :one
 INSERT INTO api_key
-(user_id, key_hash, key_hint)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, key_hash, created_on, updated_on, key_hint
+(user_id, key_hash, key_hint, role, label, scopes, expires_on, lookup_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id
 `
 
 type InsertAPIKeyParams struct {
-	UserID  int     `json:"user_id"`
-	KeyHash []byte  `json:"key_hash"`
-	KeyHint *string `json:"key_hint"`
+	UserID    int        `json:"user_id"`
+	KeyHash   []byte     `json:"key_hash"`
+	KeyHint   *string    `json:"key_hint"`
+	Role      string     `json:"role"`
+	Label     string     `json:"label"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresOn *time.Time `json:"expires_on"`
+	LookupID  *string    `json:"lookup_id"`
 }
 
 func (q *Queries) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (*ApiKey, error) {
-	row := q.db.QueryRow(ctx, insertAPIKey, arg.UserID, arg.KeyHash, arg.KeyHint)
+	row := q.db.QueryRow(ctx, insertAPIKey,
+		arg.UserID,
+		arg.KeyHash,
+		arg.KeyHint,
+		arg.Role,
+		arg.Label,
+		arg.Scopes,
+		arg.ExpiresOn,
+		arg.LookupID,
+	)
 	var i ApiKey
 	err := row.Scan(
 		&i.ID,
@@ -61,12 +109,19 @@ func (q *Queries) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (*Ap
 		&i.CreatedOn,
 		&i.UpdatedOn,
 		&i.KeyHint,
+		&i.Role,
+		&i.Label,
+		&i.Scopes,
+		&i.ExpiresOn,
+		&i.LastUsedOn,
+		&i.LastUsedIp,
+		&i.LookupID,
 	)
 	return &i, err
 }
 
 const listAPIKeys = `-- name: ListAPIKeys :many
-SELECT id, user_id, key_hash, created_on, updated_on, key_hint FROM api_key
+SELECT id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id FROM api_key
 ORDER BY created_on
 `
 
@@ -86,6 +141,53 @@ func (q *Queries) ListAPIKeys(ctx context.Context) ([]*ApiKey, error) {
 			&i.CreatedOn,
 			&i.UpdatedOn,
 			&i.KeyHint,
+			&i.Role,
+			&i.Label,
+			&i.Scopes,
+			&i.ExpiresOn,
+			&i.LastUsedOn,
+			&i.LastUsedIp,
+			&i.LookupID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLegacyAPIKeys = `-- name: ListLegacyAPIKeys :many
+SELECT id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id FROM api_key
+WHERE lookup_id IS NULL
+ORDER BY created_on
+`
+
+func (q *Queries) ListLegacyAPIKeys(ctx context.Context) ([]*ApiKey, error) {
+	rows, err := q.db.Query(ctx, listLegacyAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.KeyHash,
+			&i.CreatedOn,
+			&i.UpdatedOn,
+			&i.KeyHint,
+			&i.Role,
+			&i.Label,
+			&i.Scopes,
+			&i.ExpiresOn,
+			&i.LastUsedOn,
+			&i.LastUsedIp,
+			&i.LookupID,
 		); err != nil {
 			return nil, err
 		}
@@ -97,13 +199,84 @@ func (q *Queries) ListAPIKeys(ctx context.Context) ([]*ApiKey, error) {
 	return items, nil
 }
 
+const setAPIKeyExpiry = `-- name: SetAPIKeyExpiry :one
+UPDATE api_key
+SET
+    expires_on = $2,
+    updated_on = NOW()
+WHERE id = $1
+RETURNING id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id
+`
+
+type SetAPIKeyExpiryParams struct {
+	ID        int        `json:"id"`
+	ExpiresOn *time.Time `json:"expires_on"`
+}
+
+func (q *Queries) SetAPIKeyExpiry(ctx context.Context, arg SetAPIKeyExpiryParams) (*ApiKey, error) {
+	row := q.db.QueryRow(ctx, setAPIKeyExpiry, arg.ID, arg.ExpiresOn)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.KeyHash,
+		&i.CreatedOn,
+		&i.UpdatedOn,
+		&i.KeyHint,
+		&i.Role,
+		&i.Label,
+		&i.Scopes,
+		&i.ExpiresOn,
+		&i.LastUsedOn,
+		&i.LastUsedIp,
+		&i.LookupID,
+	)
+	return &i, err
+}
+
+const setAPIKeyLastUsed = `-- name: SetAPIKeyLastUsed :exec
+UPDATE api_key
+SET
+    last_used_on = NOW(),
+    last_used_ip = $2
+WHERE id = $1
+`
+
+type SetAPIKeyLastUsedParams struct {
+	ID         int     `json:"id"`
+	LastUsedIp *string `json:"last_used_ip"`
+}
+
+func (q *Queries) SetAPIKeyLastUsed(ctx context.Context, arg SetAPIKeyLastUsedParams) error {
+	_, err := q.db.Exec(ctx, setAPIKeyLastUsed, arg.ID, arg.LastUsedIp)
+	return err
+}
+
+const setAPIKeyLookupID = `-- name: SetAPIKeyLookupID :exec
+UPDATE api_key
+SET
+    lookup_id = $2,
+    updated_on = NOW()
+WHERE id = $1
+`
+
+type SetAPIKeyLookupIDParams struct {
+	ID       int     `json:"id"`
+	LookupID *string `json:"lookup_id"`
+}
+
+func (q *Queries) SetAPIKeyLookupID(ctx context.Context, arg SetAPIKeyLookupIDParams) error {
+	_, err := q.db.Exec(ctx, setAPIKeyLookupID, arg.ID, arg.LookupID)
+	return err
+}
+
 const softDeleteAPIKey = `-- name: SoftDeleteAPIKey :one
 UPDATE api_key
 SET
     delete = true,
     updated_on = NOW()
 WHERE id = $1
-RETURNING id, user_id, key_hash, created_on, updated_on, key_hint
+RETURNING id, user_id, key_hash, created_on, updated_on, key_hint, role, label, scopes, expires_on, last_used_on, last_used_ip, lookup_id
 `
 
 func (q *Queries) SoftDeleteAPIKey(ctx context.Context, id int) (*ApiKey, error) {
@@ -116,6 +289,13 @@ func (q *Queries) SoftDeleteAPIKey(ctx context.Context, id int) (*ApiKey, error)
 		&i.CreatedOn,
 		&i.UpdatedOn,
 		&i.KeyHint,
+		&i.Role,
+		&i.Label,
+		&i.Scopes,
+		&i.ExpiresOn,
+		&i.LastUsedOn,
+		&i.LastUsedIp,
+		&i.LookupID,
 	)
 	return &i, err
 }
@@ -24,6 +24,26 @@ func (q *Queries) CheckUserExists(ctx context.Context, emailAddress string) (boo
 	return exists, err
 }
 
+const checkUserSSOIdentityExists = `-- name: CheckUserSSOIdentityExists :one
+SELECT EXISTS(
+    SELECT 1
+    FROM user_sso_identity
+    WHERE user_id = $1 AND issuer = $2
+) as exists
+`
+
+type CheckUserSSOIdentityExistsParams struct {
+	UserID int    `json:"user_id"`
+	Issuer string `json:"issuer"`
+}
+
+func (q *Queries) CheckUserSSOIdentityExists(ctx context.Context, arg CheckUserSSOIdentityExistsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, checkUserSSOIdentityExists, arg.UserID, arg.Issuer)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM api_user
 WHERE id = $1
@@ -35,7 +55,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled FROM api_user
+SELECT id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled, role FROM api_user
 WHERE id = $1 LIMIT 1
 `
 
@@ -51,12 +71,13 @@ func (q *Queries) GetUser(ctx context.Context, id int) (*ApiUser, error) {
 		&i.PasswordResetRequired,
 		&i.TotpSecret,
 		&i.TotpEnabled,
+		&i.Role,
 	)
 	return &i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled FROM api_user
+SELECT id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled, role FROM api_user
 WHERE email_address = $1 LIMIT 1
 `
 
@@ -72,19 +93,53 @@ func (q *Queries) GetUserByEmail(ctx context.Context, emailAddress string) (*Api
 		&i.PasswordResetRequired,
 		&i.TotpSecret,
 		&i.TotpEnabled,
+		&i.Role,
+	)
+	return &i, err
+}
+
+const getUserBySSOIdentity = `-- name: GetUserBySSOIdentity :one
+SELECT api_user.id, api_user.email_address, api_user.created_on, api_user.updated_on, api_user.password_hash, api_user.password_reset_required, api_user.totp_secret, api_user.totp_enabled, api_user.role FROM api_user
+JOIN user_sso_identity i ON i.user_id = api_user.id
+WHERE i.issuer = $1 AND i.subject = $2
+`
+
+type GetUserBySSOIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserBySSOIdentity(ctx context.Context, arg GetUserBySSOIdentityParams) (*ApiUser, error) {
+	row := q.db.QueryRow(ctx, getUserBySSOIdentity, arg.Issuer, arg.Subject)
+	var i ApiUser
+	err := row.Scan(
+		&i.ID,
+		&i.EmailAddress,
+		&i.CreatedOn,
+		&i.UpdatedOn,
+		&i.PasswordHash,
+		&i.PasswordResetRequired,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
 	)
 	return &i, err
 }
 
 const insertUser = `-- name: InsertUser :one
 INSERT INTO api_user
-(email_address)
-VALUES ($1)
-RETURNING id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled
+(email_address, role)
+VALUES ($1, $2)
+RETURNING id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled, role
 `
 
-func (q *Queries) InsertUser(ctx context.Context, emailAddress string) (*ApiUser, error) {
-	row := q.db.QueryRow(ctx, insertUser, emailAddress)
+type InsertUserParams struct {
+	EmailAddress string `json:"email_address"`
+	Role         string `json:"role"`
+}
+
+func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (*ApiUser, error) {
+	row := q.db.QueryRow(ctx, insertUser, arg.EmailAddress, arg.Role)
 	var i ApiUser
 	err := row.Scan(
 		&i.ID,
@@ -95,12 +150,30 @@ func (q *Queries) InsertUser(ctx context.Context, emailAddress string) (*ApiUser
 		&i.PasswordResetRequired,
 		&i.TotpSecret,
 		&i.TotpEnabled,
+		&i.Role,
 	)
 	return &i, err
 }
 
+const insertUserSSOIdentity = `-- name: InsertUserSSOIdentity :exec
+INSERT INTO user_sso_identity
+(issuer, subject, user_id)
+VALUES ($1, $2, $3)
+`
+
+type InsertUserSSOIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+	UserID  int    `json:"user_id"`
+}
+
+func (q *Queries) InsertUserSSOIdentity(ctx context.Context, arg InsertUserSSOIdentityParams) error {
+	_, err := q.db.Exec(ctx, insertUserSSOIdentity, arg.Issuer, arg.Subject, arg.UserID)
+	return err
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled FROM api_user
+SELECT id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled, role FROM api_user
 ORDER BY email_address
 `
 
@@ -122,6 +195,7 @@ func (q *Queries) ListUsers(ctx context.Context) ([]*ApiUser, error) {
 			&i.PasswordResetRequired,
 			&i.TotpSecret,
 			&i.TotpEnabled,
+			&i.Role,
 		); err != nil {
 			return nil, err
 		}
@@ -133,13 +207,44 @@ func (q *Queries) ListUsers(ctx context.Context) ([]*ApiUser, error) {
 	return items, nil
 }
 
+const setUserRole = `-- name: SetUserRole :one
+UPDATE api_user
+SET
+    role = $2,
+    updated_on = NOW()
+WHERE id = $1
+RETURNING id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled, role
+`
+
+type SetUserRoleParams struct {
+	ID   int    `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (*ApiUser, error) {
+	row := q.db.QueryRow(ctx, setUserRole, arg.ID, arg.Role)
+	var i ApiUser
+	err := row.Scan(
+		&i.ID,
+		&i.EmailAddress,
+		&i.CreatedOn,
+		&i.UpdatedOn,
+		&i.PasswordHash,
+		&i.PasswordResetRequired,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.Role,
+	)
+	return &i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE api_user
 SET
     email_address = $2,
     updated_on = NOW()
 WHERE id = $1
-RETURNING id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled
+RETURNING id, email_address, created_on, updated_on, password_hash, password_reset_required, totp_secret, totp_enabled, role
 `
 
 type UpdateUserParams struct {
@@ -159,6 +264,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (*ApiUse
 		&i.PasswordResetRequired,
 		&i.TotpSecret,
 		&i.TotpEnabled,
+		&i.Role,
 	)
 	return &i, err
 }
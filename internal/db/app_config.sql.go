@@ -10,7 +10,7 @@ import (
 )
 
 const getAppConfig = `-- name: GetAppConfig :one
-SELECT id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size FROM app_config
+SELECT id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size, sync_boards_interval_minutes, sync_recipients_interval_minutes, sync_open_tickets_interval_minutes, sync_ticket_updates_interval_minutes, hook_check_interval_minutes, hook_silence_minutes, hook_alert_recipient_id, business_hours_start, business_hours_end, business_timezone, cw_requests_per_minute, cw_max_concurrent_requests, audit_retention_days, session_idle_timeout_hours, session_max_lifetime_hours, login_max_failures, login_ip_max_failures, login_lockout_minutes, require_totp_for_api_keys, sso_satisfies_totp FROM app_config
 WHERE id = 1
 `
 
@@ -27,6 +27,69 @@ func (q *Queries) GetAppConfig(ctx context.Context) (*AppConfig, error) {
 		&i.LogRetentionDays,
 		&i.LogCleanupIntervalHours,
 		&i.LogBufferSize,
+		&i.SyncBoardsIntervalMinutes,
+		&i.SyncRecipientsIntervalMinutes,
+		&i.SyncOpenTicketsIntervalMinutes,
+		&i.SyncTicketUpdatesIntervalMinutes,
+		&i.HookCheckIntervalMinutes,
+		&i.HookSilenceMinutes,
+		&i.HookAlertRecipientID,
+		&i.BusinessHoursStart,
+		&i.BusinessHoursEnd,
+		&i.BusinessTimezone,
+		&i.CwRequestsPerMinute,
+		&i.CwMaxConcurrentRequests,
+		&i.AuditRetentionDays,
+		&i.SessionIdleTimeoutHours,
+		&i.SessionMaxLifetimeHours,
+		&i.LoginMaxFailures,
+		&i.LoginIpMaxFailures,
+		&i.LoginLockoutMinutes,
+		&i.RequireTotpForApiKeys,
+		&i.SsoSatisfiesTotp,
+	)
+	return &i, err
+}
+
+const getAppConfigForUpdate = `-- name: GetAppConfigForUpdate :one
+SELECT id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size, sync_boards_interval_minutes, sync_recipients_interval_minutes, sync_open_tickets_interval_minutes, sync_ticket_updates_interval_minutes, hook_check_interval_minutes, hook_silence_minutes, hook_alert_recipient_id, business_hours_start, business_hours_end, business_timezone, cw_requests_per_minute, cw_max_concurrent_requests, audit_retention_days, session_idle_timeout_hours, session_max_lifetime_hours, login_max_failures, login_ip_max_failures, login_lockout_minutes, require_totp_for_api_keys, sso_satisfies_totp FROM app_config
+WHERE id = 1
+FOR UPDATE
+`
+
+func (q *Queries) GetAppConfigForUpdate(ctx context.Context) (*AppConfig, error) {
+	row := q.db.QueryRow(ctx, getAppConfigForUpdate)
+	var i AppConfig
+	err := row.Scan(
+		&i.ID,
+		&i.AttemptNotify,
+		&i.MaxMessageLength,
+		&i.MaxConcurrentSyncs,
+		&i.RequireTotp,
+		&i.DebugLogging,
+		&i.LogRetentionDays,
+		&i.LogCleanupIntervalHours,
+		&i.LogBufferSize,
+		&i.SyncBoardsIntervalMinutes,
+		&i.SyncRecipientsIntervalMinutes,
+		&i.SyncOpenTicketsIntervalMinutes,
+		&i.SyncTicketUpdatesIntervalMinutes,
+		&i.HookCheckIntervalMinutes,
+		&i.HookSilenceMinutes,
+		&i.HookAlertRecipientID,
+		&i.BusinessHoursStart,
+		&i.BusinessHoursEnd,
+		&i.BusinessTimezone,
+		&i.CwRequestsPerMinute,
+		&i.CwMaxConcurrentRequests,
+		&i.AuditRetentionDays,
+		&i.SessionIdleTimeoutHours,
+		&i.SessionMaxLifetimeHours,
+		&i.LoginMaxFailures,
+		&i.LoginIpMaxFailures,
+		&i.LoginLockoutMinutes,
+		&i.RequireTotpForApiKeys,
+		&i.SsoSatisfiesTotp,
 	)
 	return &i, err
 }
@@ -34,7 +97,7 @@ func (q *Queries) GetAppConfig(ctx context.Context) (*AppConfig, error) {
 const insertDefaultAppConfig = `-- name: InsertDefaultAppConfig :one
 INSERT INTO app_config (id) VALUES (1)
 ON CONFLICT (id) DO UPDATE SET id = EXCLUDED.id
-RETURNING id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size
+RETURNING id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size, sync_boards_interval_minutes, sync_recipients_interval_minutes, sync_open_tickets_interval_minutes, sync_ticket_updates_interval_minutes, hook_check_interval_minutes, hook_silence_minutes, hook_alert_recipient_id, business_hours_start, business_hours_end, business_timezone, cw_requests_per_minute, cw_max_concurrent_requests, audit_retention_days, session_idle_timeout_hours, session_max_lifetime_hours, login_max_failures, login_ip_max_failures, login_lockout_minutes, require_totp_for_api_keys, sso_satisfies_totp
 `
 
 func (q *Queries) InsertDefaultAppConfig(ctx context.Context) (*AppConfig, error) {
@@ -50,13 +113,42 @@ func (q *Queries) InsertDefaultAppConfig(ctx context.Context) (*AppConfig, error
 		&i.LogRetentionDays,
 		&i.LogCleanupIntervalHours,
 		&i.LogBufferSize,
+		&i.SyncBoardsIntervalMinutes,
+		&i.SyncRecipientsIntervalMinutes,
+		&i.SyncOpenTicketsIntervalMinutes,
+		&i.SyncTicketUpdatesIntervalMinutes,
+		&i.HookCheckIntervalMinutes,
+		&i.HookSilenceMinutes,
+		&i.HookAlertRecipientID,
+		&i.BusinessHoursStart,
+		&i.BusinessHoursEnd,
+		&i.BusinessTimezone,
+		&i.CwRequestsPerMinute,
+		&i.CwMaxConcurrentRequests,
+		&i.AuditRetentionDays,
+		&i.SessionIdleTimeoutHours,
+		&i.SessionMaxLifetimeHours,
+		&i.LoginMaxFailures,
+		&i.LoginIpMaxFailures,
+		&i.LoginLockoutMinutes,
+		&i.RequireTotpForApiKeys,
+		&i.SsoSatisfiesTotp,
 	)
 	return &i, err
 }
 
+const notifyAppConfigChanged = `-- name: NotifyAppConfigChanged :exec
+SELECT pg_notify('app_config_changed', $1::text)
+`
+
+func (q *Queries) NotifyAppConfigChanged(ctx context.Context, origin string) error {
+	_, err := q.db.Exec(ctx, notifyAppConfigChanged, origin)
+	return err
+}
+
 const upsertAppConfig = `-- name: UpsertAppConfig :one
-INSERT INTO app_config(id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size)
-VALUES(1, $1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO app_config(id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size, sync_boards_interval_minutes, sync_recipients_interval_minutes, sync_open_tickets_interval_minutes, sync_ticket_updates_interval_minutes, hook_check_interval_minutes, hook_silence_minutes, hook_alert_recipient_id, business_hours_start, business_hours_end, business_timezone, cw_requests_per_minute, cw_max_concurrent_requests, audit_retention_days, session_idle_timeout_hours, session_max_lifetime_hours, login_max_failures, login_ip_max_failures, login_lockout_minutes, require_totp_for_api_keys, sso_satisfies_totp)
+VALUES(1, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)
 ON CONFLICT (id) DO UPDATE SET
     attempt_notify = EXCLUDED.attempt_notify,
     max_message_length = EXCLUDED.max_message_length,
@@ -65,19 +157,59 @@ ON CONFLICT (id) DO UPDATE SET
     debug_logging = EXCLUDED.debug_logging,
     log_retention_days = EXCLUDED.log_retention_days,
     log_cleanup_interval_hours = EXCLUDED.log_cleanup_interval_hours,
-    log_buffer_size = EXCLUDED.log_buffer_size
-RETURNING id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size
+    log_buffer_size = EXCLUDED.log_buffer_size,
+    sync_boards_interval_minutes = EXCLUDED.sync_boards_interval_minutes,
+    sync_recipients_interval_minutes = EXCLUDED.sync_recipients_interval_minutes,
+    sync_open_tickets_interval_minutes = EXCLUDED.sync_open_tickets_interval_minutes,
+    sync_ticket_updates_interval_minutes = EXCLUDED.sync_ticket_updates_interval_minutes,
+    hook_check_interval_minutes = EXCLUDED.hook_check_interval_minutes,
+    hook_silence_minutes = EXCLUDED.hook_silence_minutes,
+    hook_alert_recipient_id = EXCLUDED.hook_alert_recipient_id,
+    business_hours_start = EXCLUDED.business_hours_start,
+    business_hours_end = EXCLUDED.business_hours_end,
+    business_timezone = EXCLUDED.business_timezone,
+    cw_requests_per_minute = EXCLUDED.cw_requests_per_minute,
+    cw_max_concurrent_requests = EXCLUDED.cw_max_concurrent_requests,
+    audit_retention_days = EXCLUDED.audit_retention_days,
+    session_idle_timeout_hours = EXCLUDED.session_idle_timeout_hours,
+    session_max_lifetime_hours = EXCLUDED.session_max_lifetime_hours,
+    login_max_failures = EXCLUDED.login_max_failures,
+    login_ip_max_failures = EXCLUDED.login_ip_max_failures,
+    login_lockout_minutes = EXCLUDED.login_lockout_minutes,
+    require_totp_for_api_keys = EXCLUDED.require_totp_for_api_keys,
+    sso_satisfies_totp = EXCLUDED.sso_satisfies_totp
+RETURNING id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size, sync_boards_interval_minutes, sync_recipients_interval_minutes, sync_open_tickets_interval_minutes, sync_ticket_updates_interval_minutes, hook_check_interval_minutes, hook_silence_minutes, hook_alert_recipient_id, business_hours_start, business_hours_end, business_timezone, cw_requests_per_minute, cw_max_concurrent_requests, audit_retention_days, session_idle_timeout_hours, session_max_lifetime_hours, login_max_failures, login_ip_max_failures, login_lockout_minutes, require_totp_for_api_keys, sso_satisfies_totp
 `
 
 type UpsertAppConfigParams struct {
-	AttemptNotify           bool `json:"attempt_notify"`
-	MaxMessageLength        int  `json:"max_message_length"`
-	MaxConcurrentSyncs      int  `json:"max_concurrent_syncs"`
-	RequireTotp             bool `json:"require_totp"`
-	DebugLogging            bool `json:"debug_logging"`
-	LogRetentionDays        int  `json:"log_retention_days"`
-	LogCleanupIntervalHours int  `json:"log_cleanup_interval_hours"`
-	LogBufferSize           int  `json:"log_buffer_size"`
+	AttemptNotify                    bool   `json:"attempt_notify"`
+	MaxMessageLength                 int    `json:"max_message_length"`
+	MaxConcurrentSyncs               int    `json:"max_concurrent_syncs"`
+	RequireTotp                      bool   `json:"require_totp"`
+	DebugLogging                     bool   `json:"debug_logging"`
+	LogRetentionDays                 int    `json:"log_retention_days"`
+	LogCleanupIntervalHours          int    `json:"log_cleanup_interval_hours"`
+	LogBufferSize                    int    `json:"log_buffer_size"`
+	SyncBoardsIntervalMinutes        int    `json:"sync_boards_interval_minutes"`
+	SyncRecipientsIntervalMinutes    int    `json:"sync_recipients_interval_minutes"`
+	SyncOpenTicketsIntervalMinutes   int    `json:"sync_open_tickets_interval_minutes"`
+	SyncTicketUpdatesIntervalMinutes int    `json:"sync_ticket_updates_interval_minutes"`
+	HookCheckIntervalMinutes         int    `json:"hook_check_interval_minutes"`
+	HookSilenceMinutes               int    `json:"hook_silence_minutes"`
+	HookAlertRecipientID             *int   `json:"hook_alert_recipient_id"`
+	BusinessHoursStart               int    `json:"business_hours_start"`
+	BusinessHoursEnd                 int    `json:"business_hours_end"`
+	BusinessTimezone                 string `json:"business_timezone"`
+	CwRequestsPerMinute              int    `json:"cw_requests_per_minute"`
+	CwMaxConcurrentRequests          int    `json:"cw_max_concurrent_requests"`
+	AuditRetentionDays               int    `json:"audit_retention_days"`
+	SessionIdleTimeoutHours          int    `json:"session_idle_timeout_hours"`
+	SessionMaxLifetimeHours          int    `json:"session_max_lifetime_hours"`
+	LoginMaxFailures                 int    `json:"login_max_failures"`
+	LoginIpMaxFailures               int    `json:"login_ip_max_failures"`
+	LoginLockoutMinutes              int    `json:"login_lockout_minutes"`
+	RequireTotpForApiKeys            bool   `json:"require_totp_for_api_keys"`
+	SsoSatisfiesTotp                 bool   `json:"sso_satisfies_totp"`
 }
 
 func (q *Queries) UpsertAppConfig(ctx context.Context, arg UpsertAppConfigParams) (*AppConfig, error) {
@@ -90,6 +222,26 @@ func (q *Queries) UpsertAppConfig(ctx context.Context, arg UpsertAppConfigParams
 		arg.LogRetentionDays,
 		arg.LogCleanupIntervalHours,
 		arg.LogBufferSize,
+		arg.SyncBoardsIntervalMinutes,
+		arg.SyncRecipientsIntervalMinutes,
+		arg.SyncOpenTicketsIntervalMinutes,
+		arg.SyncTicketUpdatesIntervalMinutes,
+		arg.HookCheckIntervalMinutes,
+		arg.HookSilenceMinutes,
+		arg.HookAlertRecipientID,
+		arg.BusinessHoursStart,
+		arg.BusinessHoursEnd,
+		arg.BusinessTimezone,
+		arg.CwRequestsPerMinute,
+		arg.CwMaxConcurrentRequests,
+		arg.AuditRetentionDays,
+		arg.SessionIdleTimeoutHours,
+		arg.SessionMaxLifetimeHours,
+		arg.LoginMaxFailures,
+		arg.LoginIpMaxFailures,
+		arg.LoginLockoutMinutes,
+		arg.RequireTotpForApiKeys,
+		arg.SsoSatisfiesTotp,
 	)
 	var i AppConfig
 	err := row.Scan(
@@ -102,6 +254,26 @@ func (q *Queries) UpsertAppConfig(ctx context.Context, arg UpsertAppConfigParams
 		&i.LogRetentionDays,
 		&i.LogCleanupIntervalHours,
 		&i.LogBufferSize,
+		&i.SyncBoardsIntervalMinutes,
+		&i.SyncRecipientsIntervalMinutes,
+		&i.SyncOpenTicketsIntervalMinutes,
+		&i.SyncTicketUpdatesIntervalMinutes,
+		&i.HookCheckIntervalMinutes,
+		&i.HookSilenceMinutes,
+		&i.HookAlertRecipientID,
+		&i.BusinessHoursStart,
+		&i.BusinessHoursEnd,
+		&i.BusinessTimezone,
+		&i.CwRequestsPerMinute,
+		&i.CwMaxConcurrentRequests,
+		&i.AuditRetentionDays,
+		&i.SessionIdleTimeoutHours,
+		&i.SessionMaxLifetimeHours,
+		&i.LoginMaxFailures,
+		&i.LoginIpMaxFailures,
+		&i.LoginLockoutMinutes,
+		&i.RequireTotpForApiKeys,
+		&i.SsoSatisfiesTotp,
 	)
 	return &i, err
 }
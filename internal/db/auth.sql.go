@@ -11,7 +11,7 @@ import (
 )
 
 const getUserForAuth = `-- name: GetUserForAuth :one
-SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, created_on, updated_on
+SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, role, created_on, updated_on
 FROM api_user
 WHERE email_address = $1 LIMIT 1
 `
@@ -23,6 +23,7 @@ type GetUserForAuthRow struct {
 	PasswordResetRequired bool      `json:"password_reset_required"`
 	TotpSecret            *string   `json:"totp_secret"`
 	TotpEnabled           bool      `json:"totp_enabled"`
+	Role                  string    `json:"role"`
 	CreatedOn             time.Time `json:"created_on"`
 	UpdatedOn             time.Time `json:"updated_on"`
 }
@@ -37,6 +38,7 @@ func (q *Queries) GetUserForAuth(ctx context.Context, emailAddress string) (*Get
 		&i.PasswordResetRequired,
 		&i.TotpSecret,
 		&i.TotpEnabled,
+		&i.Role,
 		&i.CreatedOn,
 		&i.UpdatedOn,
 	)
@@ -44,7 +46,7 @@ func (q *Queries) GetUserForAuth(ctx context.Context, emailAddress string) (*Get
 }
 
 const getUserForAuthByID = `-- name: GetUserForAuthByID :one
-SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, created_on, updated_on
+SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, role, created_on, updated_on
 FROM api_user
 WHERE id = $1 LIMIT 1
 `
@@ -56,6 +58,7 @@ type GetUserForAuthByIDRow struct {
 	PasswordResetRequired bool      `json:"password_reset_required"`
 	TotpSecret            *string   `json:"totp_secret"`
 	TotpEnabled           bool      `json:"totp_enabled"`
+	Role                  string    `json:"role"`
 	CreatedOn             time.Time `json:"created_on"`
 	UpdatedOn             time.Time `json:"updated_on"`
 }
@@ -70,12 +73,64 @@ func (q *Queries) GetUserForAuthByID(ctx context.Context, id int) (*GetUserForAu
 		&i.PasswordResetRequired,
 		&i.TotpSecret,
 		&i.TotpEnabled,
+		&i.Role,
 		&i.CreatedOn,
 		&i.UpdatedOn,
 	)
 	return &i, err
 }
 
+const listTOTPSecrets = `-- name: ListTOTPSecrets :many
+SELECT id, totp_secret FROM api_user
+WHERE totp_secret IS NOT NULL
+ORDER BY id
+`
+
+type ListTOTPSecretsRow struct {
+	ID         int     `json:"id"`
+	TotpSecret *string `json:"totp_secret"`
+}
+
+func (q *Queries) ListTOTPSecrets(ctx context.Context) ([]*ListTOTPSecretsRow, error) {
+	rows, err := q.db.Query(ctx, listTOTPSecrets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ListTOTPSecretsRow
+	for rows.Next() {
+		var i ListTOTPSecretsRow
+		if err := rows.Scan(&i.ID, &i.TotpSecret); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const replaceTOTPSecret = `-- name: ReplaceTOTPSecret :execrows
+UPDATE api_user
+SET totp_secret = $1, updated_on = NOW()
+WHERE id = $2 AND totp_secret = $3
+`
+
+type ReplaceTOTPSecretParams struct {
+	NewSecret *string `json:"new_secret"`
+	ID        int     `json:"id"`
+	OldSecret *string `json:"old_secret"`
+}
+
+func (q *Queries) ReplaceTOTPSecret(ctx context.Context, arg ReplaceTOTPSecretParams) (int64, error) {
+	result, err := q.db.Exec(ctx, replaceTOTPSecret, arg.NewSecret, arg.ID, arg.OldSecret)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setPasswordResetRequired = `-- name: SetPasswordResetRequired :exec
 UPDATE api_user
 SET password_reset_required = $2, updated_on = NOW()
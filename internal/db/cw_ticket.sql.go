@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const checkTicketExists = `-- name: CheckTicketExists :one
@@ -97,6 +98,84 @@ func (q *Queries) ListTickets(ctx context.Context) ([]*CwTicket, error) {
 	return items, nil
 }
 
+const searchTickets = `-- name: SearchTickets :many
+SELECT t.id, t.summary, t.board_id, t.status_id, t.owner_id, t.company_id, t.contact_id, t.resources, t.updated_by, t.updated_on, t.added_on, t.deleted FROM cw_ticket t
+JOIN cw_ticket_status s ON s.id = t.status_id
+WHERE t.deleted = FALSE
+    AND ($1::int IS NULL OR t.board_id = $1::int)
+    AND ($2::int IS NULL OR t.status_id = $2::int)
+    AND ($3::boolean IS NULL OR s.closed = $3::boolean)
+    AND ($4::int IS NULL OR t.company_id = $4::int)
+    AND ($5::int IS NULL OR t.owner_id = $5::int)
+    AND ($6::int IS NULL OR EXISTS (
+        SELECT 1 FROM cw_member m
+        WHERE m.id = $6::int
+            AND m.identifier = ANY(string_to_array(replace(t.resources, ' ', ''), ','))
+    ))
+    AND ($7::timestamp IS NULL OR t.updated_on >= $7::timestamp)
+    AND ($8::text IS NULL OR t.summary ILIKE '%' || $8::text || '%')
+    AND ($9::int IS NULL OR t.id < $9::int)
+ORDER BY t.id DESC
+LIMIT $10::int
+`
+
+type SearchTicketsParams struct {
+	BoardID      *int       `json:"board_id"`
+	StatusID     *int       `json:"status_id"`
+	Closed       *bool      `json:"closed"`
+	CompanyID    *int       `json:"company_id"`
+	OwnerID      *int       `json:"owner_id"`
+	ResourceID   *int       `json:"resource_id"`
+	UpdatedSince *time.Time `json:"updated_since"`
+	Search       *string    `json:"search"`
+	Cursor       *int       `json:"cursor"`
+	PageLimit    int        `json:"page_limit"`
+}
+
+func (q *Queries) SearchTickets(ctx context.Context, arg SearchTicketsParams) ([]*CwTicket, error) {
+	rows, err := q.db.Query(ctx, searchTickets,
+		arg.BoardID,
+		arg.StatusID,
+		arg.Closed,
+		arg.CompanyID,
+		arg.OwnerID,
+		arg.ResourceID,
+		arg.UpdatedSince,
+		arg.Search,
+		arg.Cursor,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*CwTicket
+	for rows.Next() {
+		var i CwTicket
+		if err := rows.Scan(
+			&i.ID,
+			&i.Summary,
+			&i.BoardID,
+			&i.StatusID,
+			&i.OwnerID,
+			&i.CompanyID,
+			&i.ContactID,
+			&i.Resources,
+			&i.UpdatedBy,
+			&i.UpdatedOn,
+			&i.AddedOn,
+			&i.Deleted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const softDeleteTicket = `-- name: SoftDeleteTicket :exec
 UPDATE cw_ticket
 SET
@@ -9,12 +9,19 @@ import (
 )
 
 type ApiKey struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	KeyHash   []byte    `json:"key_hash"`
-	CreatedOn time.Time `json:"created_on"`
-	UpdatedOn time.Time `json:"updated_on"`
-	KeyHint   *string   `json:"key_hint"`
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	KeyHash    []byte     `json:"key_hash"`
+	CreatedOn  time.Time  `json:"created_on"`
+	UpdatedOn  time.Time  `json:"updated_on"`
+	KeyHint    *string    `json:"key_hint"`
+	Role       string     `json:"role"`
+	Label      string     `json:"label"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresOn  *time.Time `json:"expires_on"`
+	LastUsedOn *time.Time `json:"last_used_on"`
+	LastUsedIp *string    `json:"last_used_ip"`
+	LookupID   *string    `json:"lookup_id"`
 }
 
 type ApiUser struct {
@@ -26,18 +33,39 @@ type ApiUser struct {
 	PasswordResetRequired bool      `json:"password_reset_required"`
 	TotpSecret            *string   `json:"totp_secret"`
 	TotpEnabled           bool      `json:"totp_enabled"`
+	Role                  string    `json:"role"`
 }
 
 type AppConfig struct {
-	ID                      int  `json:"id"`
-	AttemptNotify           bool `json:"attempt_notify"`
-	MaxMessageLength        int  `json:"max_message_length"`
-	MaxConcurrentSyncs      int  `json:"max_concurrent_syncs"`
-	RequireTotp             bool `json:"require_totp"`
-	DebugLogging            bool `json:"debug_logging"`
-	LogRetentionDays        int  `json:"log_retention_days"`
-	LogCleanupIntervalHours int  `json:"log_cleanup_interval_hours"`
-	LogBufferSize           int  `json:"log_buffer_size"`
+	ID                               int    `json:"id"`
+	AttemptNotify                    bool   `json:"attempt_notify"`
+	MaxMessageLength                 int    `json:"max_message_length"`
+	MaxConcurrentSyncs               int    `json:"max_concurrent_syncs"`
+	RequireTotp                      bool   `json:"require_totp"`
+	DebugLogging                     bool   `json:"debug_logging"`
+	LogRetentionDays                 int    `json:"log_retention_days"`
+	LogCleanupIntervalHours          int    `json:"log_cleanup_interval_hours"`
+	LogBufferSize                    int    `json:"log_buffer_size"`
+	SyncBoardsIntervalMinutes        int    `json:"sync_boards_interval_minutes"`
+	SyncRecipientsIntervalMinutes    int    `json:"sync_recipients_interval_minutes"`
+	SyncOpenTicketsIntervalMinutes   int    `json:"sync_open_tickets_interval_minutes"`
+	SyncTicketUpdatesIntervalMinutes int    `json:"sync_ticket_updates_interval_minutes"`
+	HookCheckIntervalMinutes         int    `json:"hook_check_interval_minutes"`
+	HookSilenceMinutes               int    `json:"hook_silence_minutes"`
+	HookAlertRecipientID             *int   `json:"hook_alert_recipient_id"`
+	BusinessHoursStart               int    `json:"business_hours_start"`
+	BusinessHoursEnd                 int    `json:"business_hours_end"`
+	BusinessTimezone                 string `json:"business_timezone"`
+	CwRequestsPerMinute              int    `json:"cw_requests_per_minute"`
+	CwMaxConcurrentRequests          int    `json:"cw_max_concurrent_requests"`
+	AuditRetentionDays               int    `json:"audit_retention_days"`
+	SessionIdleTimeoutHours          int    `json:"session_idle_timeout_hours"`
+	SessionMaxLifetimeHours          int    `json:"session_max_lifetime_hours"`
+	LoginMaxFailures                 int    `json:"login_max_failures"`
+	LoginIpMaxFailures               int    `json:"login_ip_max_failures"`
+	LoginLockoutMinutes              int    `json:"login_lockout_minutes"`
+	RequireTotpForApiKeys            bool   `json:"require_totp_for_api_keys"`
+	SsoSatisfiesTotp                 bool   `json:"sso_satisfies_totp"`
 }
 
 type AppLog struct {
@@ -49,6 +77,32 @@ type AppLog struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type AuditLog struct {
+	ID          int       `json:"id"`
+	ActorUserID *int      `json:"actor_user_id"`
+	ActorKeyID  *int      `json:"actor_key_id"`
+	ActorRole   string    `json:"actor_role"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"target_type"`
+	TargetID    *string   `json:"target_id"`
+	Before      []byte    `json:"before"`
+	After       []byte    `json:"after"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	RemoteIp    string    `json:"remote_ip"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedOn   time.Time `json:"created_on"`
+}
+
+type ConfigRevision struct {
+	ID           int       `json:"id"`
+	Config       []byte    `json:"config"`
+	AuthorUserID *int      `json:"author_user_id"`
+	AuthorKeyID  *int      `json:"author_key_id"`
+	RollbackOf   *int      `json:"rollback_of"`
+	CreatedOn    time.Time `json:"created_on"`
+}
+
 type CwBoard struct {
 	ID        int       `json:"id"`
 	Name      string    `json:"name"`
@@ -125,6 +179,33 @@ type CwTicketStatus struct {
 	Deleted        bool      `json:"deleted"`
 }
 
+type HookHealth struct {
+	ID           int        `json:"id"`
+	LastReceived *time.Time `json:"last_received"`
+	AlertSentOn  *time.Time `json:"alert_sent_on"`
+	UpdatedOn    time.Time  `json:"updated_on"`
+}
+
+type LoginLockout struct {
+	ID          int       `json:"id"`
+	Scope       string    `json:"scope"`
+	Subject     string    `json:"subject"`
+	UserID      *int      `json:"user_id"`
+	Ip          string    `json:"ip"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+	CreatedOn   time.Time `json:"created_on"`
+}
+
+type LoginThrottle struct {
+	Scope        string     `json:"scope"`
+	Subject      string     `json:"subject"`
+	Failures     int        `json:"failures"`
+	LastFailedOn time.Time  `json:"last_failed_on"`
+	LockedUntil  *time.Time `json:"locked_until"`
+	Lockouts     int        `json:"lockouts"`
+}
+
 type NotifierForward struct {
 	ID            int        `json:"id"`
 	SourceID      int        `json:"source_id"`
@@ -146,11 +227,37 @@ type NotifierRule struct {
 }
 
 type Session struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	TokenHash []byte    `json:"token_hash"`
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedOn time.Time `json:"created_on"`
+	ID                int       `json:"id"`
+	UserID            int       `json:"user_id"`
+	TokenHash         []byte    `json:"token_hash"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	CreatedOn         time.Time `json:"created_on"`
+	LastSeenOn        time.Time `json:"last_seen_on"`
+	AbsoluteExpiresAt time.Time `json:"absolute_expires_at"`
+	Ip                string    `json:"ip"`
+	UserAgent         string    `json:"user_agent"`
+	AuthMethod        string    `json:"auth_method"`
+}
+
+type SyncJob struct {
+	ID          int        `json:"id"`
+	TriggeredBy string     `json:"triggered_by"`
+	Status      string     `json:"status"`
+	Payload     []byte     `json:"payload"`
+	Phases      []byte     `json:"phases"`
+	Error       *string    `json:"error"`
+	StartedOn   time.Time  `json:"started_on"`
+	FinishedOn  *time.Time `json:"finished_on"`
+}
+
+type SyncState struct {
+	SyncType      string     `json:"sync_type"`
+	Watermark     *time.Time `json:"watermark"`
+	LastStarted   *time.Time `json:"last_started"`
+	LastFinished  *time.Time `json:"last_finished"`
+	LastSucceeded *time.Time `json:"last_succeeded"`
+	LastError     *string    `json:"last_error"`
+	UpdatedOn     time.Time  `json:"updated_on"`
 }
 
 type TicketNotification struct {
@@ -181,6 +288,13 @@ type TotpRecoveryCode struct {
 	CreatedOn time.Time `json:"created_on"`
 }
 
+type UserSsoIdentity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	UserID    int       `json:"user_id"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
 type WebexRecipient struct {
 	ID           int       `json:"id"`
 	WebexID      string    `json:"webex_id"`
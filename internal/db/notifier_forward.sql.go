@@ -504,3 +504,36 @@ func (q *Queries) ListNotifierForwardsFull(ctx context.Context) ([]*ListNotifier
 	}
 	return items, nil
 }
+
+const updateNotifierForward = `-- name: UpdateNotifierForward :one
+UPDATE notifier_forward
+SET
+    enabled = $2,
+    user_keeps_copy = $3,
+    updated_on = NOW()
+WHERE id = $1
+RETURNING id, source_id, destination_id, start_date, end_date, enabled, user_keeps_copy, created_on, updated_on
+`
+
+type UpdateNotifierForwardParams struct {
+	ID            int  `json:"id"`
+	Enabled       bool `json:"enabled"`
+	UserKeepsCopy bool `json:"user_keeps_copy"`
+}
+
+func (q *Queries) UpdateNotifierForward(ctx context.Context, arg UpdateNotifierForwardParams) (*NotifierForward, error) {
+	row := q.db.QueryRow(ctx, updateNotifierForward, arg.ID, arg.Enabled, arg.UserKeepsCopy)
+	var i NotifierForward
+	err := row.Scan(
+		&i.ID,
+		&i.SourceID,
+		&i.DestinationID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Enabled,
+		&i.UserKeepsCopy,
+		&i.CreatedOn,
+		&i.UpdatedOn,
+	)
+	return &i, err
+}
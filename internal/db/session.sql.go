@@ -11,19 +11,31 @@ import (
 )
 
 const createSession = `-- name: CreateSession :one
-INSERT INTO session (user_id, token_hash, expires_at)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, token_hash, expires_at, created_on
+INSERT INTO session (user_id, token_hash, expires_at, absolute_expires_at, ip, user_agent, auth_method)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, token_hash, expires_at, created_on, last_seen_on, absolute_expires_at, ip, user_agent, auth_method
 `
 
 type CreateSessionParams struct {
-	UserID    int       `json:"user_id"`
-	TokenHash []byte    `json:"token_hash"`
-	ExpiresAt time.Time `json:"expires_at"`
+	UserID            int       `json:"user_id"`
+	TokenHash         []byte    `json:"token_hash"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	AbsoluteExpiresAt time.Time `json:"absolute_expires_at"`
+	Ip                string    `json:"ip"`
+	UserAgent         string    `json:"user_agent"`
+	AuthMethod        string    `json:"auth_method"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*Session, error) {
-	row := q.db.QueryRow(ctx, createSession, arg.UserID, arg.TokenHash, arg.ExpiresAt)
+	row := q.db.QueryRow(ctx, createSession,
+		arg.UserID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+		arg.AbsoluteExpiresAt,
+		arg.Ip,
+		arg.UserAgent,
+		arg.AuthMethod,
+	)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +43,11 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*
 		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.CreatedOn,
+		&i.LastSeenOn,
+		&i.AbsoluteExpiresAt,
+		&i.Ip,
+		&i.UserAgent,
+		&i.AuthMethod,
 	)
 	return &i, err
 }
@@ -53,8 +70,44 @@ func (q *Queries) DeleteSession(ctx context.Context, id int) error {
 	return err
 }
 
+const deleteUserSession = `-- name: DeleteUserSession :execrows
+DELETE FROM session WHERE id = $1 AND user_id = $2
+`
+
+type DeleteUserSessionParams struct {
+	ID     int `json:"id"`
+	UserID int `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserSession, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :execrows
+DELETE FROM session
+WHERE user_id = $1
+  AND ($2::int IS NULL OR id <> $2::int)
+`
+
+type DeleteUserSessionsParams struct {
+	UserID int  `json:"user_id"`
+	KeepID *int `json:"keep_id"`
+}
+
+func (q *Queries) DeleteUserSessions(ctx context.Context, arg DeleteUserSessionsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserSessions, arg.UserID, arg.KeepID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getSessionByTokenHash = `-- name: GetSessionByTokenHash :one
-SELECT id, user_id, token_hash, expires_at, created_on FROM session
+SELECT id, user_id, token_hash, expires_at, created_on, last_seen_on, absolute_expires_at, ip, user_agent, auth_method FROM session
 WHERE token_hash = $1 AND expires_at > NOW()
 LIMIT 1
 `
@@ -68,6 +121,74 @@ func (q *Queries) GetSessionByTokenHash(ctx context.Context, tokenHash []byte) (
 		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.CreatedOn,
+		&i.LastSeenOn,
+		&i.AbsoluteExpiresAt,
+		&i.Ip,
+		&i.UserAgent,
+		&i.AuthMethod,
 	)
 	return &i, err
 }
+
+const listSessionsByUser = `-- name: ListSessionsByUser :many
+SELECT id, user_id, token_hash, expires_at, created_on, last_seen_on, absolute_expires_at, ip, user_agent, auth_method FROM session
+WHERE user_id = $1 AND expires_at > NOW()
+ORDER BY last_seen_on DESC
+`
+
+func (q *Queries) ListSessionsByUser(ctx context.Context, userID int) ([]*Session, error) {
+	rows, err := q.db.Query(ctx, listSessionsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.CreatedOn,
+			&i.LastSeenOn,
+			&i.AbsoluteExpiresAt,
+			&i.Ip,
+			&i.UserAgent,
+			&i.AuthMethod,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE session
+SET last_seen_on = NOW(),
+    expires_at = $2,
+    ip = $3,
+    user_agent = $4
+WHERE id = $1
+`
+
+type TouchSessionParams struct {
+	ID        int       `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Ip        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+}
+
+func (q *Queries) TouchSession(ctx context.Context, arg TouchSessionParams) error {
+	_, err := q.db.Exec(ctx, touchSession,
+		arg.ID,
+		arg.ExpiresAt,
+		arg.Ip,
+		arg.UserAgent,
+	)
+	return err
+}
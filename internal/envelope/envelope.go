@@ -0,0 +1,236 @@
+// Package envelope encrypts secrets stored in the database. Each value gets its own random data
+// key, which encrypts the value and is itself encrypted ("wrapped") by a master key from the
+// keyring. The master key's ID is stored alongside, so keys can be rotated by adding a new one
+// and re-wrapping old values without touching their ciphertext.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	prefix  = "enc:v1:"
+	keySize = 32
+)
+
+var (
+	ErrNoKeys     = errors.New("no encryption keys configured")
+	ErrUnknownKey = errors.New("value is encrypted with a key that isn't in the keyring")
+	ErrMalformed  = errors.New("malformed encrypted value")
+	ErrDecrypt    = errors.New("decrypting value failed")
+)
+
+// Keyring holds the master keys. The primary key wraps new values; every key can unwrap.
+type Keyring struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// ParseKeys reads keys written as "id:base64key", separated by commas or newlines. The first
+// key is the primary. Keys are 32 random bytes, e.g. from `openssl rand -base64 32`.
+func ParseKeys(s string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string][]byte)}
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	for i, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" || strings.HasPrefix(f, "#") {
+			continue
+		}
+
+		id, enc, ok := strings.Cut(f, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key entry %d is not id:base64key", i+1)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("key id %q is listed twice", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(enc))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %q is %d bytes, expected %d", id, len(key), keySize)
+		}
+
+		if k.primary == "" {
+			k.primary = id
+		}
+		k.keys[id] = key
+	}
+
+	if k.primary == "" {
+		return nil, ErrNoKeys
+	}
+
+	return k, nil
+}
+
+// PrimaryID returns the ID of the key new values are wrapped with.
+func (k *Keyring) PrimaryID() string {
+	return k.primary
+}
+
+// IsSealed reports whether s is a value produced by Seal rather than plaintext.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// KeyID returns the ID of the key a sealed value is wrapped with.
+func KeyID(sealed string) (string, error) {
+	p, err := parse(sealed)
+	if err != nil {
+		return "", err
+	}
+	return p.keyID, nil
+}
+
+// Seal encrypts plaintext. aad names what the value belongs to, such as a column and row ID,
+// and must be given again to Open; it stops a value being copied to another row.
+func (k *Keyring) Seal(plaintext []byte, aad string) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("generating data key: %w", err)
+	}
+
+	data, err := encrypt(dek, plaintext, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := encrypt(k.keys[k.primary], dek, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+
+	return sealed{keyID: k.primary, wrapped: wrapped, data: data}.String(), nil
+}
+
+// Open decrypts a value from Seal.
+func (k *Keyring) Open(s, aad string) ([]byte, error) {
+	p, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := k.unwrap(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(dek, p.data, []byte(aad))
+}
+
+// Rewrap re-wraps a sealed value's data key with the primary key, leaving the encrypted value
+// itself as it is. It reports whether anything changed.
+func (k *Keyring) Rewrap(s string) (string, bool, error) {
+	p, err := parse(s)
+	if err != nil {
+		return "", false, err
+	}
+
+	if p.keyID == k.primary {
+		return s, false, nil
+	}
+
+	dek, err := k.unwrap(p)
+	if err != nil {
+		return "", false, err
+	}
+
+	wrapped, err := encrypt(k.keys[k.primary], dek, []byte(k.primary))
+	if err != nil {
+		return "", false, err
+	}
+
+	p.keyID, p.wrapped = k.primary, wrapped
+	return p.String(), true, nil
+}
+
+func (k *Keyring) unwrap(p sealed) ([]byte, error) {
+	kek, ok := k.keys[p.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, p.keyID)
+	}
+	return decrypt(kek, p.wrapped, []byte(p.keyID))
+}
+
+type sealed struct {
+	keyID   string
+	wrapped []byte
+	data    []byte
+}
+
+func (p sealed) String() string {
+	enc := base64.RawURLEncoding
+	return prefix + p.keyID + ":" + enc.EncodeToString(p.wrapped) + ":" + enc.EncodeToString(p.data)
+}
+
+func parse(s string) (sealed, error) {
+	if !IsSealed(s) {
+		return sealed{}, ErrMalformed
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, prefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return sealed{}, ErrMalformed
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return sealed{}, ErrMalformed
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return sealed{}, ErrMalformed
+	}
+
+	return sealed{keyID: parts[0], wrapped: wrapped, data: data}, nil
+}
+
+// encrypt returns the nonce followed by the AES-GCM ciphertext.
+func encrypt(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func decrypt(key, b, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	out, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return out, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -6,18 +6,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type AdminHandler struct {
 	shutdown func()
+	audit    *auditsvc.Service
 }
 
-func NewAdminHandler(shutdown func()) *AdminHandler {
-	return &AdminHandler{shutdown: shutdown}
+func NewAdminHandler(shutdown func(), audit *auditsvc.Service) *AdminHandler {
+	return &AdminHandler{shutdown: shutdown, audit: audit}
 }
 
 func (h *AdminHandler) HandleRestart(c *gin.Context) {
 	slog.Info("restart requested via web panel")
+	h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditServerRestart, models.AuditTargetServer, ""), nil, nil)
 	c.Status(http.StatusNoContent)
 
 	// trigger shutdown after the response is sent
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type AuditHandler struct {
+	Service *auditsvc.Service
+}
+
+func NewAuditHandler(svc *auditsvc.Service) *AuditHandler {
+	return &AuditHandler{Service: svc}
+}
+
+func (h *AuditHandler) HandleList(c *gin.Context) {
+	f, err := auditFilterFromQuery(c)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	entries, err := h.Service.List(c.Request.Context(), f)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if len(entries) > 0 && len(entries) == f.Limit {
+		setNextLink(c, strconv.Itoa(entries[len(entries)-1].ID))
+	}
+
+	outputJSON(c, entries)
+}
+
+func auditFilterFromQuery(c *gin.Context) (*models.AuditFilter, error) {
+	var (
+		f = &models.AuditFilter{
+			Action:     queryString(c, "action"),
+			TargetType: queryString(c, "target_type"),
+			TargetID:   queryString(c, "target_id"),
+		}
+		err error
+	)
+
+	if f.ActorUserID, err = queryInt(c, "actor_user_id"); err != nil {
+		return nil, err
+	}
+
+	if f.ActorKeyID, err = queryInt(c, "actor_key_id"); err != nil {
+		return nil, err
+	}
+
+	if f.Since, err = queryTime(c, "since"); err != nil {
+		return nil, err
+	}
+
+	if f.Until, err = queryTime(c, "until"); err != nil {
+		return nil, err
+	}
+
+	if f.Cursor, err = queryInt(c, "cursor"); err != nil {
+		return nil, err
+	}
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	if limit != nil {
+		f.Limit = *limit
+	}
+
+	return f, nil
+}
+
+// auditEntry starts an audit entry for a change made by this request, with the actor and request
+// details filled in. targetID is left empty for targets like config that only have one instance.
+func auditEntry(c *gin.Context, action models.AuditAction, targetType, targetID string) *models.AuditEntry {
+	a := currentActor(c)
+	e := &models.AuditEntry{
+		ActorKeyID: a.APIKeyID,
+		ActorRole:  a.Role,
+		Action:     action,
+		TargetType: targetType,
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		RemoteIP:   c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+
+	if a.UserID != 0 {
+		e.ActorUserID = &a.UserID
+	}
+
+	if targetID != "" {
+		e.TargetID = &targetID
+	}
+
+	return e
+}
+
+// auditKey is an API key without its hash, for audit snapshots.
+func auditKey(k *models.APIKey) *models.APIKey {
+	if k == nil {
+		return nil
+	}
+
+	cp := *k
+	cp.KeyHash = nil
+	return &cp
+}
+
+// auditKeyResponse is a newly issued key without the plaintext key, for audit snapshots.
+func auditKeyResponse(k *models.CreateAPIKeyResponse) *models.CreateAPIKeyResponse {
+	cp := *k
+	cp.Key = ""
+	return &cp
+}
@@ -2,21 +2,27 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/authsvc"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 const cookieName = "tb_session"
 
 type AuthHandler struct {
-	svc *authsvc.Service
+	svc   *authsvc.Service
+	audit *auditsvc.Service
 }
 
-func NewAuthHandler(svc *authsvc.Service) *AuthHandler {
-	return &AuthHandler{svc: svc}
+func NewAuthHandler(svc *authsvc.Service, audit *auditsvc.Service) *AuthHandler {
+	return &AuthHandler{svc: svc, audit: audit}
 }
 
 type loginRequest struct {
@@ -31,8 +37,11 @@ func (h *AuthHandler) HandleLogin(c *gin.Context) {
 		return
 	}
 
-	result, err := h.svc.Login(c.Request.Context(), req.Email, req.Password)
+	result, err := h.svc.Login(c.Request.Context(), req.Email, req.Password, sessionClient(c))
 	if err != nil {
+		if throttled(c, err) {
+			return
+		}
 		if errors.Is(err, authsvc.ErrInvalidCredentials) || errors.Is(err, authsvc.ErrNoPassword) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
 			return
@@ -46,7 +55,7 @@ func (h *AuthHandler) HandleLogin(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie(cookieName, result.Token, int(24*time.Hour/time.Second), "/", "", false, true)
+	setSessionCookie(c, result.Token, h.svc.SessionMaxAge())
 	c.JSON(http.StatusOK, gin.H{"ok": true, "reset_required": result.ResetRequired, "totp_setup_required": result.TOTPSetupRequired})
 }
 
@@ -81,9 +90,38 @@ func (h *AuthHandler) HandleChangePassword(c *gin.Context) {
 		return
 	}
 
+	h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditPasswordChange, models.AuditTargetUser, strconv.Itoa(userID)), nil, nil)
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
+// throttled responds with 429 and a Retry-After header if err is a sign-in throttle.
+func throttled(c *gin.Context, err error) bool {
+	var te *authsvc.ThrottleError
+	if !errors.As(err, &te) {
+		return false
+	}
+
+	secs := int(math.Ceil(te.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(secs))
+
+	msg := fmt.Sprintf("too many failed attempts, try again in %d seconds", secs)
+	if te.Locked {
+		msg = fmt.Sprintf("too many failed attempts, sign-in is locked for %d minutes", int(math.Ceil(te.RetryAfter.Minutes())))
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": msg})
+	return true
+}
+
+// setSessionCookie sets the cookie for the life of the session. The server ends idle sessions
+// sooner on its own.
+func setSessionCookie(c *gin.Context, token string, maxAge time.Duration) {
+	c.SetCookie(cookieName, token, int(maxAge/time.Second), "/", "", false, true)
+}
+
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
 func (h *AuthHandler) HandleLogout(c *gin.Context) {
 	token, err := c.Cookie(cookieName)
 	if err == nil && token != "" {
@@ -1,19 +1,24 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/thecoretg/ticketbot/models"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/config"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type ConfigHandler struct {
 	Service *config.Service
+	Audit   *auditsvc.Service
 }
 
-func NewConfigHandler(svc *config.Service) *ConfigHandler {
-	return &ConfigHandler{Service: svc}
+func NewConfigHandler(svc *config.Service, audit *auditsvc.Service) *ConfigHandler {
+	return &ConfigHandler{Service: svc, Audit: audit}
 }
 
 func (h *ConfigHandler) Get(c *gin.Context) {
@@ -33,11 +38,86 @@ func (h *ConfigHandler) Update(c *gin.Context) {
 		return
 	}
 
-	cfg, err := h.Service.Update(c.Request.Context(), p)
+	// Get returns a snapshot, which stays as it is after the update
+	before, err := h.Service.Get(c.Request.Context())
+	if err != nil {
+		internalServerError(c, fmt.Errorf("getting current config: %w", err))
+		return
+	}
+
+	cfg, err := h.Service.Update(c.Request.Context(), p, currentActor(c))
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidConfig) {
+			errJSON(c, http.StatusBadRequest, err)
+			return
+		}
 		internalServerError(c, fmt.Errorf("updating config: %w", err))
 		return
 	}
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditConfigUpdate, models.AuditTargetConfig, ""), before, cfg)
+	outputJSON(c, cfg)
+}
+
+// History lists config revisions newest first, with what each one changed.
+func (h *ConfigHandler) History(c *gin.Context) {
+	cursor, err := queryInt(c, "cursor")
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	n := 0
+	if limit != nil {
+		n = *limit
+	}
+
+	revs, more, err := h.Service.History(c.Request.Context(), cursor, n)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if more {
+		setNextLink(c, strconv.Itoa(revs[len(revs)-1].Revision))
+	}
+
+	outputJSON(c, revs)
+}
+
+// Rollback restores the config from an earlier revision.
+func (h *ConfigHandler) Rollback(c *gin.Context) {
+	rev, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		errJSON(c, http.StatusBadRequest, fmt.Errorf("%s is not a valid revision", c.Param("revision")))
+		return
+	}
+
+	before, err := h.Service.Get(c.Request.Context())
+	if err != nil {
+		internalServerError(c, fmt.Errorf("getting current config: %w", err))
+		return
+	}
+
+	cfg, err := h.Service.Rollback(c.Request.Context(), rev, currentActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrConfigRevisionNotFound):
+			notFoundError(c, err)
+		case errors.Is(err, models.ErrInvalidConfig):
+			errJSON(c, http.StatusBadRequest, err)
+		default:
+			internalServerError(c, fmt.Errorf("rolling back config: %w", err))
+		}
+		return
+	}
+
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditConfigRollback, models.AuditTargetConfig, strconv.Itoa(rev)), before, cfg)
 	outputJSON(c, cfg)
 }
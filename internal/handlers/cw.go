@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thecoretg/ticketbot/models"
@@ -55,3 +56,191 @@ func (h *CWHandler) GetBoard(c *gin.Context) {
 
 	outputJSON(c, b)
 }
+
+func (h *CWHandler) ListTickets(c *gin.Context) {
+	f, err := ticketFilterFromQuery(c)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	t, err := h.Service.ListTickets(c.Request.Context(), f)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if len(t) > 0 && len(t) == f.Limit {
+		setNextLink(c, strconv.Itoa(t[len(t)-1].ID))
+	}
+
+	outputJSON(c, t)
+}
+
+func (h *CWHandler) GetTicket(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	t, err := h.Service.GetTicketDetail(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrTicketNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, t)
+}
+
+func (h *CWHandler) ListCompanies(c *gin.Context) {
+	co, err := h.Service.ListCompanies(c.Request.Context())
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, co)
+}
+
+func (h *CWHandler) GetCompany(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	co, err := h.Service.GetCompany(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrCompanyNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, co)
+}
+
+func (h *CWHandler) ListContacts(c *gin.Context) {
+	co, err := h.Service.ListContacts(c.Request.Context())
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, co)
+}
+
+func (h *CWHandler) GetContact(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	co, err := h.Service.GetContact(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrContactNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, co)
+}
+
+func (h *CWHandler) ListStatuses(c *gin.Context) {
+	boardID, err := queryInt(c, "board_id")
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	var s []*models.TicketStatus
+	if boardID != nil {
+		s, err = h.Service.ListStatusesByBoard(c.Request.Context(), *boardID)
+	} else {
+		s, err = h.Service.ListStatuses(c.Request.Context())
+	}
+
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, s)
+}
+
+func (h *CWHandler) GetStatus(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	s, err := h.Service.GetStatus(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrTicketStatusNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, s)
+}
+
+// ClientStats reports the shared Connectwise client's circuit state and per-endpoint request stats.
+func (h *CWHandler) ClientStats(c *gin.Context) {
+	outputJSON(c, h.Service.CWClient.Stats())
+}
+
+func ticketFilterFromQuery(c *gin.Context) (*models.TicketFilter, error) {
+	var (
+		f   = &models.TicketFilter{Search: queryString(c, "q")}
+		err error
+	)
+
+	ints := map[string]**int{
+		"board_id":    &f.BoardID,
+		"status_id":   &f.StatusID,
+		"company_id":  &f.CompanyID,
+		"owner_id":    &f.OwnerID,
+		"resource_id": &f.ResourceID,
+		"cursor":      &f.Cursor,
+	}
+
+	for k, dst := range ints {
+		if *dst, err = queryInt(c, k); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Closed, err = queryBool(c, "closed"); err != nil {
+		return nil, err
+	}
+
+	if f.UpdatedSince, err = queryTime(c, "updated_since"); err != nil {
+		return nil, err
+	}
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	if limit != nil {
+		f.Limit = *limit
+	}
+
+	return f, nil
+}
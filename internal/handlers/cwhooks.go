@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/internal/metrics"
+	"github.com/thecoretg/ticketbot/internal/service/cwsvc"
+)
+
+// CWHookHandler handles Connectwise callbacks for entities other than tickets,
+// keeping them current in the store without waiting for the TTL.
+type CWHookHandler struct {
+	Service *cwsvc.Service
+}
+
+func NewCWHookHandler(svc *cwsvc.Service) *CWHookHandler {
+	return &CWHookHandler{Service: svc}
+}
+
+func (h *CWHookHandler) ProcessCompany(c *gin.Context) {
+	h.processEntity(c, "company", h.Service.RefreshCompany, h.Service.SoftDeleteCompany)
+}
+
+func (h *CWHookHandler) ProcessContact(c *gin.Context) {
+	h.processEntity(c, "contact", h.Service.RefreshContact, h.Service.SoftDeleteContact)
+}
+
+func (h *CWHookHandler) ProcessMember(c *gin.Context) {
+	h.processEntity(c, "member", h.Service.RefreshMember, h.Service.SoftDeleteMember)
+}
+
+func (h *CWHookHandler) processEntity(c *gin.Context, entity string, refresh, softDelete func(context.Context, int) error) {
+	w := &psa.WebhookPayload{}
+	if err := c.ShouldBindJSON(w); err != nil {
+		badPayloadError(c, err)
+		return
+	}
+	id := w.ID
+	action := w.Action
+	metrics.WebhookReceived(entity, action)
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	switch action {
+	case "added", "updated":
+		go func() {
+			// refreshes are deferred, not failed, while the Connectwise circuit is open
+			key := fmt.Sprintf("%s:%d", entity, id)
+			run := func(ctx context.Context) error { return refresh(ctx, id) }
+			if err := h.Service.CWClient.RunOrDefer(ctx, key, run); err != nil {
+				slog.Error("processing connectwise webhook", "entity", entity, "id", id, "error", err.Error())
+			}
+		}()
+	case "deleted":
+		go func() {
+			if err := softDelete(ctx, id); err != nil {
+				slog.Error("soft deleting from connectwise webhook", "entity", entity, "id", id, "error", err.Error())
+			}
+		}()
+	default:
+		slog.Warn("unknown connectwise webhook action", "entity", entity, "action", action, "id", id)
+	}
+
+	resultJSON(c, fmt.Sprintf("%s payload received", entity))
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
+	"github.com/thecoretg/ticketbot/internal/service/exportsvc"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// maxImportSize caps import documents, which are a few kilobytes even for large deployments.
+const maxImportSize = 4 << 20
+
+type ExportHandler struct {
+	Service *exportsvc.Service
+	Audit   *auditsvc.Service
+}
+
+func NewExportHandler(svc *exportsvc.Service, audit *auditsvc.Service) *ExportHandler {
+	return &ExportHandler{Service: svc, Audit: audit}
+}
+
+// HandleExport writes the config, rules and forwards as YAML, or JSON with ?format=json.
+func (h *ExportHandler) HandleExport(c *gin.Context) {
+	f, err := exportsvc.ParseFormat(c.Query("format"))
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	doc, err := h.Service.Export(c.Request.Context())
+	if err != nil {
+		internalServerError(c, fmt.Errorf("exporting: %w", err))
+		return
+	}
+
+	b, err := exportsvc.Encode(doc, f)
+	if err != nil {
+		internalServerError(c, fmt.Errorf("encoding export: %w", err))
+		return
+	}
+
+	c.Data(http.StatusOK, f.ContentType(), b)
+}
+
+// HandleImport applies an export document, read as JSON or YAML by ?format or the content type.
+// With ?dry_run=true it only reports what would change.
+func (h *ExportHandler) HandleImport(c *gin.Context) {
+	dryRun, err := queryBool(c, "dry_run")
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	// YAML is a superset of JSON, so anything not marked as JSON can be read as YAML
+	format := c.Query("format")
+	if format == "" && strings.Contains(c.ContentType(), "json") {
+		format = string(exportsvc.FormatJSON)
+	}
+	f, err := exportsvc.ParseFormat(format)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	b, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	if err != nil {
+		errJSON(c, http.StatusBadRequest, fmt.Errorf("reading import document: %w", err))
+		return
+	}
+
+	doc, err := exportsvc.Decode(b, f)
+	if err != nil {
+		errJSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Service.Import(c.Request.Context(), doc, dryRun != nil && *dryRun, currentActor(c))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidImport) {
+			errJSON(c, http.StatusBadRequest, err)
+			return
+		}
+		internalServerError(c, fmt.Errorf("importing: %w", err))
+		return
+	}
+
+	if !res.DryRun {
+		h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditConfigImport, models.AuditTargetConfig, ""), nil, res)
+	}
+
+	outputJSON(c, res)
+}
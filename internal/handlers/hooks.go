@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/webhooks"
+)
+
+type HookHandler struct {
+	Service *webhooks.Service
+}
+
+func NewHookHandler(svc *webhooks.Service) *HookHandler {
+	return &HookHandler{Service: svc}
+}
+
+func (h *HookHandler) HandleHealth(c *gin.Context) {
+	outputJSON(c, h.Service.Health())
+}
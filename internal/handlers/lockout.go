@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
+	"github.com/thecoretg/ticketbot/internal/service/authsvc"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type LockoutHandler struct {
+	svc   *authsvc.Service
+	audit *auditsvc.Service
+}
+
+func NewLockoutHandler(svc *authsvc.Service, audit *auditsvc.Service) *LockoutHandler {
+	return &LockoutHandler{svc: svc, audit: audit}
+}
+
+// HandleList returns current sign-in lockouts and recent lockout events.
+func (h *LockoutHandler) HandleList(c *gin.Context) {
+	r, err := h.svc.Lockouts(c.Request.Context())
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, r)
+}
+
+// HandleUnlock clears a user's sign-in lockout and failed attempt count.
+func (h *LockoutHandler) HandleUnlock(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	cleared, err := h.svc.UnlockUser(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrAPIUserNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	if cleared {
+		h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditUserUnlock, models.AuditTargetUser, strconv.Itoa(id)), nil, nil)
+	}
+	c.JSON(http.StatusOK, gin.H{"unlocked": cleared})
+}
+
+// HandleUnlockIP clears a client IP's sign-in lockout and failed attempt count.
+func (h *LockoutHandler) HandleUnlockIP(c *gin.Context) {
+	ip := c.Param("ip")
+	if net.ParseIP(ip) == nil {
+		errJSON(c, http.StatusBadRequest, fmt.Errorf("%s is not a valid IP address", ip))
+		return
+	}
+
+	cleared, err := h.svc.UnlockIP(c.Request.Context(), ip)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if cleared {
+		h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditIPUnlock, models.AuditTargetIP, ip), nil, nil)
+	}
+	c.JSON(http.StatusOK, gin.H{"unlocked": cleared})
+}
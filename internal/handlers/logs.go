@@ -1,18 +1,218 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/thecoretg/ticketbot/internal/logging"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	defaultLogPageSize = 100
+	maxLogPageSize     = 1000
+
+	// attrParamPrefix marks query params that filter on an attribute, like attr.ticket_id=123.
+	attrParamPrefix = "attr."
+
+	// exportFlushEvery is how many lines an export writes between flushes to the client.
+	exportFlushEvery = 500
+
+	// streamQueueSize is how many entries a stream client can fall behind by before entries are
+	// dropped for it.
+	streamQueueSize = 256
+	// streamHeartbeat is how often a stream sends a heartbeat event, which keeps idle connections
+	// from being closed by proxies and lets clients notice a dead one.
+	streamHeartbeat = 15 * time.Second
 )
 
 type LogsHandler struct {
-	buf *logging.BufferHandler
+	buf  *logging.BufferHandler
+	logs repos.LogRepository
 }
 
-func NewLogsHandler(buf *logging.BufferHandler) *LogsHandler {
-	return &LogsHandler{buf: buf}
+func NewLogsHandler(buf *logging.BufferHandler, logs repos.LogRepository) *LogsHandler {
+	return &LogsHandler{buf: buf, logs: logs}
 }
 
 func (h *LogsHandler) HandleList(c *gin.Context) {
 	outputJSON(c, h.buf.Entries())
 }
+
+// HandleQuery searches the persisted logs, newest first.
+func (h *LogsHandler) HandleQuery(c *gin.Context) {
+	f, err := logFilterFromQuery(c)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	entries, err := h.logs.Query(c.Request.Context(), f)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if len(entries) > 0 && len(entries) == f.Limit {
+		setNextLink(c, strconv.Itoa(entries[len(entries)-1].ID))
+	}
+
+	if entries == nil {
+		entries = []logging.LogEntry{}
+	}
+	outputJSON(c, entries)
+}
+
+// HandleExport streams every persisted log matching the query's filters as newline-delimited
+// JSON, oldest first. cursor and limit are ignored.
+func (h *LogsHandler) HandleExport(c *gin.Context) {
+	f, err := logFilterFromQuery(c)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	name := fmt.Sprintf("ticketbot-logs-%s.ndjson", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	n := 0
+	err = h.logs.Export(c.Request.Context(), f, func(e logging.LogEntry) error {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		n++
+		if n%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// the status is already sent, so all that's left is to cut the download short
+		slog.ErrorContext(c.Request.Context(), "logs: export failed", "written", n, "error", err.Error())
+		_ = c.Error(err)
+	}
+}
+
+// HandleStream sends entries as they're logged as server-sent events, filtered by the same query
+// params as HandleQuery apart from cursor and limit. Each entry is a "log" event. A "dropped" event
+// with a count comes before the next entry when the client fell behind and entries were skipped,
+// and a "heartbeat" event is sent every streamHeartbeat.
+func (h *LogsHandler) HandleStream(c *gin.Context) {
+	f, err := logFilterFromQuery(c)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	sub := h.buf.Subscribe(streamQueueSize, func(e logging.LogEntry) bool { return logging.Matches(e, f) })
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// stop nginx and similar proxies from holding events back
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.Entries():
+			if !ok {
+				// the server is shutting down
+				return
+			}
+			if n := sub.TakeDropped(); n > 0 {
+				c.SSEvent("dropped", gin.H{"count": n})
+			}
+			c.SSEvent("log", e)
+		case t := <-heartbeat.C:
+			c.SSEvent("heartbeat", gin.H{"time": t.UTC()})
+		}
+		c.Writer.Flush()
+	}
+}
+
+// logFilterFromQuery reads a log filter from the query. level is a comma-separated list of levels,
+// min_level includes that level and everything more severe, q searches message words, and each
+// attr.<key> param matches an attribute, with dots in key for nested groups.
+func logFilterFromQuery(c *gin.Context) (*models.LogFilter, error) {
+	var (
+		f   = &models.LogFilter{Text: queryString(c, "q")}
+		err error
+	)
+
+	if s := c.Query("level"); s != "" {
+		for l := range strings.SplitSeq(s, ",") {
+			l = strings.ToUpper(strings.TrimSpace(l))
+			if !slices.Contains(models.LogLevels, l) {
+				return nil, fmt.Errorf("level: %w: %s", models.ErrInvalidLogLevel, l)
+			}
+			f.Levels = append(f.Levels, l)
+		}
+	}
+
+	if s := c.Query("min_level"); s != "" {
+		if f.Levels != nil {
+			return nil, errors.New("level and min_level can't be used together")
+		}
+		if f.Levels, err = models.LevelsAtLeast(s); err != nil {
+			return nil, fmt.Errorf("min_level: %w", err)
+		}
+	}
+
+	if f.Since, err = queryTime(c, "since"); err != nil {
+		return nil, err
+	}
+
+	if f.Until, err = queryTime(c, "until"); err != nil {
+		return nil, err
+	}
+
+	for k, v := range c.Request.URL.Query() {
+		key, ok := strings.CutPrefix(k, attrParamPrefix)
+		if !ok {
+			continue
+		}
+		if key == "" || slices.Contains(strings.Split(key, "."), "") || len(v) == 0 || v[0] == "" {
+			return nil, fmt.Errorf("%s: attribute filters need a key and a value", k)
+		}
+		if f.Attrs == nil {
+			f.Attrs = make(map[string]string)
+		}
+		f.Attrs[key] = v[0]
+	}
+
+	if f.Cursor, err = queryInt(c, "cursor"); err != nil {
+		return nil, err
+	}
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	f.Limit = defaultLogPageSize
+	if limit != nil && *limit > 0 {
+		f.Limit = min(*limit, maxLogPageSize)
+	}
+
+	return f, nil
+}
@@ -3,19 +3,23 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thecoretg/ticketbot/models"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/notifier"
 )
 
 type NotifierHandler struct {
-	Svc notifier.Service
+	Svc   notifier.Service
+	Audit *auditsvc.Service
 }
 
-func NewNotifierHandler(svc *notifier.Service) *NotifierHandler {
+func NewNotifierHandler(svc *notifier.Service, audit *auditsvc.Service) *NotifierHandler {
 	return &NotifierHandler{
-		Svc: *svc,
+		Svc:   *svc,
+		Audit: audit,
 	}
 }
 
@@ -67,6 +71,7 @@ func (h *NotifierHandler) AddNotifierRule(c *gin.Context) {
 		return
 	}
 
+	h.Audit.Record(ctx, auditEntry(c, models.AuditNotifierRuleCreate, models.AuditTargetNotifierRule, strconv.Itoa(n.ID)), nil, n)
 	outputJSON(c, n)
 }
 
@@ -77,6 +82,9 @@ func (h *NotifierHandler) DeleteNotifierRule(c *gin.Context) {
 		return
 	}
 
+	// a failed lookup is left for the delete to report
+	before, _ := h.Svc.GetNotifierRule(c.Request.Context(), id)
+
 	if err := h.Svc.DeleteNotifierRule(c.Request.Context(), id); err != nil {
 		if errors.Is(err, models.ErrNotifierNotFound) {
 			notFoundError(c, err)
@@ -86,6 +94,7 @@ func (h *NotifierHandler) DeleteNotifierRule(c *gin.Context) {
 		return
 	}
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditNotifierRuleDelete, models.AuditTargetNotifierRule, strconv.Itoa(id)), before, nil)
 	c.Status(http.StatusOK)
 }
 
@@ -148,6 +157,7 @@ func (h *NotifierHandler) AddUserForward(c *gin.Context) {
 		return
 	}
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditForwardCreate, models.AuditTargetNotifierForward, strconv.Itoa(f.ID)), nil, f)
 	outputJSON(c, f)
 }
 
@@ -158,6 +168,8 @@ func (h *NotifierHandler) DeleteUserForward(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.Svc.GetForward(c.Request.Context(), id)
+
 	if err := h.Svc.DeleteForward(c.Request.Context(), id); err != nil {
 		if errors.Is(err, models.ErrUserForwardNotFound) {
 			notFoundError(c, err)
@@ -167,5 +179,6 @@ func (h *NotifierHandler) DeleteUserForward(c *gin.Context) {
 		return
 	}
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditForwardDelete, models.AuditTargetNotifierForward, strconv.Itoa(id)), before, nil)
 	c.Status(http.StatusOK)
 }
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/oidc"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
+	"github.com/thecoretg/ticketbot/internal/service/authsvc"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	// oidcCookie holds the state, nonce and PKCE verifier between sending the browser to the
+	// identity provider and it coming back.
+	oidcCookie     = "tb_oidc"
+	oidcCookieAge  = 600
+	oidcCookiePath = "/auth/oidc"
+	panelPath      = "/panel/"
+)
+
+type OIDCHandler struct {
+	provider *oidc.Provider
+	name     string
+	svc      *authsvc.Service
+	audit    *auditsvc.Service
+}
+
+// NewOIDCHandler returns a handler for single sign-on. provider is nil when it isn't configured,
+// in which case the login routes return 404.
+func NewOIDCHandler(provider *oidc.Provider, name string, svc *authsvc.Service, audit *auditsvc.Service) *OIDCHandler {
+	return &OIDCHandler{provider: provider, name: name, svc: svc, audit: audit}
+}
+
+// HandleStatus tells the panel whether to offer single sign-on.
+func (h *OIDCHandler) HandleStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"enabled": h.provider != nil, "name": h.name})
+}
+
+// HandleLogin sends the browser to the identity provider.
+func (h *OIDCHandler) HandleLogin(c *gin.Context) {
+	if h.provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "single sign-on is not configured"})
+		return
+	}
+
+	var vals [3]string
+	for i := range vals {
+		v, err := oidc.RandomString()
+		if err != nil {
+			internalServerError(c, err)
+			return
+		}
+		vals[i] = v
+	}
+	state, nonce, verifier := vals[0], vals[1], vals[2]
+
+	u, err := h.provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		slog.Error("oidc: building authorization url", "error", err.Error())
+		ssoFailed(c, "identity provider is unavailable")
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookie, strings.Join(vals[:], "."), oidcCookieAge, oidcCookiePath, "", false, true)
+	c.Redirect(http.StatusFound, u)
+}
+
+// HandleCallback finishes sign-in when the identity provider sends the browser back.
+func (h *OIDCHandler) HandleCallback(c *gin.Context) {
+	if h.provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "single sign-on is not configured"})
+		return
+	}
+
+	raw, _ := c.Cookie(oidcCookie)
+	c.SetCookie(oidcCookie, "", -1, oidcCookiePath, "", false, true)
+
+	if e := c.Query("error"); e != "" {
+		slog.Warn("oidc: provider returned an error", "error", e, "description", c.Query("error_description"))
+		ssoFailed(c, "sign-in was cancelled or denied")
+		return
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(c.Query("state"))) != 1 {
+		ssoFailed(c, "sign-in expired, please try again")
+		return
+	}
+	nonce, verifier := parts[1], parts[2]
+
+	id, err := h.provider.Exchange(c.Request.Context(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		slog.Error("oidc: exchanging code", "error", err.Error())
+		msg := "identity provider sign-in failed"
+		if errors.Is(err, oidc.ErrEmailMissing) || errors.Is(err, oidc.ErrEmailUnverified) {
+			msg = err.Error()
+		}
+		ssoFailed(c, msg)
+		return
+	}
+
+	ident := authsvc.SSOIdentity{Issuer: id.Issuer, Subject: id.Subject, Email: id.Email, Groups: id.Groups}
+	res, err := h.svc.LoginSSO(c.Request.Context(), ident, sessionClient(c))
+	if err != nil {
+		if errors.Is(err, authsvc.ErrSSONotAllowed) || errors.Is(err, authsvc.ErrSSOLinkedElsewhere) {
+			slog.Warn("oidc: sign-in refused", "email", id.Email, "reason", err.Error())
+			ssoFailed(c, "your account doesn't have access to ticketbot")
+			return
+		}
+		if errors.Is(err, authsvc.ErrThrottled) {
+			slog.Warn("oidc: sign-in refused while locked out", "email", id.Email)
+			ssoFailed(c, "sign-in is locked after too many failed attempts, try again later")
+			return
+		}
+		slog.Error("oidc: signing in", "email", id.Email, "error", err.Error())
+		ssoFailed(c, "sign-in failed")
+		return
+	}
+
+	h.auditSSO(c, res)
+	setSessionCookie(c, res.Token, h.svc.SessionMaxAge())
+	c.Redirect(http.StatusFound, panelPath)
+}
+
+// auditSSO records users provisioned or given a new role by sign-in, with the user as the actor.
+func (h *OIDCHandler) auditSSO(c *gin.Context, res *authsvc.SSOResult) {
+	var action models.AuditAction
+	switch {
+	case res.Previous == nil:
+		action = models.AuditUserCreate
+	case res.Previous.Role != res.User.Role:
+		action = models.AuditUserSetRole
+	default:
+		return
+	}
+
+	e := auditEntry(c, action, models.AuditTargetUser, strconv.Itoa(res.User.ID))
+	e.ActorUserID = &res.User.ID
+	e.ActorRole = res.User.Role
+	h.audit.Record(c.Request.Context(), e, res.Previous, res.User)
+}
+
+func ssoFailed(c *gin.Context, msg string) {
+	c.Redirect(http.StatusFound, panelPath+"?sso_error="+url.QueryEscape(msg))
+}
@@ -42,6 +42,11 @@ func badPayloadError(c *gin.Context, err error) {
 	errJSON(c, http.StatusBadRequest, e)
 }
 
+func badQueryError(c *gin.Context, err error) {
+	e := fmt.Errorf("bad query parameter: %w", err)
+	errJSON(c, http.StatusBadRequest, e)
+}
+
 func notFoundError(c *gin.Context, err error) {
 	errJSON(c, http.StatusNotFound, err)
 }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/searchsvc"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type SearchHandler struct {
+	Service *searchsvc.Service
+}
+
+func NewSearchHandler(svc *searchsvc.Service) *SearchHandler {
+	return &SearchHandler{Service: svc}
+}
+
+// HandleSearch returns ranked hits for q. Only each hit's snippet is HTML; see models.SearchHit.
+func (h *SearchHandler) HandleSearch(c *gin.Context) {
+	p, err := searchParamsFromQuery(c)
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+
+	hits, err := h.Service.Query(c.Request.Context(), p)
+	if err != nil {
+		if errors.Is(err, models.ErrEmptySearchQuery) {
+			errJSON(c, http.StatusBadRequest, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, hits)
+}
+
+func searchParamsFromQuery(c *gin.Context) (*models.SearchParams, error) {
+	var (
+		p   = &models.SearchParams{Query: c.Query("q")}
+		err error
+	)
+
+	if p.BoardID, err = queryInt(c, "board_id"); err != nil {
+		return nil, err
+	}
+
+	if p.CompanyID, err = queryInt(c, "company_id"); err != nil {
+		return nil, err
+	}
+
+	if p.Since, err = queryTime(c, "since"); err != nil {
+		return nil, err
+	}
+
+	if p.Until, err = queryTime(c, "until"); err != nil {
+		return nil, err
+	}
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	if limit != nil {
+		p.Limit = *limit
+	}
+
+	return p, nil
+}
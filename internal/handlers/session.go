@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
+	"github.com/thecoretg/ticketbot/internal/service/authsvc"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type SessionHandler struct {
+	svc   *authsvc.Service
+	audit *auditsvc.Service
+}
+
+func NewSessionHandler(svc *authsvc.Service, audit *auditsvc.Service) *SessionHandler {
+	return &SessionHandler{svc: svc, audit: audit}
+}
+
+// HandleList returns the caller's active sessions, flagging the one making the request.
+func (h *SessionHandler) HandleList(c *gin.Context) {
+	a := currentActor(c)
+	s, err := h.svc.ListSessions(c.Request.Context(), a.UserID, a.SessionID)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, s)
+}
+
+// HandleRevoke ends one of the caller's sessions. Revoking the current session also clears
+// the cookie, the same as logging out.
+func (h *SessionHandler) HandleRevoke(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	a := currentActor(c)
+	if err := h.svc.RevokeSession(c.Request.Context(), a.UserID, id); err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	if a.SessionID != nil && *a.SessionID == id {
+		c.SetCookie(cookieName, "", -1, "/", "", false, true)
+	}
+
+	h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditSessionRevoke, models.AuditTargetSession, strconv.Itoa(id)), nil, nil)
+	c.Status(http.StatusOK)
+}
+
+// HandleRevokeOthers ends every session the caller has except the one making the request.
+func (h *SessionHandler) HandleRevokeOthers(c *gin.Context) {
+	a := currentActor(c)
+	n, err := h.svc.RevokeOtherSessions(c.Request.Context(), a.UserID, a.SessionID)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if n > 0 {
+		h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditUserSessionsRevoke, models.AuditTargetUser, strconv.Itoa(a.UserID)), nil, gin.H{"revoked": n})
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": n})
+}
+
+// HandleRevokeUser lets an admin sign a user out of every session.
+func (h *SessionHandler) HandleRevokeUser(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	n, err := h.svc.RevokeAllSessions(c.Request.Context(), id)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditUserSessionsRevoke, models.AuditTargetUser, strconv.Itoa(id)), nil, gin.H{"revoked": n})
+	c.JSON(http.StatusOK, gin.H{"revoked": n})
+}
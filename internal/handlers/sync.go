@@ -2,24 +2,39 @@ package handlers
 
 import (
 	"context"
-	"log/slog"
+	"errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/thecoretg/ticketbot/models"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/syncsvc"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type SyncHandler struct {
-	Svc *syncsvc.Service
-	cfg *models.Config
+	Svc   *syncsvc.Service
+	Audit *auditsvc.Service
+	cfg   *runtimecfg.Store
 }
 
-func NewSyncHandler(svc *syncsvc.Service, cfg *models.Config) *SyncHandler {
-	return &SyncHandler{Svc: svc, cfg: cfg}
+func NewSyncHandler(svc *syncsvc.Service, cfg *runtimecfg.Store, audit *auditsvc.Service) *SyncHandler {
+	return &SyncHandler{Svc: svc, Audit: audit, cfg: cfg}
 }
 
 func (h *SyncHandler) HandleSyncStatus(c *gin.Context) {
-	status := &models.SyncStatusResponse{Status: h.Svc.IsSyncing()}
+	runs, err := h.Svc.ListRuns(c.Request.Context())
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	job := h.Svc.CurrentJob()
+	status := &models.SyncStatusResponse{
+		Status: job != nil,
+		Job:    job,
+		Runs:   runs,
+	}
 	c.JSON(200, status)
 }
 
@@ -32,15 +47,96 @@ func (h *SyncHandler) HandleSync(c *gin.Context) {
 	}
 
 	if p.MaxConcurrentSyncs == 0 {
-		p.MaxConcurrentSyncs = h.cfg.MaxConcurrentSyncs
+		p.MaxConcurrentSyncs = h.cfg.Load().MaxConcurrentSyncs
 	}
 
+	// the job outlives the request, so only keep its values
 	ctx := context.WithoutCancel(c.Request.Context())
-	go func() {
-		if err := h.Svc.Sync(ctx, p); err != nil {
-			slog.Error("syncing", "error", err.Error())
+	job, err := h.Svc.Sync(ctx, p, models.SyncTriggerManual)
+	if err != nil {
+		if errors.Is(err, models.ErrSyncInProgress) {
+			conflictError(c, err)
+			return
 		}
-	}()
+		internalServerError(c, err)
+		return
+	}
+
+	h.Audit.Record(ctx, auditEntry(c, models.AuditSyncStart, models.AuditTargetSyncJob, strconv.Itoa(job.ID)), nil, job.Payload)
+	outputJSON(c, job)
+}
+
+func (h *SyncHandler) ListSyncJobs(c *gin.Context) {
+	f := &models.SyncJobFilter{}
+
+	cursor, err := queryInt(c, "cursor")
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+	f.Cursor = cursor
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		badQueryError(c, err)
+		return
+	}
+	if limit != nil {
+		f.Limit = *limit
+	}
+
+	jobs, err := h.Svc.ListJobs(c.Request.Context(), f)
+	if err != nil {
+		internalServerError(c, err)
+		return
+	}
+
+	if len(jobs) > 0 && len(jobs) == f.Limit {
+		setNextLink(c, strconv.Itoa(jobs[len(jobs)-1].ID))
+	}
+
+	outputJSON(c, jobs)
+}
+
+func (h *SyncHandler) GetSyncJob(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	job, err := h.Svc.GetJob(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrSyncJobNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	outputJSON(c, job)
+}
+
+func (h *SyncHandler) CancelSyncJob(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	if err := h.Svc.CancelJob(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, models.ErrSyncJobNotFound):
+			notFoundError(c, err)
+		case errors.Is(err, models.ErrSyncJobNotRunning):
+			conflictError(c, err)
+		default:
+			internalServerError(c, err)
+		}
+		return
+	}
 
-	resultJSON(c, "sync started")
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditSyncCancel, models.AuditTargetSyncJob, strconv.Itoa(id)), nil, nil)
+	resultJSON(c, "sync job cancelling")
 }
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/metrics"
 	"github.com/thecoretg/ticketbot/internal/service/ticketbot"
 	"github.com/thecoretg/tctg-go/connectwise/psa"
 )
@@ -25,6 +27,7 @@ func (h *TicketbotHandler) ProcessTicket(c *gin.Context) {
 	}
 	id := w.ID
 	action := w.Action
+	metrics.WebhookReceived("ticket", action)
 
 	ctx := context.WithoutCancel(c.Request.Context())
 	switch action {
@@ -39,14 +42,18 @@ func (h *TicketbotHandler) ProcessTicket(c *gin.Context) {
 	resultJSON(c, "ticket payload received")
 }
 
+// processTicket is deferred by the Connectwise client if its circuit is open, and retried once
+// Connectwise recovers.
 func (h *TicketbotHandler) processTicket(ctx context.Context, id int) {
-	if err := h.Service.ProcessTicket(ctx, id); err != nil {
-		slog.Error("processing ticket webhook", "ticket_id", id, "error", err.Error())
+	key := fmt.Sprintf("ticket:%d", id)
+	process := func(ctx context.Context) error { return h.Service.ProcessTicket(ctx, id) }
+	if err := h.Service.CW.CWClient.RunOrDefer(ctx, key, process); err != nil {
+		slog.ErrorContext(ctx, "processing ticket webhook", "ticket_id", id, "error", err.Error())
 	}
 }
 
 func (h *TicketbotHandler) deleteTicket(ctx context.Context, id int) {
 	if err := h.Service.CW.SoftDeleteTicket(ctx, id); err != nil {
-		slog.Error("soft deleting ticket from webhook", "ticket_id", id, "error", err.Error())
+		slog.ErrorContext(ctx, "soft deleting ticket from webhook", "ticket_id", id, "error", err.Error())
 	}
 }
@@ -4,18 +4,21 @@ import (
 	"encoding/base64"
 	"errors"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/authsvc"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type TOTPHandler struct {
-	svc *authsvc.Service
+	svc   *authsvc.Service
+	audit *auditsvc.Service
 }
 
-func NewTOTPHandler(svc *authsvc.Service) *TOTPHandler {
-	return &TOTPHandler{svc: svc}
+func NewTOTPHandler(svc *authsvc.Service, audit *auditsvc.Service) *TOTPHandler {
+	return &TOTPHandler{svc: svc, audit: audit}
 }
 
 type totpVerifyRequest struct {
@@ -32,8 +35,11 @@ func (h *TOTPHandler) HandleVerify(c *gin.Context) {
 		return
 	}
 
-	token, resetRequired, recoveryCodeUsed, err := h.svc.VerifyTOTP(c.Request.Context(), req.PendingToken, req.Code)
+	token, resetRequired, recoveryCodeUsed, err := h.svc.VerifyTOTP(c.Request.Context(), req.PendingToken, req.Code, sessionClient(c))
 	if err != nil {
+		if throttled(c, err) {
+			return
+		}
 		if errors.Is(err, authsvc.ErrInvalidCredentials) || errors.Is(err, authsvc.ErrInvalidTOTPCode) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired code"})
 			return
@@ -42,7 +48,7 @@ func (h *TOTPHandler) HandleVerify(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie(cookieName, token, int(24*time.Hour/time.Second), "/", "", false, true)
+	setSessionCookie(c, token, h.svc.SessionMaxAge())
 	c.JSON(http.StatusOK, gin.H{"ok": true, "reset_required": resetRequired, "recovery_code_used": recoveryCodeUsed})
 }
 
@@ -93,6 +99,8 @@ func (h *TOTPHandler) HandleConfirmSetup(c *gin.Context) {
 		return
 	}
 
+	h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditTOTPEnable, models.AuditTargetUser, strconv.Itoa(userID)), nil, nil)
+
 	c.JSON(http.StatusOK, gin.H{"ok": true, "recovery_codes": codes})
 }
 
@@ -129,5 +137,7 @@ func (h *TOTPHandler) HandleDisable(c *gin.Context) {
 		return
 	}
 
+	h.audit.Record(c.Request.Context(), auditEntry(c, models.AuditTOTPDisable, models.AuditTargetUser, strconv.Itoa(userID)), nil, nil)
+
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
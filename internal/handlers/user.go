@@ -4,8 +4,11 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/authsvc"
 	"github.com/thecoretg/ticketbot/internal/service/user"
 	"github.com/thecoretg/ticketbot/models"
@@ -13,10 +16,11 @@ import (
 
 type UserHandler struct {
 	Service *user.Service
+	Audit   *auditsvc.Service
 }
 
-func NewUserHandler(svc *user.Service) *UserHandler {
-	return &UserHandler{Service: svc}
+func NewUserHandler(svc *user.Service, audit *auditsvc.Service) *UserHandler {
+	return &UserHandler{Service: svc, Audit: audit}
 }
 
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
@@ -35,7 +39,7 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	}
 
 	slog.Info("returning current user", "user_id", u.ID, "email", u.EmailAddress)
-	outputJSON(c, u)
+	outputJSON(c, models.CurrentUser{APIUser: *u, EffectiveRole: currentRole(c), Restriction: currentActor(c).Restriction})
 }
 
 func (h *UserHandler) ListUsers(c *gin.Context) {
@@ -69,8 +73,9 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 }
 
 type createUserRequest struct {
-	EmailAddress string `json:"email_address"`
-	Password     string `json:"password"` // optional; if set, user must reset on first login
+	EmailAddress string      `json:"email_address"`
+	Password     string      `json:"password"` // optional; if set, user must reset on first login
+	Role         models.Role `json:"role"`     // optional; defaults to viewer
 }
 
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -90,12 +95,16 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		u, err = h.Service.InsertUserWithPassword(c.Request.Context(), p.EmailAddress, p.Password)
+		u, err = h.Service.InsertUserWithPassword(c.Request.Context(), p.EmailAddress, p.Password, p.Role)
 	} else {
-		u, err = h.Service.InsertUser(c.Request.Context(), p.EmailAddress)
+		u, err = h.Service.InsertUser(c.Request.Context(), p.EmailAddress, p.Role)
 	}
 
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidRole) {
+			errJSON(c, http.StatusBadRequest, err)
+			return
+		}
 		if errors.Is(err, user.ErrUserAlreadyExists{Email: p.EmailAddress}) {
 			conflictError(c, err)
 			return
@@ -104,6 +113,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditUserCreate, models.AuditTargetUser, strconv.Itoa(u.ID)), nil, u)
 	outputJSON(c, u)
 }
 
@@ -120,6 +130,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		"authenticated_user_id", authenticatedUserID,
 		"target_user_id", id)
 
+	before, _ := h.Service.GetUser(c.Request.Context(), id)
+
 	if err := h.Service.DeleteUser(c.Request.Context(), id, authenticatedUserID); err != nil {
 		if errors.Is(err, models.ErrAPIUserNotFound) {
 			notFoundError(c, err)
@@ -137,11 +149,56 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		"authenticated_user_id", authenticatedUserID,
 		"deleted_user_id", id)
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditUserDelete, models.AuditTargetUser, strconv.Itoa(id)), before, nil)
+
 	c.Status(http.StatusOK)
 }
 
+func (h *UserHandler) SetUserRole(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	p := &models.SetRolePayload{}
+	if err := c.ShouldBindJSON(p); err != nil {
+		badPayloadError(c, err)
+		return
+	}
+
+	authenticatedUserID := c.GetInt("user_id")
+	before, _ := h.Service.GetUser(c.Request.Context(), id)
+
+	u, err := h.Service.SetRole(c.Request.Context(), id, p.Role, authenticatedUserID)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidRole) {
+			errJSON(c, http.StatusBadRequest, err)
+			return
+		}
+		if errors.Is(err, models.ErrAPIUserNotFound) {
+			notFoundError(c, err)
+			return
+		}
+		if errors.Is(err, user.ErrCannotChangeOwnRole{}) {
+			errJSON(c, http.StatusForbidden, err)
+			return
+		}
+		internalServerError(c, err)
+		return
+	}
+
+	slog.Info("user role changed",
+		"authenticated_user_id", authenticatedUserID,
+		"target_user_id", id,
+		"role", u.Role)
+
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditUserSetRole, models.AuditTargetUser, strconv.Itoa(id)), before, u)
+	outputJSON(c, u)
+}
+
 func (h *UserHandler) ListAPIKeys(c *gin.Context) {
-	k, err := h.Service.ListAPIKeys(c.Request.Context())
+	k, err := h.Service.ListAPIKeys(c.Request.Context(), currentActor(c))
 	if err != nil {
 		internalServerError(c, err)
 		return
@@ -157,7 +214,7 @@ func (h *UserHandler) GetAPIKey(c *gin.Context) {
 		return
 	}
 
-	k, err := h.Service.GetAPIKey(c.Request.Context(), id)
+	k, err := h.Service.GetAPIKey(c.Request.Context(), id, currentActor(c))
 	if err != nil {
 		if errors.Is(err, models.ErrAPIKeyNotFound) {
 			notFoundError(c, err)
@@ -177,18 +234,69 @@ func (h *UserHandler) AddAPIKey(c *gin.Context) {
 		return
 	}
 
-	k, err := h.Service.AddAPIKey(c.Request.Context(), p.Email)
+	k, err := h.Service.AddAPIKey(c.Request.Context(), p, currentActor(c))
 	if err != nil {
-		internalServerError(c, err)
+		apiKeyError(c, err)
+		return
+	}
+
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditAPIKeyCreate, models.AuditTargetAPIKey, strconv.Itoa(k.ID)), nil, auditKeyResponse(k))
+	outputJSON(c, k)
+}
+
+const defaultKeyGraceMinutes = 60
+
+// RotateAPIKey issues a replacement key and lets the old one keep working for the grace period.
+func (h *UserHandler) RotateAPIKey(c *gin.Context) {
+	id, err := convertID(c)
+	if err != nil {
+		badIntError(c)
+		return
+	}
+
+	p := &models.RotateAPIKeyPayload{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(p); err != nil {
+			badPayloadError(c, err)
+			return
+		}
+	}
+
+	grace := defaultKeyGraceMinutes
+	if p.GraceMinutes != nil {
+		grace = *p.GraceMinutes
+	}
+	if grace <= 0 {
+		errJSON(c, http.StatusBadRequest, errors.New("grace_minutes must be greater than 0"))
 		return
 	}
 
-	o := models.CreateAPIKeyResponse{
-		Email: p.Email,
-		Key:   k,
+	before, _ := h.Service.GetAPIKey(c.Request.Context(), id, currentActor(c))
+
+	k, err := h.Service.RotateAPIKey(c.Request.Context(), id, time.Duration(grace)*time.Minute, currentActor(c))
+	if err != nil {
+		apiKeyError(c, err)
+		return
 	}
 
-	outputJSON(c, o)
+	slog.Info("api key rotated", "old_key_id", id, "new_key_id", k.ID, "grace_minutes", grace)
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditAPIKeyRotate, models.AuditTargetAPIKey, strconv.Itoa(id)), auditKey(before), auditKeyResponse(k))
+	outputJSON(c, k)
+}
+
+func apiKeyError(c *gin.Context, err error) {
+	var tooHigh user.ErrRoleTooHigh
+	switch {
+	case errors.Is(err, models.ErrInvalidRole), errors.Is(err, models.ErrInvalidScope),
+		errors.Is(err, models.ErrInvalidKeyExpiry), errors.Is(err, models.ErrAPIKeyExpired):
+		errJSON(c, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrAPIUserNotFound), errors.Is(err, models.ErrAPIKeyNotFound):
+		notFoundError(c, err)
+	case errors.As(err, &tooHigh), errors.Is(err, user.ErrKeyNotOwned{}), errors.Is(err, user.ErrScopesTooBroad{}):
+		errJSON(c, http.StatusForbidden, err)
+	default:
+		internalServerError(c, err)
+	}
 }
 
 func (h *UserHandler) DeleteAPIKey(c *gin.Context) {
@@ -198,7 +306,9 @@ func (h *UserHandler) DeleteAPIKey(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.DeleteAPIKey(c.Request.Context(), id); err != nil {
+	before, _ := h.Service.GetAPIKey(c.Request.Context(), id, currentActor(c))
+
+	if err := h.Service.DeleteAPIKey(c.Request.Context(), id, currentActor(c)); err != nil {
 		if errors.Is(err, models.ErrAPIKeyNotFound) {
 			notFoundError(c, err)
 			return
@@ -207,5 +317,6 @@ func (h *UserHandler) DeleteAPIKey(c *gin.Context) {
 		return
 	}
 
+	h.Audit.Record(c.Request.Context(), auditEntry(c, models.AuditAPIKeyDelete, models.AuditTargetAPIKey, strconv.Itoa(id)), auditKey(before), nil)
 	c.Status(http.StatusOK)
 }
@@ -1,12 +1,95 @@
 package handlers
 
 import (
+	"fmt"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/models"
 )
 
+// currentRole is the effective role CombinedAuth set for the request.
+func currentRole(c *gin.Context) models.Role {
+	return models.Role(c.GetString("role"))
+}
+
+// currentActor is who CombinedAuth authenticated the request as.
+func currentActor(c *gin.Context) *models.Actor {
+	if a, ok := c.Get("actor"); ok {
+		return a.(*models.Actor)
+	}
+	return &models.Actor{UserID: c.GetInt("user_id"), Role: currentRole(c)}
+}
+
 func convertID(c *gin.Context) (int, error) {
 	s := c.Param("id")
 	return strconv.Atoi(s)
 }
+
+// queryInt returns nil if the query param is absent.
+func queryInt(c *gin.Context, key string) (*int, error) {
+	s := c.Query(key)
+	if s == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s is not a valid integer", key, s)
+	}
+
+	return &i, nil
+}
+
+// queryBool returns nil if the query param is absent.
+func queryBool(c *gin.Context, key string) (*bool, error) {
+	s := c.Query(key)
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s is not a valid boolean", key, s)
+	}
+
+	return &b, nil
+}
+
+// queryTime parses an RFC3339 query param and returns nil if it is absent.
+func queryTime(c *gin.Context, key string) (*time.Time, error) {
+	s := c.Query(key)
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s is not a valid RFC3339 timestamp", key, s)
+	}
+
+	return &t, nil
+}
+
+// queryString returns nil if the query param is absent.
+func queryString(c *gin.Context, key string) *string {
+	s := c.Query(key)
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// setNextLink adds a Link header pointing at the next page of the current request,
+// which the sdk follows in GetMany.
+func setNextLink(c *gin.Context, cursor string) {
+	u := url.URL{Path: c.Request.URL.Path}
+	q := c.Request.URL.Query()
+	q.Set("cursor", cursor)
+	u.RawQuery = q.Encode()
+
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, u.String()))
+}
@@ -7,7 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -23,15 +23,14 @@ var requiredCloudwatchEnvs = []string{
 	"AWS_REGION",
 }
 
+// CloudwatchHandler ships records to a CloudWatch log stream. Handle only queues the record; a
+// background shipper sends them in batches, so logging never waits on AWS. Call Close on shutdown
+// to send whatever is still queued.
 type CloudwatchHandler struct {
-	client        *cloudwatchlogs.Client
-	logGroupName  string
-	logStreamName string
-	sequenceToken *string
-	mu            sync.Mutex
-	attrs         []slog.Attr
-	groups        []string
-	level         slog.Leveler
+	shipper *cwShipper
+	attrs   []slog.Attr
+	groups  []string
+	level   slog.Leveler
 }
 
 type CloudwatchHandlerParams struct {
@@ -42,6 +41,8 @@ type CloudwatchHandlerParams struct {
 	StreamName      string
 	Level           slog.Leveler
 	RetentionDays   int32 // Number of days to retain logs (0 = never expire)
+	// Endpoint overrides the CloudWatch Logs endpoint, for a local stand-in like LocalStack.
+	Endpoint string
 }
 
 func NewCloudwatchLogger(ctx context.Context, params CloudwatchHandlerParams) (*CloudwatchHandler, error) {
@@ -59,7 +60,11 @@ func NewCloudwatchLogger(ctx context.Context, params CloudwatchHandlerParams) (*
 		return nil, fmt.Errorf("unable to load SDK config: %w", err)
 	}
 
-	client := cloudwatchlogs.NewFromConfig(cfg)
+	client := cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
+		if params.Endpoint != "" {
+			o.BaseEndpoint = aws.String(params.Endpoint)
+		}
+	})
 
 	// create log group if it doesn't exist
 	_, err = client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
@@ -94,11 +99,12 @@ func NewCloudwatchLogger(ctx context.Context, params CloudwatchHandlerParams) (*
 		slog.Info("created log stream", "group_name", params.GroupName, "stream_name", streamTime)
 	}
 
+	shipper := newCWShipper(client, params.GroupName, streamTime)
+	go shipper.run()
+
 	return &CloudwatchHandler{
-		client:        client,
-		logGroupName:  params.GroupName,
-		logStreamName: streamTime,
-		level:         params.Level,
+		shipper: shipper,
+		level:   params.Level,
 	}, nil
 }
 
@@ -130,31 +136,32 @@ func (h *CloudwatchHandler) Handle(ctx context.Context, record slog.Record) erro
 		return fmt.Errorf("failed to marshal log entry: %w", err)
 	}
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
+	h.shipper.enqueue(types.InputLogEvent{
+		Message:   aws.String(truncateEvent(string(message))),
+		Timestamp: aws.Int64(record.Time.UnixMilli()),
+	})
+	return nil
+}
 
-	input := &cloudwatchlogs.PutLogEventsInput{
-		LogGroupName:  aws.String(h.logGroupName),
-		LogStreamName: aws.String(h.logStreamName),
-		LogEvents: []types.InputLogEvent{
-			{
-				Message:   aws.String(string(message)),
-				Timestamp: aws.Int64(record.Time.UnixMilli()),
-			},
-		},
-	}
+// Close sends the records still queued and stops shipping. Records handled after Close are
+// dropped. It gives up when ctx is done.
+func (h *CloudwatchHandler) Close(ctx context.Context) error {
+	return h.shipper.close(ctx)
+}
 
-	if h.sequenceToken != nil {
-		input.SequenceToken = h.sequenceToken
-	}
+// Dropped is how many records haven't reached CloudWatch because the queue was full, sending
+// failed or CloudWatch rejected them.
+func (h *CloudwatchHandler) Dropped() int64 {
+	return h.shipper.dropped.Load()
+}
 
-	result, err := h.client.PutLogEvents(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to put log events: %w", err)
+// truncateEvent cuts msg down to the largest message CloudWatch accepts, keeping it valid UTF-8.
+func truncateEvent(msg string) string {
+	const marker = "...(truncated)"
+	if len(msg) <= cwMaxEventBytes {
+		return msg
 	}
-
-	h.sequenceToken = result.NextSequenceToken
-	return nil
+	return strings.ToValidUTF8(msg[:cwMaxEventBytes-len(marker)], "") + marker
 }
 
 func (h *CloudwatchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -163,13 +170,10 @@ func (h *CloudwatchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	copy(newAttrs[len(h.attrs):], attrs)
 
 	return &CloudwatchHandler{
-		client:        h.client,
-		logGroupName:  h.logGroupName,
-		logStreamName: h.logStreamName,
-		sequenceToken: h.sequenceToken,
-		level:         h.level,
-		attrs:         newAttrs,
-		groups:        h.groups,
+		shipper: h.shipper,
+		level:   h.level,
+		attrs:   newAttrs,
+		groups:  h.groups,
 	}
 }
 
@@ -179,13 +183,10 @@ func (h *CloudwatchHandler) WithGroup(name string) slog.Handler {
 	newGroups[len(h.groups)] = name
 
 	return &CloudwatchHandler{
-		client:        h.client,
-		logGroupName:  h.logGroupName,
-		logStreamName: h.logStreamName,
-		sequenceToken: h.sequenceToken,
-		level:         h.level,
-		attrs:         h.attrs,
-		groups:        newGroups,
+		shipper: h.shipper,
+		level:   h.level,
+		attrs:   h.attrs,
+		groups:  newGroups,
 	}
 }
 
@@ -208,6 +209,8 @@ func GetCloudwatchParamsFromEnv(level slog.Leveler) CloudwatchHandlerParams {
 		p.StreamName = streamEnv
 	}
 
+	p.Endpoint = os.Getenv("CLOUDWATCH_ENDPOINT")
+
 	if retentionEnv := os.Getenv("CLOUDWATCH_RETENTION_DAYS"); retentionEnv != "" {
 		if days, err := strconv.Atoi(retentionEnv); err == nil {
 			p.RetentionDays = int32(days)
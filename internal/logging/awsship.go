@@ -0,0 +1,280 @@
+package logging
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/thecoretg/ticketbot/internal/metrics"
+)
+
+// PutLogEvents limits, from the CloudWatch Logs API reference. Each event counts its message
+// length plus cwEventOverhead bytes towards the batch size.
+const (
+	cwMaxBatchEvents = 10_000
+	cwMaxBatchBytes  = 1_048_576
+	cwEventOverhead  = 26
+	cwMaxEventBytes  = 262_144 - cwEventOverhead
+	cwMaxBatchSpan   = 24 * time.Hour
+)
+
+const (
+	cwQueueSize     = 10_000
+	cwFlushInterval = 2 * time.Second
+	cwMaxAttempts   = 5
+	cwRetryDelay    = 250 * time.Millisecond
+)
+
+// cloudwatchAPI is the part of the CloudWatch Logs client the shipper uses.
+type cloudwatchAPI interface {
+	PutLogEvents(ctx context.Context, in *cloudwatchlogs.PutLogEventsInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogStream(ctx context.Context, in *cloudwatchlogs.CreateLogStreamInput, opts ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
+// cwShipper sends events to a log stream in the background. Handlers derived with WithAttrs and
+// WithGroup share one, so they all feed the same queue.
+type cwShipper struct {
+	client cloudwatchAPI
+	group  string
+	stream string
+
+	queue      chan types.InputLogEvent
+	flushEvery time.Duration
+	retryDelay time.Duration
+
+	// ctx is cancelled if Close gives up waiting, to cut short a send that's still retrying.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	stopOnce sync.Once
+	// stopMu is held for reading by enqueue from checking stop until the event is queued, so once
+	// close has closed stop under the write lock, run's final drain sees every queued event.
+	stopMu sync.RWMutex
+	done   chan struct{}
+
+	dropped atomic.Int64
+	// errLog reports shipping problems to stderr, since logging them through slog would send them
+	// back to this shipper.
+	errLog *slog.Logger
+}
+
+func newCWShipper(client cloudwatchAPI, group, stream string) *cwShipper {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &cwShipper{
+		client:     client,
+		group:      group,
+		stream:     stream,
+		queue:      make(chan types.InputLogEvent, cwQueueSize),
+		flushEvery: cwFlushInterval,
+		retryDelay: cwRetryDelay,
+		ctx:        ctx,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		errLog:     slog.New(slog.NewJSONHandler(os.Stderr, nil)),
+	}
+}
+
+// enqueue adds ev to the queue, dropping it if the queue is full rather than holding up the caller.
+func (s *cwShipper) enqueue(ev types.InputLogEvent) {
+	s.stopMu.RLock()
+	defer s.stopMu.RUnlock()
+
+	select {
+	case <-s.stop:
+		s.drop("closed", 1)
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- ev:
+	default:
+		s.drop("queue full", 1)
+	}
+}
+
+func (s *cwShipper) drop(reason string, n int) {
+	s.dropped.Add(int64(n))
+	metrics.CloudwatchDropped(reason, n)
+}
+
+// run batches queued events, sending a batch when the next event wouldn't fit in it or every
+// flushEvery, until close is called. Events still queued then are sent before it returns.
+func (s *cwShipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushEvery)
+	defer ticker.Stop()
+
+	var b cwBatch
+	add := func(ev types.InputLogEvent) {
+		if !b.fits(ev) {
+			s.send(b.take())
+		}
+		b.add(ev)
+	}
+
+	for {
+		select {
+		case ev := <-s.queue:
+			add(ev)
+		case <-ticker.C:
+			if len(b.events) > 0 {
+				s.send(b.take())
+			}
+		case <-s.stop:
+			for {
+				select {
+				case ev := <-s.queue:
+					add(ev)
+				default:
+					if len(b.events) > 0 {
+						s.send(b.take())
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// send puts events to the stream, retrying with backoff. A stream that's gone missing is created
+// again before the next attempt.
+func (s *cwShipper) send(events []types.InputLogEvent) {
+	// events must be in time order within a batch, and records can reach the queue slightly out of
+	// order when logged from several goroutines
+	slices.SortStableFunc(events, func(a, b types.InputLogEvent) int {
+		return cmp.Compare(*a.Timestamp, *b.Timestamp)
+	})
+
+	in := &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+		LogEvents:     events,
+	}
+
+	delay := s.retryDelay
+	for attempt := 1; ; attempt++ {
+		out, err := s.client.PutLogEvents(s.ctx, in)
+		if err == nil {
+			if n := rejectedCount(out.RejectedLogEventsInfo, len(events)); n > 0 {
+				s.errLog.Warn("cloudwatch: log events rejected", "rejected", n, "events", len(events))
+				s.drop("rejected", n)
+			}
+			return
+		}
+
+		var invalid *types.InvalidParameterException
+		if errors.As(err, &invalid) || attempt == cwMaxAttempts || s.ctx.Err() != nil {
+			s.errLog.Error("cloudwatch: sending log events", "events", len(events), "attempts", attempt, "error", err.Error())
+			s.drop("send failed", len(events))
+			return
+		}
+
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			if _, err := s.client.CreateLogStream(s.ctx, &cloudwatchlogs.CreateLogStreamInput{
+				LogGroupName:  aws.String(s.group),
+				LogStreamName: aws.String(s.stream),
+			}); err != nil {
+				s.errLog.Error("cloudwatch: recreating log stream", "group_name", s.group, "stream_name", s.stream, "error", err.Error())
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-s.ctx.Done():
+		}
+		delay *= 2
+	}
+}
+
+// close sends everything queued so far and stops the shipper. If ctx ends first, the send in
+// progress is abandoned and the rest of the queue is dropped.
+func (s *cwShipper) close(ctx context.Context) error {
+	s.stopMu.Lock()
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.stopMu.Unlock()
+
+	select {
+	case <-s.done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-s.done
+		return ctx.Err()
+	}
+}
+
+// rejectedCount is how many of n events CloudWatch reported as too old, expired or too new.
+func rejectedCount(info *types.RejectedLogEventsInfo, n int) int {
+	if info == nil {
+		return 0
+	}
+
+	rejected := 0
+	if info.TooOldLogEventEndIndex != nil {
+		rejected = int(*info.TooOldLogEventEndIndex)
+	}
+	if info.ExpiredLogEventEndIndex != nil {
+		rejected = max(rejected, int(*info.ExpiredLogEventEndIndex))
+	}
+	if info.TooNewLogEventStartIndex != nil {
+		rejected += n - int(*info.TooNewLogEventStartIndex)
+	}
+
+	return min(rejected, n)
+}
+
+// cwBatch collects events for one PutLogEvents call.
+type cwBatch struct {
+	events []types.InputLogEvent
+	bytes  int
+	// oldest and newest are the batch's event timestamps in milliseconds
+	oldest int64
+	newest int64
+}
+
+func (b *cwBatch) fits(ev types.InputLogEvent) bool {
+	if len(b.events) == 0 {
+		return true
+	}
+
+	ts := *ev.Timestamp
+	span := max(b.newest, ts) - min(b.oldest, ts)
+	return len(b.events) < cwMaxBatchEvents &&
+		b.bytes+eventBytes(ev) <= cwMaxBatchBytes &&
+		span < cwMaxBatchSpan.Milliseconds()
+}
+
+func (b *cwBatch) add(ev types.InputLogEvent) {
+	ts := *ev.Timestamp
+	if len(b.events) == 0 {
+		b.oldest, b.newest = ts, ts
+	}
+	b.oldest = min(b.oldest, ts)
+	b.newest = max(b.newest, ts)
+	b.events = append(b.events, ev)
+	b.bytes += eventBytes(ev)
+}
+
+func (b *cwBatch) take() []types.InputLogEvent {
+	events := b.events
+	*b = cwBatch{}
+	return events
+}
+
+func eventBytes(ev types.InputLogEvent) int {
+	return len(*ev.Message) + cwEventOverhead
+}
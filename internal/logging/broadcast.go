@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/thecoretg/ticketbot/internal/metrics"
+)
+
+// Broadcaster fans log entries out to live subscribers, such as /logs/stream clients. Each
+// subscriber has its own bounded queue, and entries are dropped for a subscriber whose queue is
+// full, so a slow client never holds up logging.
+type Broadcaster struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives the entries published after it was made that its match func accepts.
+type Subscription struct {
+	b       *Broadcaster
+	ch      chan LogEntry
+	match   func(LogEntry) bool
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+func newBroadcaster() *Broadcaster {
+	return &Broadcaster{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe starts a subscription with a queue of size entries. match may be nil to receive
+// everything. Close the subscription when done with it.
+func (b *Broadcaster) Subscribe(size int, match func(LogEntry) bool) *Subscription {
+	s := &Subscription{b: b, ch: make(chan LogEntry, size), match: match}
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	metrics.LogStreamSubscribed(1)
+	return s
+}
+
+func (b *Broadcaster) publish(e LogEntry) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for s := range b.subs {
+		if s.match != nil && !s.match(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+			metrics.LogStreamDropped()
+		}
+	}
+}
+
+// Entries is closed when the subscription is.
+func (s *Subscription) Entries() <-chan LogEntry {
+	return s.ch
+}
+
+// TakeDropped returns how many entries were dropped since the last call because the queue was
+// full.
+func (s *Subscription) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.b.mu.Lock()
+		delete(s.b.subs, s)
+		s.b.mu.Unlock()
+
+		close(s.ch)
+		metrics.LogStreamSubscribed(-1)
+	})
+}
+
+// CloseAll closes every subscription, so streams end instead of holding up a server shutdown.
+func (b *Broadcaster) CloseAll() {
+	b.mu.RLock()
+	subs := make([]*Subscription, 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		s.Close()
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/thecoretg/ticketbot/internal/metrics"
 )
 
 const defaultBufferSize = 500
 
-// LogEntry is a single buffered log record.
+// LogEntry is a single buffered log record. ID is only set on entries read back from the database.
 type LogEntry struct {
+	ID      int            `json:"id,omitempty"`
 	Time    time.Time      `json:"time"`
 	Level   string         `json:"level"`
 	Message string         `json:"message"`
@@ -25,6 +28,10 @@ type ring struct {
 	size  int
 	head  int
 	count int
+
+	// bc lives on the ring so that handlers derived with WithAttrs and WithGroup publish to the
+	// same subscribers.
+	bc *Broadcaster
 }
 
 func (r *ring) push(e LogEntry) {
@@ -93,7 +100,7 @@ func NewBufferHandler(inner slog.Handler, size int) *BufferHandler {
 	}
 	return &BufferHandler{
 		inner: inner,
-		r:     &ring{buf: make([]LogEntry, size), size: size},
+		r:     &ring{buf: make([]LogEntry, size), size: size, bc: newBroadcaster()},
 	}
 }
 
@@ -107,8 +114,8 @@ func (h *BufferHandler) Handle(ctx context.Context, rec slog.Record) error {
 		return err
 	}
 
-	// exclude healthcheck and log-poll noise from the ring buffer and DB
-	if strings.Contains(rec.Message, "/healthcheck") || strings.Contains(rec.Message, "/logs") {
+	// exclude healthcheck, log-poll and metrics scrape noise from the ring buffer and DB
+	if strings.Contains(rec.Message, "/healthcheck") || strings.Contains(rec.Message, "/logs") || strings.Contains(rec.Message, "/metrics") {
 		return nil
 	}
 
@@ -135,10 +142,12 @@ func (h *BufferHandler) Handle(ctx context.Context, rec slog.Record) error {
 	}
 
 	h.r.push(entry)
+	h.r.bc.publish(entry)
 	if h.persistCh != nil {
 		select {
 		case h.persistCh <- entry:
 		default: // drop if channel is full rather than block
+			metrics.LogsDropped("queue full", 1)
 		}
 	}
 	return nil
@@ -202,6 +211,16 @@ func (h *BufferHandler) Resize(newSize int) {
 // Size returns the current buffer capacity.
 func (h *BufferHandler) Size() int { return h.r.size }
 
+// Subscribe starts a live subscription to entries as they're logged. See Broadcaster.Subscribe.
+func (h *BufferHandler) Subscribe(size int, match func(LogEntry) bool) *Subscription {
+	return h.r.bc.Subscribe(size, match)
+}
+
+// CloseSubscribers ends every live subscription.
+func (h *BufferHandler) CloseSubscribers() {
+	h.r.bc.CloseAll()
+}
+
 // Entries returns buffered entries in chronological order (oldest first).
 func (h *BufferHandler) Entries() []LogEntry {
 	return h.r.entries()
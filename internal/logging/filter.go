@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// Matches reports whether e passes f's level, time, text and attribute filters. It's the
+// in-memory counterpart of the database query, for entries that haven't been stored yet: text
+// is matched as a case-insensitive substring of the message rather than by words.
+func Matches(e LogEntry, f *models.LogFilter) bool {
+	if len(f.Levels) > 0 && !slices.Contains(f.Levels, e.Level) {
+		return false
+	}
+	if f.Since != nil && e.Time.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && !e.Time.Before(*f.Until) {
+		return false
+	}
+	if f.Text != nil && !strings.Contains(strings.ToLower(e.Message), strings.ToLower(*f.Text)) {
+		return false
+	}
+
+	for k, want := range f.Attrs {
+		v, ok := attrAt(e.Attrs, k)
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+
+	return true
+}
+
+// attrAt looks up the attribute at the dotted path key, descending into groups.
+func attrAt(attrs map[string]any, key string) (any, bool) {
+	var cur any = attrs
+	for part := range strings.SplitSeq(key, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
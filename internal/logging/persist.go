@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"time"
+
+	"github.com/thecoretg/ticketbot/internal/metrics"
 )
 
 // LogPersistRepository is the subset of the log repo the persister needs.
@@ -19,23 +21,27 @@ type LogConfig interface {
 	GetLogCleanupIntervalHours() int
 }
 
+// LogConfigFunc returns the current config each time the persister needs it, so changes apply
+// without a restart.
+type LogConfigFunc func() LogConfig
+
 // Persister batches log entries from a BufferHandler into the DB and
 // runs a periodic cleanup goroutine to enforce the retention policy.
 type Persister struct {
-	repo   LogPersistRepository
-	buf    *BufferHandler
-	cfg    LogConfig
-	ch     chan LogEntry
+	repo LogPersistRepository
+	buf  *BufferHandler
+	cfg  LogConfigFunc
+	ch   chan LogEntry
 }
 
 const (
-	flushInterval  = 5 * time.Second
-	channelBuf     = 2000
+	flushInterval = 5 * time.Second
+	channelBuf    = 2000
 )
 
 // NewPersister wires the persister to the buffer handler.
 // Call Start to launch the background goroutines.
-func NewPersister(repo LogPersistRepository, buf *BufferHandler, cfg LogConfig) *Persister {
+func NewPersister(repo LogPersistRepository, buf *BufferHandler, cfg LogConfigFunc) *Persister {
 	ch := make(chan LogEntry, channelBuf)
 	buf.persistCh = ch
 	return &Persister{repo: repo, buf: buf, cfg: cfg, ch: ch}
@@ -69,6 +75,7 @@ func (p *Persister) runWriter(ctx context.Context) {
 		}
 		if err := p.repo.InsertBatch(ctx, batch); err != nil {
 			slog.Warn("log persister: failed to write batch", "error", err, "count", len(batch))
+			metrics.LogsDropped("write failed", len(batch))
 		}
 		batch = batch[:0]
 	}
@@ -102,7 +109,7 @@ func (p *Persister) runCleanup(ctx context.Context) {
 	var lastCleanup time.Time
 
 	doCleanup := func() {
-		retentionDays := p.cfg.GetLogRetentionDays()
+		retentionDays := p.cfg().GetLogRetentionDays()
 		if retentionDays <= 0 {
 			return
 		}
@@ -124,7 +131,7 @@ func (p *Persister) runCleanup(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			intervalHours := p.cfg.GetLogCleanupIntervalHours()
+			intervalHours := p.cfg().GetLogCleanupIntervalHours()
 			if intervalHours <= 0 {
 				intervalHours = 24
 			}
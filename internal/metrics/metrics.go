@@ -0,0 +1,182 @@
+// Package metrics exports Prometheus metrics for ticketbot. The collectors are package level so any
+// component can record to them without being handed a registry; they're served by Handler.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ticketbot"
+
+// Results for ticket processing and sync runs.
+const (
+	ResultOK       = "ok"
+	ResultError    = "error"
+	ResultDeferred = "deferred"
+)
+
+// Services for external API metrics.
+const (
+	ServiceConnectwise = "connectwise"
+	ServiceWebex       = "webex"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	webhooksReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_received_total",
+		Help:      "Connectwise webhooks received, by entity and action.",
+	}, []string{"entity", "action"})
+
+	ticketDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ticket_processing_duration_seconds",
+		Help:      "Time to process a ticket webhook, including notifications, by result.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"result"})
+
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_requests_total",
+		Help:      "Requests to Connectwise and Webex, by service, endpoint and status code.",
+	}, []string{"service", "endpoint", "status"})
+
+	apiDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of requests to Connectwise and Webex, by service and endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "endpoint"})
+
+	notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Ticket notifications by outcome (sent, skipped or failed), reason and recipient type.",
+	}, []string{"outcome", "reason", "recipient_type"})
+
+	syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Time taken by sync runs, by sync type and result.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	}, []string{"type", "result"})
+
+	syncItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_items_total",
+		Help:      "Items handled by sync jobs, by sync type and what happened to them.",
+	}, []string{"type", "item"})
+
+	logsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "log_entries_dropped_total",
+		Help:      "Log entries that weren't stored in the database, by reason.",
+	}, []string{"reason"})
+
+	cloudwatchDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cloudwatch_events_dropped_total",
+		Help:      "Log events that weren't delivered to CloudWatch, by reason.",
+	}, []string{"reason"})
+
+	logStreamSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "log_stream_subscribers",
+		Help:      "Clients connected to the live log stream.",
+	})
+
+	logStreamDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "log_stream_dropped_total",
+		Help:      "Log entries not sent to a live log stream client because it fell behind.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		webhooksReceived,
+		ticketDuration,
+		apiRequests,
+		apiDuration,
+		notifications,
+		syncDuration,
+		syncItems,
+		logsDropped,
+		cloudwatchDropped,
+		logStreamSubscribers,
+		logStreamDropped,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+func WebhookReceived(entity, action string) {
+	webhooksReceived.WithLabelValues(entity, action).Inc()
+}
+
+// TicketProcessed records a processed ticket webhook. A deferred ticket waited for the
+// Connectwise circuit to close and isn't counted as an error. Failures are the histogram's
+// count with result="error".
+func TicketProcessed(took time.Duration, result string) {
+	ticketDuration.WithLabelValues(result).Observe(took.Seconds())
+}
+
+// APIRequest records a request to an external service. Endpoints should be route templates,
+// like "GET service/tickets/:id", so the label stays bounded.
+func APIRequest(service, endpoint, status string, took time.Duration) {
+	apiRequests.WithLabelValues(service, endpoint, status).Inc()
+	apiDuration.WithLabelValues(service, endpoint).Observe(took.Seconds())
+}
+
+func NotificationSent(recipientType string) {
+	notifications.WithLabelValues("sent", "", recipientType).Inc()
+}
+
+func NotificationSkipped(reason string) {
+	notifications.WithLabelValues("skipped", reason, "").Inc()
+}
+
+func NotificationFailed(reason, recipientType string) {
+	notifications.WithLabelValues("failed", reason, recipientType).Inc()
+}
+
+func SyncRun(syncType string, took time.Duration, result string) {
+	syncDuration.WithLabelValues(syncType, result).Observe(took.Seconds())
+}
+
+// SyncItems adds n items of a sync type, where item is what happened to them, such as
+// "fetched" or "failed".
+func SyncItems(syncType, item string, n int) {
+	if n > 0 {
+		syncItems.WithLabelValues(syncType, item).Add(float64(n))
+	}
+}
+
+func LogsDropped(reason string, n int) {
+	logsDropped.WithLabelValues(reason).Add(float64(n))
+}
+
+func CloudwatchDropped(reason string, n int) {
+	cloudwatchDropped.WithLabelValues(reason).Add(float64(n))
+}
+
+// LogStreamSubscribed adjusts the live log stream client count by delta.
+func LogStreamSubscribed(delta int) {
+	logStreamSubscribers.Add(float64(delta))
+}
+
+func LogStreamDropped() {
+	logStreamDropped.Inc()
+}
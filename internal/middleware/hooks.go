@@ -34,6 +34,15 @@ func RequireConnectwiseSignature() gin.HandlerFunc {
 	}
 }
 
+// TrackHookReceived calls mark for every request that reaches it, for monitoring
+// when callbacks were last delivered.
+func TrackHookReceived(mark func()) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mark()
+		c.Next()
+	}
+}
+
 func RequireWebexSignature(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bodyBytes, err := io.ReadAll(c.Request.Body)
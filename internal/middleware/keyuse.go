@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/thecoretg/ticketbot/internal/repos"
+)
+
+const (
+	// keyUseFlushDelay is how long uses are collected before they're written, so a burst of
+	// requests with the same key costs one write.
+	keyUseFlushDelay = 5 * time.Second
+	keyUseTimeout    = 10 * time.Second
+)
+
+// keyUseRecorder writes API key last-used times and IPs off the request path. Uses are collected
+// per key and written together shortly after the first one comes in.
+type keyUseRecorder struct {
+	keys repos.APIKeyRepository
+
+	mu        sync.Mutex
+	pending   map[int]string
+	scheduled bool
+}
+
+func newKeyUseRecorder(keys repos.APIKeyRepository) *keyUseRecorder {
+	return &keyUseRecorder{keys: keys, pending: make(map[int]string)}
+}
+
+// record queues a use of key id from ip. It never blocks on the database.
+func (r *keyUseRecorder) record(id int, ip string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending[id] = ip
+	if !r.scheduled {
+		r.scheduled = true
+		time.AfterFunc(keyUseFlushDelay, r.flush)
+	}
+}
+
+func (r *keyUseRecorder) flush() {
+	r.mu.Lock()
+	uses := r.pending
+	r.pending = make(map[int]string)
+	r.scheduled = false
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyUseTimeout)
+	defer cancel()
+
+	for id, ip := range uses {
+		if err := r.keys.SetLastUsed(ctx, id, ip); err != nil {
+			slog.Warn("recording api key use", "key_id", id, "error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// restrictedRoutes are what a restricted session can still reach, keyed by method and route
+// pattern. /users/me is always allowed so the panel can find out what the user has to do.
+var restrictedRoutes = map[models.Restriction]map[string]bool{
+	models.RestrictionPasswordReset: {
+		"GET /users/me":      true,
+		"PUT /auth/password": true,
+	},
+	models.RestrictionTOTPSetup: {
+		"GET /users/me":         true,
+		"PUT /auth/password":    true,
+		"GET /auth/totp":        true,
+		"POST /auth/totp/setup": true,
+		"PUT /auth/totp/setup":  true,
+	},
+}
+
+// restrictionAllows reports whether a session with restriction r may use the matched route.
+func restrictionAllows(c *gin.Context, r models.Restriction) bool {
+	if r == models.RestrictionNone {
+		return true
+	}
+	return restrictedRoutes[r][c.Request.Method+" "+c.FullPath()]
+}
+
+func abortRestricted(c *gin.Context, r models.Restriction) {
+	msg := "password change required"
+	if r == models.RestrictionTOTPSetup {
+		msg = "two-factor authentication setup required"
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg, "restriction": r})
+}
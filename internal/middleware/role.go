@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// RequireRole rejects requests whose effective role, as set by CombinedAuth, is below min.
+// It must run after CombinedAuth.
+func RequireRole(min models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := models.Role(c.GetString("role"))
+		if !role.AtLeast(min) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this requires the " + string(min) + " role"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope rejects API key requests whose key isn't scoped to area. GET and HEAD requests need
+// read access; anything else needs write. Sessions and unscoped keys always pass. It must run after
+// CombinedAuth.
+func RequireScope(area string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := c.Get("actor")
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+
+		write := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+		if !models.ScopeAllows(actor.(*models.Actor).Scopes, area, write) {
+			level := models.ScopeRead
+			if write {
+				level = models.ScopeWrite
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key is missing the " + area + ":" + level + " scope"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession rejects API key requests, for account routes like changing a password or turning
+// off TOTP that a leaked key shouldn't be able to reach whatever its scopes. It must run after
+// CombinedAuth.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := c.Get("actor")
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+
+		if actor.(*models.Actor).APIKeyID != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this can only be done from a signed-in session"})
+			return
+		}
+
+		c.Next()
+	}
+}
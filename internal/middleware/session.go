@@ -1,27 +1,51 @@
 package middleware
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thecoretg/ticketbot/internal/apikey"
 	"github.com/thecoretg/ticketbot/internal/repos"
 	"github.com/thecoretg/ticketbot/internal/service/authsvc"
 	"github.com/thecoretg/ticketbot/models"
-	"golang.org/x/crypto/bcrypt"
 )
 
 const sessionCookie = "tb_session"
 
-// CombinedAuth accepts either a Bearer API key or a valid session cookie.
+// lastUsedInterval limits how often a key's last-used time and IP are written, so a busy client
+// doesn't cause a write on every request.
+const lastUsedInterval = time.Minute
+
+// CombinedAuth accepts either a Bearer API key or a valid session cookie. It sets the user's ID
+// and effective role on the context: the user's own role for a session, or the lower of the key's
+// and its owner's roles for an API key, so keys lose access when their owner is demoted.
+// The full models.Actor, including a key's scopes, is set as "actor". Expired keys are rejected.
+// Sessions whose user still has to change their password or set up TOTP can only reach the
+// routes for doing so, and keys stop working while their owner is missing required TOTP.
 func CombinedAuth(keys repos.APIKeyRepository, auth *authsvc.Service) gin.HandlerFunc {
+	verifier := apikey.NewVerifier(keys)
+	uses := newKeyUseRecorder(keys)
 	return func(c *gin.Context) {
 		// Try session cookie first
 		if token, err := c.Cookie(sessionCookie); err == nil && token != "" {
-			userID, err := auth.ValidateToken(c.Request.Context(), token)
+			session, err := auth.ValidateToken(c.Request.Context(), token, sessionClient(c))
 			if err == nil {
-				c.Set("user_id", userID)
+				role, restriction, err := auth.SessionAccess(c.Request.Context(), session)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+					return
+				}
+
+				if !restrictionAllows(c, restriction) {
+					abortRestricted(c, restriction)
+					return
+				}
+
+				setActor(c, &models.Actor{UserID: session.UserID, Role: role, SessionID: &session.ID, Restriction: restriction})
 				c.Next()
 				return
 			}
@@ -32,19 +56,34 @@ func CombinedAuth(keys repos.APIKeyRepository, auth *authsvc.Service) gin.Handle
 		if strings.HasPrefix(authHeader, "Bearer ") {
 			key := strings.TrimPrefix(authHeader, "Bearer ")
 			if key != "" {
-				allKeys, err := keys.List(c.Request.Context())
-				if err != nil {
+				k, err := verifier.Verify(c.Request.Context(), key)
+				if err != nil && !errors.Is(err, apikey.ErrInvalidKey) {
 					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 					return
 				}
 
-				for _, k := range allKeys {
-					if bcrypt.CompareHashAndPassword(k.KeyHash, []byte(key)) == nil {
-						slog.Info("authenticated via api key", "user_id", k.UserID)
-						c.Set("user_id", k.UserID)
-						c.Next()
+				if err == nil {
+					if k.Expired(time.Now()) {
+						c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "api key expired"})
+						return
+					}
+
+					ownerRole, err := auth.KeyOwnerRole(c.Request.Context(), k.UserID)
+					if errors.Is(err, authsvc.ErrOwnerTOTPRequired) {
+						c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
 						return
 					}
+					if err != nil {
+						c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+						return
+					}
+
+					role := models.LowerRole(k.Role, ownerRole)
+					slog.Info("authenticated via api key", "user_id", k.UserID, "key_id", k.ID, "role", role)
+					recordKeyUse(c, uses, k)
+					setActor(c, &models.Actor{UserID: k.UserID, Role: role, APIKeyID: &k.ID, Scopes: k.Scopes})
+					c.Next()
+					return
 				}
 			}
 		}
@@ -53,6 +92,24 @@ func CombinedAuth(keys repos.APIKeyRepository, auth *authsvc.Service) gin.Handle
 	}
 }
 
+func setActor(c *gin.Context, a *models.Actor) {
+	c.Set("user_id", a.UserID)
+	c.Set("role", string(a.Role))
+	c.Set("actor", a)
+}
+
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+func recordKeyUse(c *gin.Context, uses *keyUseRecorder, k *models.APIKey) {
+	if k.LastUsedOn != nil && time.Since(*k.LastUsedOn) < lastUsedInterval {
+		return
+	}
+
+	uses.record(k.ID, c.ClientIP())
+}
+
 // SessionAuth accepts only a valid session cookie (used for panel-only routes if needed).
 func SessionAuth(auth *authsvc.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -62,7 +119,7 @@ func SessionAuth(auth *authsvc.Service) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := auth.ValidateToken(c.Request.Context(), token)
+		session, err := auth.ValidateToken(c.Request.Context(), token, sessionClient(c))
 		if err != nil {
 			if isNotFound(err) {
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session expired or invalid"})
@@ -72,7 +129,7 @@ func SessionAuth(auth *authsvc.Service) gin.HandlerFunc {
 			return
 		}
 
-		c.Set("user_id", userID)
+		c.Set("user_id", session.UserID)
 		c.Next()
 	}
 }
@@ -0,0 +1,284 @@
+// Package oidc is a minimal OpenID Connect relying party for the panel's single sign-on: provider
+// discovery, the authorization code flow with PKCE, and ID token verification against the
+// provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrInvalidToken    = errors.New("invalid id token")
+	ErrEmailMissing    = errors.New("id token has no email")
+	ErrEmailUnverified = errors.New("email address is not verified by the identity provider")
+)
+
+// Config is how to reach the provider and which claims to read. Everything but the client
+// secret is required when IssuerURL is set; public clients rely on PKCE alone.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	EmailClaim   string
+	GroupsClaim  string
+	// TrustEmail accepts the email claim without email_verified being true, for providers that
+	// only issue addresses they own but don't send the claim. Leave it off for any provider that
+	// lets users set their own email.
+	TrustEmail bool
+}
+
+func (c Config) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// Identity is who the provider says signed in. Issuer and Subject together identify the account
+// for good; the email can change or be reused.
+type Identity struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Groups  []string
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to one OIDC issuer. Discovery happens on first use and is retried until it
+// succeeds, so a provider that's down at startup doesn't stop the server.
+type Provider struct {
+	cfg  Config
+	http *http.Client
+	// secret is the client secret, which can be swapped when it's rotated
+	secret atomic.Pointer[string]
+
+	mu   sync.Mutex
+	meta *metadata
+	keys *keySet
+}
+
+func New(cfg Config) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	p := &Provider{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 15 * time.Second},
+	}
+	p.secret.Store(&cfg.ClientSecret)
+	return p
+}
+
+// SetClientSecret swaps the client secret, such as after it's rotated. Sign-ins already waiting
+// on the provider finish with the secret they started with.
+func (p *Provider) SetClientSecret(secret string) {
+	p.secret.Store(&secret)
+}
+
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	u := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	m := &metadata{}
+	if err := p.getJSON(ctx, u, m); err != nil {
+		return nil, fmt.Errorf("discovering provider: %w", err)
+	}
+
+	if strings.TrimSuffix(m.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("provider reports issuer %q, expected %q", m.Issuer, p.cfg.IssuerURL)
+	}
+
+	if m.AuthorizationEndpoint == "" || m.TokenEndpoint == "" || m.JWKSURI == "" {
+		return nil, errors.New("provider metadata is missing required endpoints")
+	}
+
+	p.meta = m
+	p.keys = newKeySet(m.JWKSURI, p.getJSON)
+	return m, nil
+}
+
+// AuthCodeURL returns where to send the browser to sign in. verifier is the PKCE code verifier
+// that must be passed to Exchange with the resulting code.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(m.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return m.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange trades an authorization code for an ID token, verifies it, and returns the identity
+// in it. nonce must be the one passed to AuthCodeURL.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if secret := *p.secret.Load(); secret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(secret))
+	}
+
+	res, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting token: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading token response: %w", err)
+	}
+
+	tr := &tokenResponse{}
+	if err := json.Unmarshal(body, tr); err != nil {
+		return nil, fmt.Errorf("token endpoint returned %d: %w", res.StatusCode, err)
+	}
+
+	if res.StatusCode != http.StatusOK || tr.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", res.StatusCode, tr.Error, tr.ErrorDescription)
+	}
+
+	if tr.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidToken)
+	}
+
+	claims, err := p.verify(ctx, m, tr.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.identity(claims)
+}
+
+func (p *Provider) identity(claims map[string]any) (*Identity, error) {
+	id := &Identity{}
+	id.Issuer, _ = claims["iss"].(string)
+	id.Subject, _ = claims["sub"].(string)
+	if id.Issuer == "" || id.Subject == "" {
+		return nil, fmt.Errorf("%w: missing iss or sub", ErrInvalidToken)
+	}
+
+	email, _ := claims[p.cfg.EmailClaim].(string)
+	id.Email = strings.TrimSpace(email)
+	if id.Email == "" {
+		return nil, ErrEmailMissing
+	}
+
+	// the email decides which existing user a first sign-in is linked to, so it has to be one the
+	// provider checked, whichever claim it comes from. Some providers send the claim as a string.
+	verified := claims["email_verified"] == true || claims["email_verified"] == "true"
+	if !verified && !p.cfg.TrustEmail {
+		return nil, ErrEmailUnverified
+	}
+
+	switch g := claims[p.cfg.GroupsClaim].(type) {
+	case []any:
+		for _, v := range g {
+			if s, ok := v.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	case string:
+		id.Groups = []string{g}
+	}
+
+	return id, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", u, res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(target)
+}
+
+// RandomString returns a random URL-safe string, for state, nonce and PKCE verifier values.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -9,14 +9,21 @@ func AllRepos(pool *pgxpool.Pool) *repos.AllRepos {
 	return &repos.AllRepos{
 		APIKey:              NewAPIKeyRepo(pool),
 		APIUser:             NewAPIUserRepo(pool),
+		Audit:               NewAuditRepo(pool),
 		Config:              NewConfigRepo(pool),
+		ConfigRevisions:     NewConfigRevisionRepo(pool),
+		HookHealth:          NewHookHealthRepo(pool),
 		Logs:                NewLogRepo(pool),
+		LoginThrottle:       NewLoginThrottleRepo(pool),
 		Sessions:            NewSessionRepo(pool),
 		TOTPPending:         NewTOTPPendingRepo(pool),
 		TOTPRecovery:        NewTOTPRecoveryRepo(pool),
 		TicketNotifications: NewNotificationRepo(pool),
 		NotifierForwards:    NewUserForwardRepo(pool),
 		NotifierRules:       NewNotifierRuleRepo(pool),
+		Search:              NewSearchRepo(pool),
+		SyncJobs:            NewSyncJobRepo(pool),
+		SyncState:           NewSyncStateRepo(pool),
 		WebexRecipients:     NewWebexRecipientRepo(pool),
 		CW: repos.CWRepos{
 			Board:        NewBoardRepo(pool),
@@ -3,12 +3,13 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/thecoretg/ticketbot/internal/db"
-	"github.com/thecoretg/ticketbot/models"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type APIKeyRepo struct {
@@ -38,6 +39,21 @@ func (p *APIKeyRepo) List(ctx context.Context) ([]*models.APIKey, error) {
 	return k, nil
 }
 
+// ListLegacy returns keys that don't have a lookup ID yet.
+func (p *APIKeyRepo) ListLegacy(ctx context.Context) ([]*models.APIKey, error) {
+	dk, err := p.queries.ListLegacyAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var k []*models.APIKey
+	for _, d := range dk {
+		k = append(k, keyFromPG(d))
+	}
+
+	return k, nil
+}
+
 func (p *APIKeyRepo) Get(ctx context.Context, id int) (*models.APIKey, error) {
 	d, err := p.queries.GetAPIKey(ctx, id)
 	if err != nil {
@@ -50,6 +66,18 @@ func (p *APIKeyRepo) Get(ctx context.Context, id int) (*models.APIKey, error) {
 	return keyFromPG(d), nil
 }
 
+func (p *APIKeyRepo) GetByLookupID(ctx context.Context, lookupID string) (*models.APIKey, error) {
+	d, err := p.queries.GetAPIKeyByLookupID(ctx, &lookupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return keyFromPG(d), nil
+}
+
 func (p *APIKeyRepo) Insert(ctx context.Context, a *models.APIKey) (*models.APIKey, error) {
 	d, err := p.queries.InsertAPIKey(ctx, insertParamsFromAPIKey(a))
 	if err != nil {
@@ -59,6 +87,35 @@ func (p *APIKeyRepo) Insert(ctx context.Context, a *models.APIKey) (*models.APIK
 	return keyFromPG(d), nil
 }
 
+func (p *APIKeyRepo) SetExpiry(ctx context.Context, id int, expiresOn *time.Time) (*models.APIKey, error) {
+	d, err := p.queries.SetAPIKeyExpiry(ctx, db.SetAPIKeyExpiryParams{
+		ID:        id,
+		ExpiresOn: expiresOn,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return keyFromPG(d), nil
+}
+
+func (p *APIKeyRepo) SetLastUsed(ctx context.Context, id int, ip string) error {
+	return p.queries.SetAPIKeyLastUsed(ctx, db.SetAPIKeyLastUsedParams{
+		ID:         id,
+		LastUsedIp: &ip,
+	})
+}
+
+func (p *APIKeyRepo) SetLookupID(ctx context.Context, id int, lookupID string) error {
+	return p.queries.SetAPIKeyLookupID(ctx, db.SetAPIKeyLookupIDParams{
+		ID:       id,
+		LookupID: &lookupID,
+	})
+}
+
 func (p *APIKeyRepo) Delete(ctx context.Context, id int) error {
 	if err := p.queries.DeleteAPIKey(ctx, id); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -72,9 +129,14 @@ func (p *APIKeyRepo) Delete(ctx context.Context, id int) error {
 
 func insertParamsFromAPIKey(a *models.APIKey) db.InsertAPIKeyParams {
 	return db.InsertAPIKeyParams{
-		UserID:  a.UserID,
-		KeyHash: a.KeyHash,
-		KeyHint: a.KeyHint,
+		UserID:    a.UserID,
+		KeyHash:   a.KeyHash,
+		KeyHint:   a.KeyHint,
+		Role:      string(a.Role),
+		Label:     a.Label,
+		Scopes:    a.Scopes,
+		ExpiresOn: a.ExpiresOn,
+		LookupID:  a.LookupID,
 	}
 }
 
@@ -84,7 +146,15 @@ func keyFromPG(pg *db.ApiKey) *models.APIKey {
 		UserID:    pg.UserID,
 		KeyHash:   pg.KeyHash,
 		KeyHint:   pg.KeyHint,
+		Role:      models.Role(pg.Role),
+		Label:     pg.Label,
+		Scopes:    pg.Scopes,
 		CreatedOn: pg.CreatedOn,
 		UpdatedOn: pg.UpdatedOn,
+
+		ExpiresOn:  pg.ExpiresOn,
+		LastUsedOn: pg.LastUsedOn,
+		LastUsedIP: pg.LastUsedIp,
+		LookupID:   pg.LookupID,
 	}
 }
@@ -66,9 +66,49 @@ func (p *APIUserRepo) Exists(ctx context.Context, email string) (bool, error) {
 	return p.queries.CheckUserExists(ctx, email)
 }
 
-func (p *APIUserRepo) Insert(ctx context.Context, email string) (*models.APIUser, error) {
-	d, err := p.queries.InsertUser(ctx, email)
+// GetBySSOIdentity returns the user linked to the identity provider account issuer and subject.
+func (p *APIUserRepo) GetBySSOIdentity(ctx context.Context, issuer, subject string) (*models.APIUser, error) {
+	d, err := p.queries.GetUserBySSOIdentity(ctx, db.GetUserBySSOIdentityParams{Issuer: issuer, Subject: subject})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrAPIUserNotFound
+		}
+		return nil, err
+	}
+
+	return userFromPG(d), nil
+}
+
+// HasSSOIdentity reports whether the user is already linked to an account at issuer.
+func (p *APIUserRepo) HasSSOIdentity(ctx context.Context, id int, issuer string) (bool, error) {
+	return p.queries.CheckUserSSOIdentityExists(ctx, db.CheckUserSSOIdentityExistsParams{UserID: id, Issuer: issuer})
+}
+
+func (p *APIUserRepo) LinkSSOIdentity(ctx context.Context, id int, issuer, subject string) error {
+	return p.queries.InsertUserSSOIdentity(ctx, db.InsertUserSSOIdentityParams{Issuer: issuer, Subject: subject, UserID: id})
+}
+
+func (p *APIUserRepo) Insert(ctx context.Context, email string, role models.Role) (*models.APIUser, error) {
+	d, err := p.queries.InsertUser(ctx, db.InsertUserParams{
+		EmailAddress: email,
+		Role:         string(role),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userFromPG(d), nil
+}
+
+func (p *APIUserRepo) SetRole(ctx context.Context, id int, role models.Role) (*models.APIUser, error) {
+	d, err := p.queries.SetUserRole(ctx, db.SetUserRoleParams{
+		ID:   id,
+		Role: string(role),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrAPIUserNotFound
+		}
 		return nil, err
 	}
 
@@ -96,7 +136,7 @@ func (p *APIUserRepo) GetForAuth(ctx context.Context, email string) (*models.Use
 		return nil, err
 	}
 
-	return userAuthFromPG(d.ID, d.EmailAddress, d.PasswordHash, d.PasswordResetRequired, d.TotpSecret, d.TotpEnabled), nil
+	return userAuthFromPG(d.ID, d.EmailAddress, d.PasswordHash, d.PasswordResetRequired, d.TotpSecret, d.TotpEnabled, models.Role(d.Role)), nil
 }
 
 func (p *APIUserRepo) GetForAuthByID(ctx context.Context, id int) (*models.UserAuth, error) {
@@ -108,7 +148,7 @@ func (p *APIUserRepo) GetForAuthByID(ctx context.Context, id int) (*models.UserA
 		return nil, err
 	}
 
-	return userAuthFromPG(d.ID, d.EmailAddress, d.PasswordHash, d.PasswordResetRequired, d.TotpSecret, d.TotpEnabled), nil
+	return userAuthFromPG(d.ID, d.EmailAddress, d.PasswordHash, d.PasswordResetRequired, d.TotpSecret, d.TotpEnabled, models.Role(d.Role)), nil
 }
 
 func (p *APIUserRepo) SetPassword(ctx context.Context, id int, hash []byte) error {
@@ -122,6 +162,36 @@ func (p *APIUserRepo) SetTOTPSecret(ctx context.Context, id int, secret *string)
 	return p.queries.SetTOTPSecret(ctx, db.SetTOTPSecretParams{ID: id, TotpSecret: secret})
 }
 
+func (p *APIUserRepo) ListTOTPSecrets(ctx context.Context) (map[int]string, error) {
+	dm, err := p.queries.ListTOTPSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[int]string, len(dm))
+	for _, d := range dm {
+		if d.TotpSecret != nil {
+			m[d.ID] = *d.TotpSecret
+		}
+	}
+
+	return m, nil
+}
+
+// ReplaceTOTPSecret sets the user's secret only if it's still old, and reports whether it was.
+func (p *APIUserRepo) ReplaceTOTPSecret(ctx context.Context, id int, old, secret string) (bool, error) {
+	n, err := p.queries.ReplaceTOTPSecret(ctx, db.ReplaceTOTPSecretParams{
+		NewSecret: &secret,
+		ID:        id,
+		OldSecret: &old,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
 func (p *APIUserRepo) SetTOTPEnabled(ctx context.Context, id int, enabled bool) error {
 	return p.queries.SetTOTPEnabled(ctx, db.SetTOTPEnabledParams{ID: id, TotpEnabled: enabled})
 }
@@ -155,12 +225,13 @@ func userFromPG(pg *db.ApiUser) *models.APIUser {
 	return &models.APIUser{
 		ID:           pg.ID,
 		EmailAddress: pg.EmailAddress,
+		Role:         models.Role(pg.Role),
 		CreatedOn:    pg.CreatedOn,
 		UpdatedOn:    pg.UpdatedOn,
 	}
 }
 
-func userAuthFromPG(id int, email string, passwordHash []byte, resetRequired bool, totpSecret *string, totpEnabled bool) *models.UserAuth {
+func userAuthFromPG(id int, email string, passwordHash []byte, resetRequired bool, totpSecret *string, totpEnabled bool, role models.Role) *models.UserAuth {
 	return &models.UserAuth{
 		ID:            id,
 		EmailAddress:  email,
@@ -168,5 +239,6 @@ func userAuthFromPG(id int, email string, passwordHash []byte, resetRequired boo
 		ResetRequired: resetRequired,
 		TOTPSecret:    totpSecret,
 		TOTPEnabled:   totpEnabled,
+		Role:          role,
 	}
 }
@@ -7,8 +7,8 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/thecoretg/ticketbot/internal/db"
-	"github.com/thecoretg/ticketbot/models"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type ConfigRepo struct {
@@ -39,6 +39,18 @@ func (p *ConfigRepo) Get(ctx context.Context) (*models.Config, error) {
 	return configFromPG(d), nil
 }
 
+func (p *ConfigRepo) GetForUpdate(ctx context.Context) (*models.Config, error) {
+	d, err := p.queries.GetAppConfigForUpdate(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrConfigNotFound
+		}
+		return nil, err
+	}
+
+	return configFromPG(d), nil
+}
+
 func (p *ConfigRepo) InsertDefault(ctx context.Context) (*models.Config, error) {
 	d, err := p.queries.InsertDefaultAppConfig(ctx)
 	if err != nil {
@@ -57,6 +69,10 @@ func (p *ConfigRepo) Upsert(ctx context.Context, c *models.Config) (*models.Conf
 	return configFromPG(d), nil
 }
 
+func (p *ConfigRepo) NotifyChanged(ctx context.Context, origin string) error {
+	return p.queries.NotifyAppConfigChanged(ctx, origin)
+}
+
 func configToUpsertParams(c *models.Config) db.UpsertAppConfigParams {
 	return db.UpsertAppConfigParams{
 		AttemptNotify:           c.AttemptNotify,
@@ -67,6 +83,27 @@ func configToUpsertParams(c *models.Config) db.UpsertAppConfigParams {
 		LogRetentionDays:        c.LogRetentionDays,
 		LogCleanupIntervalHours: c.LogCleanupIntervalHours,
 		LogBufferSize:           c.LogBufferSize,
+
+		SyncBoardsIntervalMinutes:        c.SyncBoardsIntervalMinutes,
+		SyncRecipientsIntervalMinutes:    c.SyncRecipientsIntervalMinutes,
+		SyncOpenTicketsIntervalMinutes:   c.SyncOpenTicketsIntervalMinutes,
+		SyncTicketUpdatesIntervalMinutes: c.SyncTicketUpdatesIntervalMinutes,
+		HookCheckIntervalMinutes:         c.HookCheckIntervalMinutes,
+		HookSilenceMinutes:               c.HookSilenceMinutes,
+		HookAlertRecipientID:             c.HookAlertRecipientID,
+		BusinessHoursStart:               c.BusinessHoursStart,
+		BusinessHoursEnd:                 c.BusinessHoursEnd,
+		BusinessTimezone:                 c.BusinessTimezone,
+		CwRequestsPerMinute:              c.CWRequestsPerMinute,
+		CwMaxConcurrentRequests:          c.CWMaxConcurrentRequests,
+		AuditRetentionDays:               c.AuditRetentionDays,
+		SessionIdleTimeoutHours:          c.SessionIdleTimeoutHours,
+		SessionMaxLifetimeHours:          c.SessionMaxLifetimeHours,
+		LoginMaxFailures:                 c.LoginMaxFailures,
+		LoginIpMaxFailures:               c.LoginIPMaxFailures,
+		LoginLockoutMinutes:              c.LoginLockoutMinutes,
+		RequireTotpForApiKeys:            c.RequireTOTPForAPIKeys,
+		SsoSatisfiesTotp:                 c.SSOSatisfiesTOTP,
 	}
 }
 
@@ -81,5 +118,26 @@ func configFromPG(pg *db.AppConfig) *models.Config {
 		LogRetentionDays:        pg.LogRetentionDays,
 		LogCleanupIntervalHours: pg.LogCleanupIntervalHours,
 		LogBufferSize:           pg.LogBufferSize,
+
+		SyncBoardsIntervalMinutes:        pg.SyncBoardsIntervalMinutes,
+		SyncRecipientsIntervalMinutes:    pg.SyncRecipientsIntervalMinutes,
+		SyncOpenTicketsIntervalMinutes:   pg.SyncOpenTicketsIntervalMinutes,
+		SyncTicketUpdatesIntervalMinutes: pg.SyncTicketUpdatesIntervalMinutes,
+		HookCheckIntervalMinutes:         pg.HookCheckIntervalMinutes,
+		HookSilenceMinutes:               pg.HookSilenceMinutes,
+		HookAlertRecipientID:             pg.HookAlertRecipientID,
+		BusinessHoursStart:               pg.BusinessHoursStart,
+		BusinessHoursEnd:                 pg.BusinessHoursEnd,
+		BusinessTimezone:                 pg.BusinessTimezone,
+		CWRequestsPerMinute:              pg.CwRequestsPerMinute,
+		CWMaxConcurrentRequests:          pg.CwMaxConcurrentRequests,
+		AuditRetentionDays:               pg.AuditRetentionDays,
+		SessionIdleTimeoutHours:          pg.SessionIdleTimeoutHours,
+		SessionMaxLifetimeHours:          pg.SessionMaxLifetimeHours,
+		LoginMaxFailures:                 pg.LoginMaxFailures,
+		LoginIPMaxFailures:               pg.LoginIpMaxFailures,
+		LoginLockoutMinutes:              pg.LoginLockoutMinutes,
+		RequireTOTPForAPIKeys:            pg.RequireTotpForApiKeys,
+		SSOSatisfiesTOTP:                 pg.SsoSatisfiesTotp,
 	}
 }
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type AuditRepo struct {
+	queries *db.Queries
+}
+
+func NewAuditRepo(pool *pgxpool.Pool) *AuditRepo {
+	return &AuditRepo{queries: db.New(pool)}
+}
+
+func (r *AuditRepo) List(ctx context.Context, f *models.AuditFilter) ([]*models.AuditEntry, error) {
+	dm, err := r.queries.ListAuditEntries(ctx, db.ListAuditEntriesParams{
+		ActorUserID: f.ActorUserID,
+		ActorKeyID:  f.ActorKeyID,
+		Action:      f.Action,
+		TargetType:  f.TargetType,
+		TargetID:    f.TargetID,
+		Since:       f.Since,
+		Until:       f.Until,
+		Cursor:      f.Cursor,
+		PageLimit:   f.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*models.AuditEntry
+	for _, d := range dm {
+		entries = append(entries, auditEntryFromPG(d))
+	}
+
+	return entries, nil
+}
+
+func (r *AuditRepo) Insert(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error) {
+	d, err := r.queries.InsertAuditEntry(ctx, db.InsertAuditEntryParams{
+		ActorUserID: e.ActorUserID,
+		ActorKeyID:  e.ActorKeyID,
+		ActorRole:   string(e.ActorRole),
+		Action:      string(e.Action),
+		TargetType:  e.TargetType,
+		TargetID:    e.TargetID,
+		Before:      e.Before,
+		After:       e.After,
+		Method:      e.Method,
+		Path:        e.Path,
+		RemoteIp:    e.RemoteIP,
+		UserAgent:   e.UserAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return auditEntryFromPG(d), nil
+}
+
+func (r *AuditRepo) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.DeleteAuditEntriesBefore(ctx, before)
+}
+
+func auditEntryFromPG(d *db.AuditLog) *models.AuditEntry {
+	return &models.AuditEntry{
+		ID:          d.ID,
+		ActorUserID: d.ActorUserID,
+		ActorKeyID:  d.ActorKeyID,
+		ActorRole:   models.Role(d.ActorRole),
+		Action:      models.AuditAction(d.Action),
+		TargetType:  d.TargetType,
+		TargetID:    d.TargetID,
+		Before:      d.Before,
+		After:       d.After,
+		Method:      d.Method,
+		Path:        d.Path,
+		RemoteIP:    d.RemoteIp,
+		UserAgent:   d.UserAgent,
+		CreatedOn:   d.CreatedOn,
+	}
+}
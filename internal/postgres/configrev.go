@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type ConfigRevisionRepo struct {
+	queries *db.Queries
+}
+
+func NewConfigRevisionRepo(pool *pgxpool.Pool) *ConfigRevisionRepo {
+	return &ConfigRevisionRepo{queries: db.New(pool)}
+}
+
+func (p *ConfigRevisionRepo) WithTx(tx pgx.Tx) repos.ConfigRevisionRepository {
+	return &ConfigRevisionRepo{queries: db.New(tx)}
+}
+
+func (p *ConfigRevisionRepo) Get(ctx context.Context, revision int) (*models.ConfigRevision, error) {
+	d, err := p.queries.GetConfigRevision(ctx, revision)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrConfigRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return configRevisionFromPG(d), nil
+}
+
+func (p *ConfigRevisionRepo) Latest(ctx context.Context) (*models.ConfigRevision, error) {
+	d, err := p.queries.GetLatestConfigRevision(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrConfigRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return configRevisionFromPG(d), nil
+}
+
+func (p *ConfigRevisionRepo) List(ctx context.Context, cursor *int, limit int) ([]*models.ConfigRevision, error) {
+	dm, err := p.queries.ListConfigRevisions(ctx, db.ListConfigRevisionsParams{Cursor: cursor, PageLimit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	var revs []*models.ConfigRevision
+	for _, d := range dm {
+		revs = append(revs, configRevisionFromPG(d))
+	}
+
+	return revs, nil
+}
+
+func (p *ConfigRevisionRepo) Insert(ctx context.Context, r *models.ConfigRevision) (*models.ConfigRevision, error) {
+	d, err := p.queries.InsertConfigRevision(ctx, db.InsertConfigRevisionParams{
+		Config:       r.Config,
+		AuthorUserID: r.AuthorUserID,
+		AuthorKeyID:  r.AuthorKeyID,
+		RollbackOf:   r.RollbackOf,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return configRevisionFromPG(d), nil
+}
+
+func configRevisionFromPG(d *db.ConfigRevision) *models.ConfigRevision {
+	return &models.ConfigRevision{
+		Revision:     d.ID,
+		Config:       d.Config,
+		AuthorUserID: d.AuthorUserID,
+		AuthorKeyID:  d.AuthorKeyID,
+		RollbackOf:   d.RollbackOf,
+		CreatedOn:    d.CreatedOn,
+	}
+}
@@ -41,6 +41,20 @@ func (p *TicketRepo) List(ctx context.Context) ([]*models.Ticket, error) {
 	return b, nil
 }
 
+func (p *TicketRepo) Search(ctx context.Context, f *models.TicketFilter) ([]*models.Ticket, error) {
+	dm, err := p.queries.SearchTickets(ctx, ticketFilterToSearchParams(f))
+	if err != nil {
+		return nil, err
+	}
+
+	var b []*models.Ticket
+	for _, d := range dm {
+		b = append(b, ticketFromPG(d))
+	}
+
+	return b, nil
+}
+
 func (p *TicketRepo) Get(ctx context.Context, id int) (*models.Ticket, error) {
 	d, err := p.queries.GetTicket(ctx, id)
 	if err != nil {
@@ -95,6 +109,21 @@ func ticketToUpsertParams(t *models.Ticket) db.UpsertTicketParams {
 	}
 }
 
+func ticketFilterToSearchParams(f *models.TicketFilter) db.SearchTicketsParams {
+	return db.SearchTicketsParams{
+		BoardID:      f.BoardID,
+		StatusID:     f.StatusID,
+		Closed:       f.Closed,
+		CompanyID:    f.CompanyID,
+		OwnerID:      f.OwnerID,
+		ResourceID:   f.ResourceID,
+		UpdatedSince: f.UpdatedSince,
+		Search:       f.Search,
+		Cursor:       f.Cursor,
+		PageLimit:    f.Limit,
+	}
+}
+
 func ticketFromPG(pg *db.CwTicket) *models.Ticket {
 	return &models.Ticket{
 		ID:        pg.ID,
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+)
+
+type HookHealthRepo struct {
+	queries *db.Queries
+}
+
+func NewHookHealthRepo(pool *pgxpool.Pool) *HookHealthRepo {
+	return &HookHealthRepo{queries: db.New(pool)}
+}
+
+// LastReceived returns when a Connectwise callback was last received, or nil if one never has been.
+func (r *HookHealthRepo) LastReceived(ctx context.Context) (*time.Time, error) {
+	t, err := r.queries.GetHookLastReceived(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// SetLastReceived records when a callback was last received. An earlier time than the one
+// stored is ignored.
+func (r *HookHealthRepo) SetLastReceived(ctx context.Context, t time.Time) error {
+	return r.queries.SetHookLastReceived(ctx, &t)
+}
+
+// ClaimSilenceAlert records that this instance is sending the silence alert at sentOn, and
+// reports whether it may. It may not if an alert was already sent since the last callback was
+// received, or since dayStart.
+func (r *HookHealthRepo) ClaimSilenceAlert(ctx context.Context, sentOn, dayStart time.Time) (bool, error) {
+	n, err := r.queries.ClaimHookSilenceAlert(ctx, db.ClaimHookSilenceAlertParams{
+		SentOn:   &sentOn,
+		DayStart: dayStart,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// ReleaseSilenceAlert gives up a claim made at sentOn, so the alert can be retried.
+func (r *HookHealthRepo) ReleaseSilenceAlert(ctx context.Context, sentOn time.Time) error {
+	return r.queries.ReleaseHookSilenceAlert(ctx, &sentOn)
+}
@@ -3,10 +3,16 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/thecoretg/ticketbot/internal/logging"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type LogRepo struct {
@@ -77,6 +83,139 @@ func (r *LogRepo) GetRecent(ctx context.Context, limit int) ([]logging.LogEntry,
 	return entries, rows.Err()
 }
 
+func (r *LogRepo) Query(ctx context.Context, f *models.LogFilter) ([]logging.LogEntry, error) {
+	where, args := logWhere(f, true)
+	args = append(args, f.Limit)
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, time, level, message, attrs
+		FROM app_log`+where+`
+		ORDER BY id DESC
+		LIMIT $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []logging.LogEntry
+	for rows.Next() {
+		e, err := scanLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *LogRepo) Export(ctx context.Context, f *models.LogFilter, fn func(logging.LogEntry) error) error {
+	where, args := logWhere(f, false)
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, time, level, message, attrs
+		FROM app_log`+where+`
+		ORDER BY id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanLogEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func scanLogEntry(rows pgx.Rows) (logging.LogEntry, error) {
+	var e logging.LogEntry
+	var attrsJSON []byte
+	if err := rows.Scan(&e.ID, &e.Time, &e.Level, &e.Message, &attrsJSON); err != nil {
+		return e, err
+	}
+	if attrsJSON != nil {
+		_ = json.Unmarshal(attrsJSON, &e.Attrs)
+	}
+	return e, nil
+}
+
+// logWhere builds the WHERE clause and its args for f. The message search and attribute filters
+// are written to match the indexes in migration 00019.
+func logWhere(f *models.LogFilter, paged bool) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(f.Levels) > 0 {
+		conds = append(conds, "level = ANY("+arg(f.Levels)+"::text[])")
+	}
+	if f.Since != nil {
+		conds = append(conds, "time >= "+arg(*f.Since))
+	}
+	if f.Until != nil {
+		conds = append(conds, "time < "+arg(*f.Until))
+	}
+	if f.Text != nil {
+		conds = append(conds, "to_tsvector('simple', message) @@ websearch_to_tsquery('simple', "+arg(*f.Text)+")")
+	}
+
+	keys := make([]string, 0, len(f.Attrs))
+	for k := range f.Attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		var alts []string
+		for _, doc := range attrDocs(k, f.Attrs[k]) {
+			alts = append(alts, "attrs @> "+arg(doc)+"::jsonb")
+		}
+		conds = append(conds, "("+strings.Join(alts, " OR ")+")")
+	}
+
+	if paged && f.Cursor != nil {
+		conds = append(conds, "id < "+arg(*f.Cursor))
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return "\n\t\tWHERE " + strings.Join(conds, "\n\t\t  AND "), args
+}
+
+// attrDocs returns the JSON documents an attrs column contains if the attribute at the dotted path
+// key equals value. Attributes keep the type they were logged with, so a value that parses as a
+// number or boolean is matched both as that and as a string.
+func attrDocs(key, value string) []string {
+	vals := []any{value}
+	if n, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+		vals = append(vals, n)
+	} else if value == "true" || value == "false" {
+		vals = append(vals, value == "true")
+	}
+
+	path := strings.Split(key, ".")
+	docs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		for i := len(path) - 1; i >= 0; i-- {
+			v = map[string]any{path[i]: v}
+		}
+		// strings, finite numbers and bools always marshal
+		b, _ := json.Marshal(v)
+		docs = append(docs, string(b))
+	}
+	return docs
+}
+
 func (r *LogRepo) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
 	tag, err := r.pool.Exec(ctx, `DELETE FROM app_log WHERE time < $1`, before)
 	if err != nil {
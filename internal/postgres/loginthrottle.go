@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type LoginThrottleRepo struct {
+	queries *db.Queries
+}
+
+func NewLoginThrottleRepo(pool *pgxpool.Pool) *LoginThrottleRepo {
+	return &LoginThrottleRepo{queries: db.New(pool)}
+}
+
+func (r *LoginThrottleRepo) Get(ctx context.Context, scope, subject string) (*models.LoginThrottle, error) {
+	d, err := r.queries.GetLoginThrottle(ctx, db.GetLoginThrottleParams{Scope: scope, Subject: subject})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrLoginThrottleNotFound
+		}
+		return nil, err
+	}
+
+	return loginThrottleFromPG(d), nil
+}
+
+func (r *LoginThrottleRepo) ListLocked(ctx context.Context) ([]*models.LoginThrottle, error) {
+	dm, err := r.queries.ListLockedLoginThrottles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var t []*models.LoginThrottle
+	for _, d := range dm {
+		t = append(t, loginThrottleFromPG(d))
+	}
+
+	return t, nil
+}
+
+func (r *LoginThrottleRepo) Attempt(ctx context.Context, scope, subject string, windowStart time.Time, maxFailures, freeFailures int, maxDelay time.Duration) (*models.LoginThrottle, error) {
+	d, err := r.queries.ClaimLoginAttempt(ctx, db.ClaimLoginAttemptParams{
+		Scope:           scope,
+		Subject:         subject,
+		WindowStart:     windowStart,
+		MaxFailures:     maxFailures,
+		FreeFailures:    freeFailures,
+		MaxDelaySeconds: maxDelay.Seconds(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrLoginAttemptRefused
+		}
+		return nil, err
+	}
+
+	return loginThrottleFromPG(d), nil
+}
+
+func (r *LoginThrottleRepo) Release(ctx context.Context, scope, subject string) error {
+	return r.queries.ReleaseLoginAttempt(ctx, db.ReleaseLoginAttemptParams{Scope: scope, Subject: subject})
+}
+
+func (r *LoginThrottleRepo) Lock(ctx context.Context, scope, subject string, base, maxLock time.Duration, escalateSince time.Time) (*models.LoginThrottle, error) {
+	d, err := r.queries.LockLoginThrottle(ctx, db.LockLoginThrottleParams{
+		EscalateSince: escalateSince,
+		BaseSeconds:   base.Seconds(),
+		MaxSeconds:    maxLock.Seconds(),
+		Scope:         scope,
+		Subject:       subject,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrLoginThrottleNotFound
+		}
+		return nil, err
+	}
+
+	return loginThrottleFromPG(d), nil
+}
+
+func (r *LoginThrottleRepo) Clear(ctx context.Context, scope, subject string) (bool, error) {
+	n, err := r.queries.DeleteLoginThrottle(ctx, db.DeleteLoginThrottleParams{Scope: scope, Subject: subject})
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (r *LoginThrottleRepo) DeleteStale(ctx context.Context, failedBefore, lockedBefore time.Time) error {
+	return r.queries.DeleteStaleLoginThrottles(ctx, db.DeleteStaleLoginThrottlesParams{
+		FailedBefore: failedBefore,
+		LockedBefore: lockedBefore,
+	})
+}
+
+func (r *LoginThrottleRepo) CreateLockout(ctx context.Context, l *models.LoginLockout) (*models.LoginLockout, error) {
+	d, err := r.queries.InsertLoginLockout(ctx, db.InsertLoginLockoutParams{
+		Scope:       l.Scope,
+		Subject:     l.Subject,
+		UserID:      l.UserID,
+		Ip:          l.IP,
+		Failures:    l.Failures,
+		LockedUntil: l.LockedUntil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loginLockoutFromPG(d), nil
+}
+
+func (r *LoginThrottleRepo) ListLockouts(ctx context.Context, limit int) ([]*models.LoginLockout, error) {
+	dm, err := r.queries.ListLoginLockouts(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var l []*models.LoginLockout
+	for _, d := range dm {
+		l = append(l, loginLockoutFromPG(d))
+	}
+
+	return l, nil
+}
+
+func (r *LoginThrottleRepo) DeleteLockoutsBefore(ctx context.Context, before time.Time) error {
+	return r.queries.DeleteLoginLockoutsBefore(ctx, before)
+}
+
+func loginThrottleFromPG(d *db.LoginThrottle) *models.LoginThrottle {
+	return &models.LoginThrottle{
+		Scope:        d.Scope,
+		Subject:      d.Subject,
+		Failures:     d.Failures,
+		LastFailedOn: d.LastFailedOn,
+		LockedUntil:  d.LockedUntil,
+		Lockouts:     d.Lockouts,
+	}
+}
+
+func loginLockoutFromPG(d *db.LoginLockout) *models.LoginLockout {
+	return &models.LoginLockout{
+		ID:          d.ID,
+		Scope:       d.Scope,
+		Subject:     d.Subject,
+		UserID:      d.UserID,
+		IP:          d.Ip,
+		Failures:    d.Failures,
+		LockedUntil: d.LockedUntil,
+		CreatedOn:   d.CreatedOn,
+	}
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// ts_headline marks matches with these sentinels rather than tags, so the text around them can
+// be escaped before the <mark> tags go in. The query strips them from the text first.
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+// headlineOpts controls the ts_headline snippets returned with each hit.
+const headlineOpts = "StartSel=" + markStart + ", StopSel=" + markStop + ", MaxWords=35, MinWords=15, MaxFragments=2"
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+
+type SearchRepo struct {
+	queries *db.Queries
+}
+
+func NewSearchRepo(pool *pgxpool.Pool) *SearchRepo {
+	return &SearchRepo{queries: db.New(pool)}
+}
+
+func (r *SearchRepo) Search(ctx context.Context, p *models.SearchParams) ([]*models.SearchHit, error) {
+	dm, err := r.queries.SearchTicketText(ctx, db.SearchTicketTextParams{
+		Query:        p.Query,
+		HeadlineOpts: headlineOpts,
+		BoardID:      p.BoardID,
+		CompanyID:    p.CompanyID,
+		Since:        p.Since,
+		Until:        p.Until,
+		PageLimit:    p.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []*models.SearchHit
+	for _, d := range dm {
+		hits = append(hits, searchHitFromPG(d))
+	}
+
+	return hits, nil
+}
+
+func searchHitFromPG(d *db.SearchTicketTextRow) *models.SearchHit {
+	return &models.SearchHit{
+		Kind:      models.SearchHitKind(d.Kind),
+		TicketID:  d.TicketID,
+		NoteID:    d.NoteID,
+		Summary:   d.Summary,
+		BoardID:   d.BoardID,
+		CompanyID: d.CompanyID,
+		Snippet:   highlight(d.Snippet),
+		Rank:      d.Rank,
+		UpdatedOn: d.UpdatedOn,
+	}
+}
+
+// highlight escapes a ts_headline snippet for HTML and swaps its sentinels for <mark> tags.
+func highlight(s string) string {
+	return markReplacer.Replace(html.EscapeString(s))
+}
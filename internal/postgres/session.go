@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -20,9 +21,13 @@ func NewSessionRepo(pool *pgxpool.Pool) *SessionRepo {
 
 func (r *SessionRepo) Create(ctx context.Context, s *models.Session) (*models.Session, error) {
 	d, err := r.queries.CreateSession(ctx, db.CreateSessionParams{
-		UserID:    s.UserID,
-		TokenHash: s.TokenHash,
-		ExpiresAt: s.ExpiresAt,
+		UserID:            s.UserID,
+		TokenHash:         s.TokenHash,
+		ExpiresAt:         s.ExpiresAt,
+		AbsoluteExpiresAt: s.AbsoluteExpiresAt,
+		Ip:                s.IP,
+		UserAgent:         s.UserAgent,
+		AuthMethod:        s.AuthMethod,
 	})
 	if err != nil {
 		return nil, err
@@ -43,20 +48,65 @@ func (r *SessionRepo) GetByTokenHash(ctx context.Context, tokenHash []byte) (*mo
 	return sessionFromPG(d), nil
 }
 
+func (r *SessionRepo) ListByUser(ctx context.Context, userID int) ([]*models.Session, error) {
+	dm, err := r.queries.ListSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var s []*models.Session
+	for _, d := range dm {
+		s = append(s, sessionFromPG(d))
+	}
+
+	return s, nil
+}
+
+func (r *SessionRepo) Touch(ctx context.Context, id int, expiresAt time.Time, client models.SessionClient) error {
+	return r.queries.TouchSession(ctx, db.TouchSessionParams{
+		ID:        id,
+		ExpiresAt: expiresAt,
+		Ip:        client.IP,
+		UserAgent: client.UserAgent,
+	})
+}
+
 func (r *SessionRepo) Delete(ctx context.Context, id int) error {
 	return r.queries.DeleteSession(ctx, id)
 }
 
+func (r *SessionRepo) DeleteForUser(ctx context.Context, id, userID int) error {
+	n, err := r.queries.DeleteUserSession(ctx, db.DeleteUserSessionParams{ID: id, UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return models.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) DeleteAllForUser(ctx context.Context, userID int, keepID *int) (int64, error) {
+	return r.queries.DeleteUserSessions(ctx, db.DeleteUserSessionsParams{UserID: userID, KeepID: keepID})
+}
+
 func (r *SessionRepo) DeleteExpired(ctx context.Context) error {
 	return r.queries.DeleteExpiredSessions(ctx)
 }
 
 func sessionFromPG(d *db.Session) *models.Session {
 	return &models.Session{
-		ID:        d.ID,
-		UserID:    d.UserID,
-		TokenHash: d.TokenHash,
-		ExpiresAt: d.ExpiresAt,
-		CreatedOn: d.CreatedOn,
+		ID:                d.ID,
+		UserID:            d.UserID,
+		TokenHash:         d.TokenHash,
+		ExpiresAt:         d.ExpiresAt,
+		AbsoluteExpiresAt: d.AbsoluteExpiresAt,
+		LastSeenOn:        d.LastSeenOn,
+		IP:                d.Ip,
+		UserAgent:         d.UserAgent,
+		AuthMethod:        d.AuthMethod,
+		CreatedOn:         d.CreatedOn,
 	}
 }
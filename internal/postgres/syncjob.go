@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type SyncJobRepo struct {
+	queries *db.Queries
+}
+
+func NewSyncJobRepo(pool *pgxpool.Pool) *SyncJobRepo {
+	return &SyncJobRepo{queries: db.New(pool)}
+}
+
+func (r *SyncJobRepo) List(ctx context.Context, f *models.SyncJobFilter) ([]*models.SyncJob, error) {
+	dm, err := r.queries.ListSyncJobs(ctx, db.ListSyncJobsParams{
+		Cursor:    f.Cursor,
+		PageLimit: f.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*models.SyncJob
+	for _, d := range dm {
+		j, err := syncJobFromPG(d)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+func (r *SyncJobRepo) Get(ctx context.Context, id int) (*models.SyncJob, error) {
+	d, err := r.queries.GetSyncJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrSyncJobNotFound
+		}
+		return nil, err
+	}
+
+	return syncJobFromPG(d)
+}
+
+func (r *SyncJobRepo) Create(ctx context.Context, j *models.SyncJob) (*models.SyncJob, error) {
+	payload, err := json.Marshal(j.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	phases, err := json.Marshal(j.Phases)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling phases: %w", err)
+	}
+
+	d, err := r.queries.CreateSyncJob(ctx, db.CreateSyncJobParams{
+		TriggeredBy: j.TriggeredBy,
+		Status:      string(j.Status),
+		Payload:     payload,
+		Phases:      phases,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return syncJobFromPG(d)
+}
+
+func (r *SyncJobRepo) Update(ctx context.Context, j *models.SyncJob) (*models.SyncJob, error) {
+	phases, err := json.Marshal(j.Phases)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling phases: %w", err)
+	}
+
+	d, err := r.queries.UpdateSyncJob(ctx, db.UpdateSyncJobParams{
+		ID:         j.ID,
+		Status:     string(j.Status),
+		Phases:     phases,
+		Error:      j.Error,
+		FinishedOn: j.FinishedOn,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrSyncJobNotFound
+		}
+		return nil, err
+	}
+
+	return syncJobFromPG(d)
+}
+
+func (r *SyncJobRepo) InterruptRunning(ctx context.Context) (int64, error) {
+	return r.queries.InterruptRunningSyncJobs(ctx)
+}
+
+func (r *SyncJobRepo) DeleteOlderThan(ctx context.Context, before time.Time) error {
+	return r.queries.DeleteSyncJobsBefore(ctx, before)
+}
+
+func syncJobFromPG(d *db.SyncJob) (*models.SyncJob, error) {
+	j := &models.SyncJob{
+		ID:          d.ID,
+		TriggeredBy: d.TriggeredBy,
+		Status:      models.SyncJobStatus(d.Status),
+		Error:       d.Error,
+		StartedOn:   d.StartedOn,
+		FinishedOn:  d.FinishedOn,
+	}
+
+	if err := json.Unmarshal(d.Payload, &j.Payload); err != nil {
+		return nil, fmt.Errorf("unmarshaling payload for sync job %d: %w", d.ID, err)
+	}
+
+	if err := json.Unmarshal(d.Phases, &j.Phases); err != nil {
+		return nil, fmt.Errorf("unmarshaling phases for sync job %d: %w", d.ID, err)
+	}
+
+	return j, nil
+}
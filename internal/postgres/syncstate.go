@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/db"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type SyncStateRepo struct {
+	queries *db.Queries
+}
+
+func NewSyncStateRepo(pool *pgxpool.Pool) *SyncStateRepo {
+	return &SyncStateRepo{queries: db.New(pool)}
+}
+
+func (r *SyncStateRepo) List(ctx context.Context) ([]*models.SyncState, error) {
+	dm, err := r.queries.ListSyncStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var s []*models.SyncState
+	for _, d := range dm {
+		s = append(s, syncStateFromPG(d))
+	}
+
+	return s, nil
+}
+
+func (r *SyncStateRepo) Get(ctx context.Context, t models.SyncType) (*models.SyncState, error) {
+	d, err := r.queries.GetSyncState(ctx, string(t))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrSyncStateNotFound
+		}
+		return nil, err
+	}
+
+	return syncStateFromPG(d), nil
+}
+
+func (r *SyncStateRepo) RecordRun(ctx context.Context, s *models.SyncState) (*models.SyncState, error) {
+	d, err := r.queries.RecordSyncRun(ctx, db.RecordSyncRunParams{
+		SyncType:      string(s.SyncType),
+		LastStarted:   s.LastStarted,
+		LastFinished:  s.LastFinished,
+		LastSucceeded: s.LastSucceeded,
+		LastError:     s.LastError,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return syncStateFromPG(d), nil
+}
+
+func (r *SyncStateRepo) SetWatermark(ctx context.Context, t models.SyncType, watermark time.Time) error {
+	return r.queries.SetSyncWatermark(ctx, db.SetSyncWatermarkParams{
+		SyncType:  string(t),
+		Watermark: &watermark,
+	})
+}
+
+func syncStateFromPG(d *db.SyncState) *models.SyncState {
+	return &models.SyncState{
+		SyncType:      models.SyncType(d.SyncType),
+		Watermark:     d.Watermark,
+		LastStarted:   d.LastStarted,
+		LastFinished:  d.LastFinished,
+		LastSucceeded: d.LastSucceeded,
+		LastError:     d.LastError,
+		UpdatedOn:     d.UpdatedOn,
+	}
+}
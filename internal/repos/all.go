@@ -3,14 +3,21 @@ package repos
 type AllRepos struct {
 	APIKey              APIKeyRepository
 	APIUser             APIUserRepository
+	Audit               AuditRepository
 	Config              ConfigRepository
+	ConfigRevisions     ConfigRevisionRepository
+	HookHealth          HookHealthRepository
 	Logs                LogRepository
+	LoginThrottle       LoginThrottleRepository
 	Sessions            SessionRepository
 	TOTPPending         TOTPPendingRepository
 	TOTPRecovery        TOTPRecoveryRepository
 	TicketNotifications TicketNotificationRepository
 	NotifierForwards    NotifierForwardRepository
 	NotifierRules       NotifierRuleRepository
+	Search              SearchRepository
+	SyncJobs            SyncJobRepository
+	SyncState           SyncStateRepository
 	WebexRecipients     WebexRecipientRepository
 	CW                  CWRepos
 }
@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/thecoretg/ticketbot/models"
@@ -10,8 +11,13 @@ import (
 type APIKeyRepository interface {
 	WithTx(tx pgx.Tx) APIKeyRepository
 	List(ctx context.Context) ([]*models.APIKey, error)
+	ListLegacy(ctx context.Context) ([]*models.APIKey, error)
 	Get(ctx context.Context, id int) (*models.APIKey, error)
+	GetByLookupID(ctx context.Context, lookupID string) (*models.APIKey, error)
 	Insert(ctx context.Context, a *models.APIKey) (*models.APIKey, error)
+	SetExpiry(ctx context.Context, id int, expiresOn *time.Time) (*models.APIKey, error)
+	SetLastUsed(ctx context.Context, id int, ip string) error
+	SetLookupID(ctx context.Context, id int, lookupID string) error
 	Delete(ctx context.Context, id int) error
 }
 
@@ -22,11 +28,17 @@ type APIUserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*models.APIUser, error)
 	GetForAuth(ctx context.Context, email string) (*models.UserAuth, error)
 	Exists(ctx context.Context, email string) (bool, error)
-	Insert(ctx context.Context, email string) (*models.APIUser, error)
+	GetBySSOIdentity(ctx context.Context, issuer, subject string) (*models.APIUser, error)
+	HasSSOIdentity(ctx context.Context, id int, issuer string) (bool, error)
+	LinkSSOIdentity(ctx context.Context, id int, issuer, subject string) error
+	Insert(ctx context.Context, email string, role models.Role) (*models.APIUser, error)
+	SetRole(ctx context.Context, id int, role models.Role) (*models.APIUser, error)
 	GetForAuthByID(ctx context.Context, id int) (*models.UserAuth, error)
 	SetPassword(ctx context.Context, id int, hash []byte) error
 	SetPasswordResetRequired(ctx context.Context, id int, required bool) error
 	SetTOTPSecret(ctx context.Context, id int, secret *string) error
+	ListTOTPSecrets(ctx context.Context) (map[int]string, error)
+	ReplaceTOTPSecret(ctx context.Context, id int, old, secret string) (bool, error)
 	SetTOTPEnabled(ctx context.Context, id int, enabled bool) error
 	Delete(ctx context.Context, id int) error
 }
@@ -10,6 +10,19 @@ import (
 type ConfigRepository interface {
 	WithTx(tx pgx.Tx) ConfigRepository
 	Get(ctx context.Context) (*models.Config, error)
+	// GetForUpdate is Get with the row locked until the transaction finishes.
+	GetForUpdate(ctx context.Context) (*models.Config, error)
 	InsertDefault(ctx context.Context) (*models.Config, error)
 	Upsert(ctx context.Context, c *models.Config) (*models.Config, error)
+	// NotifyChanged tells other instances listening for config changes to reload, once the
+	// transaction commits. origin identifies the instance that made the change.
+	NotifyChanged(ctx context.Context, origin string) error
+}
+
+type ConfigRevisionRepository interface {
+	WithTx(tx pgx.Tx) ConfigRevisionRepository
+	Get(ctx context.Context, revision int) (*models.ConfigRevision, error)
+	Latest(ctx context.Context) (*models.ConfigRevision, error)
+	List(ctx context.Context, cursor *int, limit int) ([]*models.ConfigRevision, error)
+	Insert(ctx context.Context, r *models.ConfigRevision) (*models.ConfigRevision, error)
 }
@@ -0,0 +1,14 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type AuditRepository interface {
+	List(ctx context.Context, f *models.AuditFilter) ([]*models.AuditEntry, error)
+	Insert(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error)
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,13 @@
+package repos
+
+import (
+	"context"
+	"time"
+)
+
+type HookHealthRepository interface {
+	LastReceived(ctx context.Context) (*time.Time, error)
+	SetLastReceived(ctx context.Context, t time.Time) error
+	ClaimSilenceAlert(ctx context.Context, sentOn, dayStart time.Time) (bool, error)
+	ReleaseSilenceAlert(ctx context.Context, sentOn time.Time) error
+}
@@ -5,10 +5,16 @@ import (
 	"time"
 
 	"github.com/thecoretg/ticketbot/internal/logging"
+	"github.com/thecoretg/ticketbot/models"
 )
 
 type LogRepository interface {
 	InsertBatch(ctx context.Context, entries []logging.LogEntry) error
 	GetRecent(ctx context.Context, limit int) ([]logging.LogEntry, error)
+	// Query returns entries matching f, newest first.
+	Query(ctx context.Context, f *models.LogFilter) ([]logging.LogEntry, error)
+	// Export calls fn with every entry matching f, oldest first, ignoring f's cursor and limit. It
+	// stops at the first error fn returns.
+	Export(ctx context.Context, f *models.LogFilter, fn func(logging.LogEntry) error) error
 	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
 }
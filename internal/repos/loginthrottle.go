@@ -0,0 +1,32 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, scope, subject string) (*models.LoginThrottle, error)
+	ListLocked(ctx context.Context) ([]*models.LoginThrottle, error)
+	// Attempt counts an attempt before it's checked, restarting the count if the last one was before
+	// windowStart. It returns models.ErrLoginAttemptRefused, without counting it, if the key is
+	// locked, already has maxFailures attempts, or the delay after the last one hasn't passed. The
+	// delay doubles from a second after freeFailures attempts, up to maxDelay.
+	Attempt(ctx context.Context, scope, subject string, windowStart time.Time, maxFailures, freeFailures int, maxDelay time.Duration) (*models.LoginThrottle, error)
+	// Release uncounts an attempt that didn't fail.
+	Release(ctx context.Context, scope, subject string) error
+	// Lock locks the key for base, doubled for each lockout that ended after escalateSince, up to
+	// maxLock, and starts the failure count over.
+	Lock(ctx context.Context, scope, subject string, base, maxLock time.Duration, escalateSince time.Time) (*models.LoginThrottle, error)
+	// Clear removes the count and any lockout, reporting whether there was anything to remove.
+	Clear(ctx context.Context, scope, subject string) (bool, error)
+	// DeleteStale removes counts with no failures since failedBefore, unless they were locked out
+	// after lockedBefore, whose lockout count is still needed.
+	DeleteStale(ctx context.Context, failedBefore, lockedBefore time.Time) error
+
+	CreateLockout(ctx context.Context, l *models.LoginLockout) (*models.LoginLockout, error)
+	ListLockouts(ctx context.Context, limit int) ([]*models.LoginLockout, error)
+	DeleteLockoutsBefore(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,11 @@
+package repos
+
+import (
+	"context"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, p *models.SearchParams) ([]*models.SearchHit, error)
+}
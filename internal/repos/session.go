@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/thecoretg/ticketbot/models"
 )
@@ -9,6 +10,10 @@ import (
 type SessionRepository interface {
 	Create(ctx context.Context, s *models.Session) (*models.Session, error)
 	GetByTokenHash(ctx context.Context, tokenHash []byte) (*models.Session, error)
+	ListByUser(ctx context.Context, userID int) ([]*models.Session, error)
+	Touch(ctx context.Context, id int, expiresAt time.Time, client models.SessionClient) error
 	Delete(ctx context.Context, id int) error
+	DeleteForUser(ctx context.Context, id, userID int) error
+	DeleteAllForUser(ctx context.Context, userID int, keepID *int) (int64, error)
 	DeleteExpired(ctx context.Context) error
 }
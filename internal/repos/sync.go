@@ -0,0 +1,24 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+type SyncStateRepository interface {
+	List(ctx context.Context) ([]*models.SyncState, error)
+	Get(ctx context.Context, t models.SyncType) (*models.SyncState, error)
+	RecordRun(ctx context.Context, s *models.SyncState) (*models.SyncState, error)
+	SetWatermark(ctx context.Context, t models.SyncType, watermark time.Time) error
+}
+
+type SyncJobRepository interface {
+	List(ctx context.Context, f *models.SyncJobFilter) ([]*models.SyncJob, error)
+	Get(ctx context.Context, id int) (*models.SyncJob, error)
+	Create(ctx context.Context, j *models.SyncJob) (*models.SyncJob, error)
+	Update(ctx context.Context, j *models.SyncJob) (*models.SyncJob, error)
+	InterruptRunning(ctx context.Context) (int64, error)
+	DeleteOlderThan(ctx context.Context, before time.Time) error
+}
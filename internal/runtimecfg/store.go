@@ -0,0 +1,91 @@
+// Package runtimecfg holds the app config the running server uses. The config is published as an
+// immutable snapshot that's swapped atomically, so readers never see a half-applied change and
+// never need a lock, and components that need to act on a change can subscribe to it.
+package runtimecfg
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// Subscriber is called with the previous and new config after a change is published. Both are
+// snapshots and must not be modified.
+type Subscriber func(prev, next *models.Config)
+
+type Store struct {
+	cur atomic.Pointer[models.Config]
+
+	// pubMu makes publishes, and so subscriber calls, happen one at a time and in order.
+	pubMu  sync.Mutex
+	subsMu sync.Mutex
+	subs   map[int]Subscriber
+	nextID int
+}
+
+func New(initial *models.Config) *Store {
+	s := &Store{subs: make(map[int]Subscriber)}
+	s.cur.Store(initial.Clone())
+	return s
+}
+
+// Load returns the current config. The snapshot must not be modified; read a field from the same
+// snapshot rather than calling Load again if several fields need to agree.
+func (s *Store) Load() *models.Config {
+	return s.cur.Load()
+}
+
+// Publish makes a copy of c the current config and calls each subscriber in the order they
+// subscribed, if anything changed. Subscribers must not publish themselves.
+func (s *Store) Publish(c *models.Config) {
+	next := c.Clone()
+
+	s.pubMu.Lock()
+	defer s.pubMu.Unlock()
+
+	prev := s.cur.Load()
+	if reflect.DeepEqual(prev, next) {
+		return
+	}
+	s.cur.Store(next)
+
+	for _, fn := range s.subscribers() {
+		fn(prev, next)
+	}
+}
+
+// Subscribe calls fn after every change from now on, until the returned function is called.
+func (s *Store) Subscribe(fn Subscriber) (unsubscribe func()) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subs[id] = fn
+
+	return func() {
+		s.subsMu.Lock()
+		defer s.subsMu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+func (s *Store) subscribers() []Subscriber {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	ids := make([]int, 0, len(s.subs))
+	for id := range s.subs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	out := make([]Subscriber, len(ids))
+	for i, id := range ids {
+		out[i] = s.subs[id]
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	redacted = "[REDACTED]"
+
+	// minRedactLen keeps very short values from mangling ordinary output. Real secrets are longer.
+	minRedactLen = 6
+)
+
+// Redactor replaces known secret values with [REDACTED]. Values are only ever added, so output
+// that still carries a rotated-out secret is scrubbed too.
+type Redactor struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// Add registers values to redact. Empty and very short values are ignored.
+func (r *Redactor) Add(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range values {
+		if len(v) < minRedactLen || slices.Contains(r.values, v) {
+			continue
+		}
+		r.values = append(r.values, v)
+	}
+}
+
+// Redact returns s with every registered value replaced.
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.values {
+		if strings.Contains(s, v) {
+			s = strings.ReplaceAll(s, v, redacted)
+		}
+	}
+	return s
+}
+
+// Handler wraps h so records are redacted before h sees them.
+func (r *Redactor) Handler(h slog.Handler) slog.Handler {
+	return &redactHandler{next: h, r: r}
+}
+
+type redactHandler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.Redact(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.r.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = h.r.attr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(out), r: h.r}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), r: h.r}
+}
+
+func (r *Redactor) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		out := make([]any, len(group))
+		for i, g := range group {
+			out[i] = r.attr(g)
+		}
+		return slog.Group(a.Key, out...)
+	case slog.KindAny:
+		// errors and Stringers are what usually carry a secret, such as a DSN in a connection error
+		switch x := v.Any().(type) {
+		case nil:
+		case error, fmt.Stringer:
+			s := fmt.Sprint(x)
+			if red := r.Redact(s); red != s {
+				return slog.String(a.Key, red)
+			}
+		default:
+			if red, ok := r.redactValue(x); ok {
+				return slog.String(a.Key, red)
+			}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redactValue checks a struct, map or other value logged with slog.Any for secrets, and if it
+// holds any returns it as a redacted string. It's checked as JSON, which is how most handlers
+// write it and follows pointers, and as %+v, which includes unexported fields.
+func (r *Redactor) redactValue(x any) (string, bool) {
+	r.mu.RLock()
+	empty := len(r.values) == 0
+	r.mu.RUnlock()
+	if empty {
+		return "", false
+	}
+
+	var forms []string
+	if b, err := json.Marshal(x); err == nil {
+		forms = append(forms, string(b))
+	}
+	forms = append(forms, fmt.Sprintf("%+v", x))
+
+	for _, s := range forms {
+		if red := r.Redact(s); red != s {
+			return red, true
+		}
+	}
+	return "", false
+}
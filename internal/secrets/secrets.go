@@ -0,0 +1,78 @@
+// Package secrets reads credentials from the environment, files or a mounted secrets directory,
+// and scrubs their values from log output.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Value is a secret and where it was read from. Source is empty if the secret isn't set.
+type Value struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// Resolver looks secrets up by name in, in order: the NAME environment variable, the file named
+// by NAME_FILE, and a file called NAME (or name, lowercased) in Dir, such as a Docker or
+// Kubernetes secrets mount. Files are read on every lookup, so a rotated secret is picked up by
+// looking it up again.
+type Resolver struct {
+	Dir string
+}
+
+func NewResolver(dir string) *Resolver {
+	return &Resolver{Dir: dir}
+}
+
+// Lookup returns the secret called name. A file that is named but can't be read is an error;
+// a secret that isn't set anywhere is not.
+func (r *Resolver) Lookup(name string) (Value, error) {
+	v := Value{Name: name}
+
+	if s := os.Getenv(name); s != "" {
+		v.Value, v.Source = s, "env "+name
+		return v, nil
+	}
+
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		s, err := readFile(path)
+		if err != nil {
+			return v, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		v.Value, v.Source = s, "file "+path
+		return v, nil
+	}
+
+	if r.Dir == "" {
+		return v, nil
+	}
+
+	for _, f := range []string{name, strings.ToLower(name)} {
+		path := filepath.Join(r.Dir, f)
+		s, err := readFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return v, fmt.Errorf("%s: %w", name, err)
+		}
+		v.Value, v.Source = s, "secrets dir "+path
+		return v, nil
+	}
+
+	return v, nil
+}
+
+// readFile reads a secret file without the trailing newline most tools add.
+func readFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
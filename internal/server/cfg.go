@@ -2,14 +2,21 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
+	"github.com/thecoretg/ticketbot/internal/envelope"
 	"github.com/thecoretg/ticketbot/internal/mock"
+	"github.com/thecoretg/ticketbot/internal/oidc"
+	"github.com/thecoretg/ticketbot/internal/service/authsvc"
 	"github.com/thecoretg/tctg-go/connectwise/psa"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/secrets"
 	"github.com/thecoretg/tctg-go/webex"
 	"github.com/thecoretg/ticketbot/models"
 )
@@ -21,6 +28,49 @@ type Creds struct {
 	PostgresDSN          string
 	WebexAPISecret       string
 	CWCreds              *psa.Config
+
+	// EncryptionKeys are the master keys for secrets stored in the database. See
+	// envelope.ParseKeys for the format.
+	EncryptionKeys string
+
+	// SeedFile is an export document applied at startup if the database has nothing configured.
+	SeedFile string
+
+	// MetricsPort serves /metrics on a port of its own, without auth, instead of on the main
+	// port behind an API key.
+	MetricsPort string
+
+	// OIDC single sign-on for the panel, off unless OIDC_ISSUER_URL is set.
+	OIDC        oidc.Config
+	OIDCName    string
+	SSO         authsvc.SSOPolicy
+	ssoRolesErr error
+
+	// resolver reads the variables in secretVars, which may come from files, and sources records
+	// where each was found, for validate and for logging.
+	resolver *secrets.Resolver
+	sources  map[string]string
+	loadErrs []error
+
+	// dsn is the latest POSTGRES_DSN, which new database connections take their login from.
+	dsn atomic.Pointer[string]
+}
+
+// secretVars are the variables that can also be given as NAME_FILE or as a file in SECRETS_DIR.
+// The values of redacted ones are scrubbed from log output.
+var secretVars = []struct {
+	name   string
+	redact bool
+}{
+	{"POSTGRES_DSN", true},
+	{"WEBEX_SECRET", true},
+	{"CW_PUB_KEY", true},
+	{"CW_PRIV_KEY", true},
+	{"CW_CLIENT_ID", false},
+	{"CW_COMPANY_ID", false},
+	{"INITIAL_ADMIN_PASSWORD", true},
+	{"ENCRYPTION_KEYS", true},
+	{"OIDC_CLIENT_SECRET", true},
 }
 
 type TestFlags struct {
@@ -32,22 +82,135 @@ type TestFlags struct {
 }
 
 func getCreds() *Creds {
-	return &Creds{
-		RootURL:              os.Getenv("ROOT_URL"),
-		InitialAdminEmail:    os.Getenv("INITIAL_ADMIN_EMAIL"),
-		InitialAdminPassword: os.Getenv("INITIAL_ADMIN_PASSWORD"),
-		PostgresDSN:          os.Getenv("POSTGRES_DSN"),
-		WebexAPISecret:       os.Getenv("WEBEX_SECRET"),
-		CWCreds: &psa.Config{
-			PublicKey:  os.Getenv("CW_PUB_KEY"),
-			PrivateKey: os.Getenv("CW_PRIV_KEY"),
-			ClientID:   os.Getenv("CW_CLIENT_ID"),
-			CompanyID:  os.Getenv("CW_COMPANY_ID"),
-		},
+	c := &Creds{
+		resolver: secrets.NewResolver(os.Getenv("SECRETS_DIR")),
+		sources:  make(map[string]string),
+	}
+
+	sv := c.loadSecrets()
+	c.InitialAdminPassword = sv["INITIAL_ADMIN_PASSWORD"].Value
+	c.PostgresDSN = sv["POSTGRES_DSN"].Value
+	c.WebexAPISecret = sv["WEBEX_SECRET"].Value
+	c.EncryptionKeys = sv["ENCRYPTION_KEYS"].Value
+	c.CWCreds = cwConfig(sv)
+	c.dsn.Store(&c.PostgresDSN)
+
+	c.RootURL = os.Getenv("ROOT_URL")
+	c.InitialAdminEmail = os.Getenv("INITIAL_ADMIN_EMAIL")
+	c.SeedFile = os.Getenv("SEED_FILE")
+	c.MetricsPort = os.Getenv("METRICS_PORT")
+
+	c.OIDC = oidc.Config{
+		IssuerURL:    os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: sv["OIDC_CLIENT_SECRET"].Value,
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       splitList(os.Getenv("OIDC_SCOPES")),
+		EmailClaim:   os.Getenv("OIDC_EMAIL_CLAIM"),
+		GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+		TrustEmail:   os.Getenv("OIDC_TRUST_EMAIL") == "true",
+	}
+	if c.OIDC.RedirectURL == "" && c.RootURL != "" {
+		c.OIDC.RedirectURL = strings.TrimSuffix(c.RootURL, "/") + "/auth/oidc/callback"
+	}
+
+	c.OIDCName = os.Getenv("OIDC_PROVIDER_NAME")
+	if c.OIDCName == "" {
+		c.OIDCName = "SSO"
+	}
+
+	c.SSO = authsvc.SSOPolicy{
+		AutoProvision:  os.Getenv("OIDC_AUTO_PROVISION") == "true",
+		AllowedDomains: splitList(os.Getenv("OIDC_ALLOWED_DOMAINS")),
+		DefaultRole:    models.Role(os.Getenv("OIDC_DEFAULT_ROLE")),
+	}
+	if c.SSO.DefaultRole == "" {
+		c.SSO.DefaultRole = models.RoleViewer
+	}
+	c.SSO.GroupRoles, c.ssoRolesErr = authsvc.ParseGroupRoles(os.Getenv("OIDC_GROUP_ROLES"))
+
+	return c
+}
+
+// loadSecrets looks up every secret variable, recording where each came from and any file that
+// couldn't be read.
+func (c *Creds) loadSecrets() map[string]secrets.Value {
+	out := make(map[string]secrets.Value, len(secretVars))
+	for _, sv := range secretVars {
+		v, err := c.resolver.Lookup(sv.name)
+		if err != nil {
+			c.loadErrs = append(c.loadErrs, err)
+		}
+		c.sources[sv.name] = v.Source
+		out[sv.name] = v
 	}
+	return out
+}
+
+func cwConfig(sv map[string]secrets.Value) *psa.Config {
+	return &psa.Config{
+		PublicKey:  sv["CW_PUB_KEY"].Value,
+		PrivateKey: sv["CW_PRIV_KEY"].Value,
+		ClientID:   sv["CW_CLIENT_ID"].Value,
+		CompanyID:  sv["CW_COMPANY_ID"].Value,
+	}
+}
+
+// redactedValues returns the values of the secret variables that are scrubbed from logs.
+func redactedValues(sv map[string]secrets.Value) []string {
+	var out []string
+	for _, v := range secretVars {
+		if v.redact {
+			out = append(out, sv[v.name].Value)
+		}
+	}
+	return out
+}
+
+// secretValues returns the secrets as loaded at startup, for redaction.
+func (c *Creds) secretValues() []string {
+	return []string{
+		c.PostgresDSN, c.WebexAPISecret, c.CWCreds.PublicKey, c.CWCreds.PrivateKey,
+		c.InitialAdminPassword, c.EncryptionKeys, c.OIDC.ClientSecret,
+	}
+}
+
+// currentDSN returns POSTGRES_DSN as last read, which differs from PostgresDSN once it's rotated.
+func (c *Creds) currentDSN() string {
+	return *c.dsn.Load()
+}
+
+// describe names a variable along with where its value was read from, if anywhere.
+func (c *Creds) describe(name string) string {
+	if src := c.sources[name]; src != "" {
+		return fmt.Sprintf("%s (from %s)", name, src)
+	}
+	return name
+}
+
+// LogValue logs where each secret was read from, never the values themselves.
+func (c *Creds) LogValue() slog.Value {
+	var attrs []slog.Attr
+	for _, sv := range secretVars {
+		src := c.sources[sv.name]
+		if src == "" {
+			src = "unset"
+		}
+		attrs = append(attrs, slog.String(strings.ToLower(sv.name), src))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// splitList splits a comma or space separated env value.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
 }
 
 func (c *Creds) validate(tf *TestFlags) error {
+	if len(c.loadErrs) > 0 {
+		return fmt.Errorf("reading secrets: %w", errors.Join(c.loadErrs...))
+	}
+
 	req := map[string]string{
 		"INITIAL_ADMIN_EMAIL": c.InitialAdminEmail,
 	}
@@ -67,7 +230,7 @@ func (c *Creds) validate(tf *TestFlags) error {
 	}
 
 	if c.PostgresDSN == "" {
-		empty = append(empty, "POSTGRES_DSN")
+		empty = append(empty, c.describe("POSTGRES_DSN"))
 	}
 
 	if c.RootURL == "" {
@@ -79,22 +242,56 @@ func (c *Creds) validate(tf *TestFlags) error {
 	}
 
 	if c.WebexAPISecret == "" {
-		empty = append(empty, "WEBEX_SECRET")
+		empty = append(empty, c.describe("WEBEX_SECRET"))
+	}
+
+	if c.EncryptionKeys == "" {
+		empty = append(empty, c.describe("ENCRYPTION_KEYS"))
 	}
 
 	for k, v := range cwVals {
 		if v == "" {
-			empty = append(empty, k)
+			empty = append(empty, c.describe(k))
+		}
+	}
+
+	if c.OIDC.Enabled() {
+		if c.OIDC.ClientID == "" {
+			empty = append(empty, "OIDC_CLIENT_ID")
+		}
+		if c.OIDC.RedirectURL == "" {
+			empty = append(empty, "OIDC_REDIRECT_URL")
 		}
 	}
 
 	if len(empty) > 0 {
-		return fmt.Errorf("1 or more required env variables are empty: %v", empty)
+		// a variable with a source was found but is blank, such as an empty secret file
+		return fmt.Errorf("1 or more required env variables are empty: %v (secrets can also be set as NAME_FILE or in SECRETS_DIR)", empty)
+	}
+
+	if c.OIDC.Enabled() {
+		if c.ssoRolesErr != nil {
+			return fmt.Errorf("OIDC_GROUP_ROLES: %w", c.ssoRolesErr)
+		}
+		if !c.SSO.DefaultRole.Valid() {
+			return fmt.Errorf("OIDC_DEFAULT_ROLE: %w: %q", models.ErrInvalidRole, c.SSO.DefaultRole)
+		}
+		if c.SSO.AutoProvision && len(c.SSO.AllowedDomains) == 0 {
+			return fmt.Errorf("OIDC_AUTO_PROVISION needs OIDC_ALLOWED_DOMAINS")
+		}
 	}
 
 	return nil
 }
 
+func (c *Creds) keyring() (*envelope.Keyring, error) {
+	k, err := envelope.ParseKeys(c.EncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("loading encryption keys: %w", err)
+	}
+	return k, nil
+}
+
 // getStartupConfig gets the current config at server startup. It uses the default if one is not
 // found in the store, upserts, and then returns the final result.
 func getStartupConfig(ctx context.Context, r repos.ConfigRepository) (*models.Config, error) {
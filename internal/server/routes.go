@@ -6,8 +6,19 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/thecoretg/ticketbot/internal/handlers"
+	"github.com/thecoretg/ticketbot/internal/metrics"
 	"github.com/thecoretg/ticketbot/internal/middleware"
 	"github.com/thecoretg/ticketbot/internal/web"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// Every authenticated user is at least a viewer, so read-only routes only need auth. Routes that
+// change things add one of these after it. Groups also add middleware.RequireScope for their area,
+// which only limits scoped API keys.
+var (
+	requireOperator = middleware.RequireRole(models.RoleOperator)
+	requireAdmin    = middleware.RequireRole(models.RoleAdmin)
+	requireSession  = middleware.RequireSession()
 )
 
 func AddRoutes(a *App, g *gin.Engine, shutdown func()) {
@@ -19,75 +30,121 @@ func AddRoutes(a *App, g *gin.Engine, shutdown func()) {
 	g.GET("healthcheck", handlers.HandleHealthCheck) // authless ping for lightsail health checks
 	g.GET("authtest", auth, handlers.HandleHealthCheck)
 
-	ah := handlers.NewAuthHandler(a.Svc.Auth)
+	ah := handlers.NewAuthHandler(a.Svc.Auth, a.Svc.Audit)
 	g.POST("auth/login", ah.HandleLogin)
 	g.POST("auth/logout", ah.HandleLogout)
-	g.PUT("auth/password", auth, ah.HandleChangePassword)
+	g.PUT("auth/password", auth, requireSession, ah.HandleChangePassword)
+
+	oh := handlers.NewOIDCHandler(a.OIDC, a.Creds.OIDCName, a.Svc.Auth, a.Svc.Audit)
+	g.GET("auth/oidc", oh.HandleStatus)
+	g.GET("auth/oidc/login", oh.HandleLogin)
+	g.GET("auth/oidc/callback", oh.HandleCallback)
+
+	ssh := handlers.NewSessionHandler(a.Svc.Auth, a.Svc.Audit)
+	account := middleware.RequireScope("account")
+	g.GET("auth/sessions", auth, account, ssh.HandleList)
+	g.DELETE("auth/sessions", auth, account, ssh.HandleRevokeOthers)
+	g.DELETE("auth/sessions/:id", auth, account, ssh.HandleRevoke)
 
-	th := handlers.NewTOTPHandler(a.Svc.Auth)
+	th := handlers.NewTOTPHandler(a.Svc.Auth, a.Svc.Audit)
 	g.POST("auth/totp/verify", th.HandleVerify)
-	g.GET("auth/totp", auth, th.HandleStatus)
-	g.POST("auth/totp/setup", auth, th.HandleBeginSetup)
-	g.PUT("auth/totp/setup", auth, th.HandleConfirmSetup)
-	g.DELETE("auth/totp", auth, th.HandleDisable)
+	g.GET("auth/totp", auth, requireSession, th.HandleStatus)
+	g.POST("auth/totp/setup", auth, requireSession, th.HandleBeginSetup)
+	g.PUT("auth/totp/setup", auth, requireSession, th.HandleConfirmSetup)
+	g.DELETE("auth/totp", auth, requireSession, th.HandleDisable)
 
-	s := g.Group("sync", auth)
-	sh := handlers.NewSyncHandler(a.Svc.Sync, a.Config)
+	s := g.Group("sync", auth, middleware.RequireScope("sync"))
+	sh := handlers.NewSyncHandler(a.Svc.Sync, a.Config, a.Svc.Audit)
 	registerSyncRoutes(s, sh)
 
 	u := g.Group("users", auth)
-	uh := handlers.NewUserHandler(a.Svc.User)
-	registerUserRoutes(u, uh)
+	uh := handlers.NewUserHandler(a.Svc.User, a.Svc.Audit)
+	registerUserRoutes(u, uh, ssh, handlers.NewLockoutHandler(a.Svc.Auth, a.Svc.Audit))
 
-	c := g.Group("config", auth)
-	ch := handlers.NewConfigHandler(a.Svc.Config)
+	c := g.Group("config", auth, middleware.RequireScope("config"))
+	ch := handlers.NewConfigHandler(a.Svc.Config, a.Svc.Audit)
 	registerConfigRoutes(c, ch)
 
-	cw := g.Group("cw", auth)
+	cw := g.Group("cw", auth, middleware.RequireScope("cw"))
 	cwh := handlers.NewCWHandler(a.Svc.CW)
 	registerCWRoutes(cw, cwh)
 
-	wx := g.Group("webex", auth)
+	wx := g.Group("webex", auth, middleware.RequireScope("webex"))
 	wh := handlers.NewWebexHandler(a.Svc.Webex)
 	registerWebexRoutes(wx, wh)
 
-	n := g.Group("notifiers", auth)
-	nh := handlers.NewNotifierHandler(a.Svc.Notifier)
+	n := g.Group("notifiers", auth, middleware.RequireScope("notifiers"))
+	nh := handlers.NewNotifierHandler(a.Svc.Notifier, a.Svc.Audit)
 	registerNotifierRoutes(n, nh)
 
-	lh := handlers.NewLogsHandler(a.LogBuffer)
-	g.GET("logs", auth, lh.HandleList)
+	eh := handlers.NewExportHandler(a.Svc.Export, a.Svc.Audit)
+	g.GET("export", auth, middleware.RequireScope("config"), eh.HandleExport)
+	g.POST("import", auth, requireAdmin, middleware.RequireScope("config"), eh.HandleImport)
+
+	sch := handlers.NewSearchHandler(a.Svc.Search)
+	g.GET("search", auth, middleware.RequireScope("search"), sch.HandleSearch)
+
+	auh := handlers.NewAuditHandler(a.Svc.Audit)
+	g.GET("audit", auth, requireAdmin, middleware.RequireScope("audit"), auh.HandleList)
+
+	if a.Creds.MetricsPort == "" {
+		g.GET("metrics", auth, middleware.RequireScope("metrics"), gin.WrapH(metrics.Handler()))
+	}
 
-	adminh := handlers.NewAdminHandler(shutdown)
-	g.POST("admin/restart", auth, adminh.HandleRestart)
+	lh := handlers.NewLogsHandler(a.LogBuffer, a.Stores.Logs)
+	g.GET("logs", auth, middleware.RequireScope("logs"), lh.HandleList)
+	g.GET("logs/query", auth, middleware.RequireScope("logs"), lh.HandleQuery)
+	g.GET("logs/export", auth, middleware.RequireScope("logs"), lh.HandleExport)
+	g.GET("logs/stream", auth, middleware.RequireScope("logs"), lh.HandleStream)
+
+	adminh := handlers.NewAdminHandler(shutdown, a.Svc.Audit)
+	g.POST("admin/restart", auth, requireAdmin, middleware.RequireScope("admin"), adminh.HandleRestart)
 
 	tb := handlers.NewTicketbotHandler(a.Svc.Ticketbot)
+	cwhh := handlers.NewCWHookHandler(a.Svc.CW)
 	hh := g.Group("hooks")
-	registerHookRoutes(hh, tb)
+	registerHookRoutes(hh, tb, cwhh, middleware.TrackHookReceived(a.Svc.Hooks.MarkCWHookReceived))
+
+	hkh := handlers.NewHookHandler(a.Svc.Hooks)
+	g.GET("hooks/health", auth, middleware.RequireScope("hooks"), hkh.HandleHealth)
 }
 
 func registerSyncRoutes(r *gin.RouterGroup, h *handlers.SyncHandler) {
-	r.POST("", h.HandleSync)
+	r.POST("", requireOperator, h.HandleSync)
 	r.GET("status", h.HandleSyncStatus)
+	r.GET("jobs", h.ListSyncJobs)
+	r.GET("jobs/:id", h.GetSyncJob)
+	r.POST("jobs/:id/cancel", requireOperator, h.CancelSyncJob)
 }
 
-func registerUserRoutes(r *gin.RouterGroup, h *handlers.UserHandler) {
-	r.GET("", h.ListUsers)
+func registerUserRoutes(r *gin.RouterGroup, h *handlers.UserHandler, sh *handlers.SessionHandler, lh *handlers.LockoutHandler) {
 	r.GET("me", h.GetCurrentUser)
-	r.GET(":id", h.GetUser)
-	r.POST("", h.CreateUser)
-	r.DELETE(":id", h.DeleteUser)
 
-	k := r.Group("keys")
+	u := r.Group("", middleware.RequireScope("users"))
+	u.GET("", requireAdmin, h.ListUsers)
+	u.GET("lockouts", requireAdmin, lh.HandleList)
+	u.DELETE("lockouts/ip/:ip", requireAdmin, lh.HandleUnlockIP)
+	u.GET(":id", requireAdmin, h.GetUser)
+	u.POST("", requireAdmin, h.CreateUser)
+	u.PUT(":id/role", requireAdmin, h.SetUserRole)
+	u.DELETE(":id", requireAdmin, h.DeleteUser)
+	u.DELETE(":id/sessions", requireAdmin, sh.HandleRevokeUser)
+	u.DELETE(":id/lockout", requireAdmin, lh.HandleUnlock)
+
+	// anyone can manage their own keys; the user service only lets admins touch other users' keys
+	k := r.Group("keys", middleware.RequireScope("keys"))
 	k.GET("", h.ListAPIKeys)
 	k.GET(":id", h.GetAPIKey)
 	k.POST("", h.AddAPIKey)
+	k.POST(":id/rotate", h.RotateAPIKey)
 	k.DELETE(":id", h.DeleteAPIKey)
 }
 
 func registerConfigRoutes(r *gin.RouterGroup, h *handlers.ConfigHandler) {
 	r.GET("", h.Get)
-	r.PUT("", h.Update)
+	r.PUT("", requireAdmin, h.Update)
+	r.GET("history", requireAdmin, h.History)
+	r.POST("rollback/:revision", requireAdmin, h.Rollback)
 }
 
 func registerCWRoutes(r *gin.RouterGroup, h *handlers.CWHandler) {
@@ -97,6 +154,24 @@ func registerCWRoutes(r *gin.RouterGroup, h *handlers.CWHandler) {
 
 	m := r.Group("members")
 	m.GET("", h.ListMembers)
+
+	t := r.Group("tickets")
+	t.GET("", h.ListTickets)
+	t.GET(":id", h.GetTicket)
+
+	co := r.Group("companies")
+	co.GET("", h.ListCompanies)
+	co.GET(":id", h.GetCompany)
+
+	ct := r.Group("contacts")
+	ct.GET("", h.ListContacts)
+	ct.GET(":id", h.GetContact)
+
+	st := r.Group("statuses")
+	st.GET("", h.ListStatuses)
+	st.GET(":id", h.GetStatus)
+
+	r.GET("client/stats", h.ClientStats)
 }
 
 func registerWebexRoutes(r *gin.RouterGroup, h *handlers.WebexHandler) {
@@ -109,16 +184,20 @@ func registerNotifierRoutes(r *gin.RouterGroup, h *handlers.NotifierHandler) {
 	ru := r.Group("rules")
 	ru.GET("", h.ListNotifierRules)
 	ru.GET(":id", h.GetNotifierRule)
-	ru.POST("", h.AddNotifierRule)
-	ru.DELETE(":id", h.DeleteNotifierRule)
+	ru.POST("", requireOperator, h.AddNotifierRule)
+	ru.DELETE(":id", requireOperator, h.DeleteNotifierRule)
 
 	fw := r.Group("forwards")
 	fw.GET("", h.ListForwards)
 	fw.GET(":id", h.GetForward)
-	fw.POST("", h.AddUserForward)
-	fw.DELETE(":id", h.DeleteUserForward)
+	fw.POST("", requireOperator, h.AddUserForward)
+	fw.DELETE(":id", requireOperator, h.DeleteUserForward)
 }
 
-func registerHookRoutes(r *gin.RouterGroup, tb *handlers.TicketbotHandler) {
-	r.POST("cw/tickets", middleware.RequireConnectwiseSignature(), tb.ProcessTicket)
+func registerHookRoutes(r *gin.RouterGroup, tb *handlers.TicketbotHandler, cwh *handlers.CWHookHandler, received gin.HandlerFunc) {
+	cw := r.Group("cw", middleware.RequireConnectwiseSignature(), received)
+	cw.POST("tickets", tb.ProcessTicket)
+	cw.POST("companies", cwh.ProcessCompany)
+	cw.POST("contacts", cwh.ProcessContact)
+	cw.POST("members", cwh.ProcessMember)
 }
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/tctg-go/webex"
+	"github.com/thecoretg/ticketbot/internal/metrics"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// secretsPollInterval is how often file-based secrets are re-read for rotation.
+const secretsPollInterval = 30 * time.Second
+
+// StartSecretWatcher re-reads file-based secrets and applies rotated ones without a restart:
+// Connectwise keys and the Webex token get new clients, a new Postgres login is used for new
+// connections, and a new OIDC client secret for the next sign-in. ENCRYPTION_KEYS and
+// INITIAL_ADMIN_PASSWORD are only read at startup; new keys take the rotate-keys command and a
+// restart. Secrets only given as plain env variables can't change, so it does nothing if none
+// came from a file.
+func (a *App) StartSecretWatcher(ctx context.Context) {
+	if !a.Creds.fileBased() {
+		return
+	}
+
+	go func() {
+		t := time.NewTicker(secretsPollInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				a.reloadSecrets(ctx)
+			}
+		}
+	}()
+}
+
+// reloadSecrets applies any secrets that changed since the last read. A secret that can't be
+// read, is now empty or doesn't give a working client is skipped, keeping the old value, and
+// retried on the next poll.
+func (a *App) reloadSecrets(ctx context.Context) {
+	cr := a.Creds
+	cr.loadErrs = nil
+	sv := cr.loadSecrets()
+	for _, err := range cr.loadErrs {
+		slog.Warn("secret watcher: reading secret", "error", err)
+	}
+
+	a.redactor.Add(redactedValues(sv)...)
+
+	cw := cwConfig(sv)
+	if *cw != *cr.CWCreds && cw.PublicKey != "" && cw.PrivateKey != "" && cw.ClientID != "" && cw.CompanyID != "" {
+		cl, err := psa.NewClient(ctx, *cw)
+		if err != nil {
+			slog.Error("secret watcher: creating connectwise client with rotated keys", "error", err)
+		} else {
+			a.CWClient.SetClient(cl)
+			cr.CWCreds = cw
+			slog.Info("secret watcher: applied rotated connectwise keys", "source", cr.sources["CW_PRIV_KEY"])
+		}
+	}
+
+	if v := sv["WEBEX_SECRET"]; v.Value != "" && v.Value != cr.WebexAPISecret {
+		ms, err := makeMessageSender(ctx, a.TestFlags.MockWebex, v.Value)
+		if err != nil {
+			slog.Error("secret watcher: creating webex client with rotated token", "error", err)
+		} else {
+			a.sender.set(ms)
+			cr.WebexAPISecret = v.Value
+			slog.Info("secret watcher: applied rotated webex token", "source", v.Source)
+		}
+	}
+
+	if v := sv["OIDC_CLIENT_SECRET"]; a.OIDC != nil && v.Value != "" && v.Value != cr.OIDC.ClientSecret {
+		a.OIDC.SetClientSecret(v.Value)
+		cr.OIDC.ClientSecret = v.Value
+		slog.Info("secret watcher: applied rotated oidc client secret", "source", v.Source)
+	}
+
+	if v := sv["POSTGRES_DSN"]; v.Value != "" && v.Value != cr.currentDSN() {
+		dsn := v.Value
+		cr.dsn.Store(&dsn)
+		slog.Info("secret watcher: applied rotated postgres login for new connections", "source", v.Source)
+	}
+}
+
+// fileBased reports whether any secret was read from a file, and so could be rotated.
+func (c *Creds) fileBased() bool {
+	for _, src := range c.sources {
+		if src != "" && !strings.HasPrefix(src, "env ") {
+			return true
+		}
+	}
+	return false
+}
+
+// swapSender is the message sender handed to services, so the Webex client behind it can be
+// replaced when the token rotates. It also records every Webex request for metrics.
+type swapSender struct {
+	cur atomic.Pointer[senderBox]
+}
+
+// senderBox lets the mock and real clients share one atomic.Pointer.
+type senderBox struct {
+	repos.MessageSender
+}
+
+func newSwapSender(ms repos.MessageSender) *swapSender {
+	s := &swapSender{}
+	s.set(ms)
+	return s
+}
+
+func (s *swapSender) set(ms repos.MessageSender) {
+	s.cur.Store(&senderBox{ms})
+}
+
+func (s *swapSender) get() repos.MessageSender {
+	return s.cur.Load().MessageSender
+}
+
+func (s *swapSender) GetMessage(ctx context.Context, id string, params map[string]string) (m *webex.Message, err error) {
+	ctx, done := observeWebex(ctx, "GET messages/:id")
+	defer func() { done(err) }()
+	return s.get().GetMessage(ctx, id, params)
+}
+
+func (s *swapSender) GetAttachmentAction(ctx context.Context, messageID string) (a *webex.AttachmentAction, err error) {
+	ctx, done := observeWebex(ctx, "GET attachment/actions/:id")
+	defer func() { done(err) }()
+	return s.get().GetAttachmentAction(ctx, messageID)
+}
+
+func (s *swapSender) PostMessage(ctx context.Context, message *webex.Message) (m *webex.Message, err error) {
+	ctx, done := observeWebex(ctx, "POST messages")
+	defer func() { done(err) }()
+	return s.get().PostMessage(ctx, message)
+}
+
+func (s *swapSender) ListRooms(ctx context.Context, params map[string]string) (r []webex.Room, err error) {
+	ctx, done := observeWebex(ctx, "GET rooms")
+	defer func() { done(err) }()
+	return s.get().ListRooms(ctx, params)
+}
+
+func (s *swapSender) ListPeople(ctx context.Context, email string) (p []webex.Person, err error) {
+	ctx, done := observeWebex(ctx, "GET people")
+	defer func() { done(err) }()
+	return s.get().ListPeople(ctx, email)
+}
+
+// observeWebex starts a span for a Webex request, and returns a function that ends it and records
+// the request for metrics. The client doesn't always say what status a failed request got, so
+// those without one are counted as "error".
+func observeWebex(ctx context.Context, endpoint string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "webex "+endpoint, trace.WithSpanKind(trace.SpanKindClient))
+
+	return ctx, func(err error) {
+		status := "2xx"
+		if err != nil {
+			status = "error"
+			var sc interface{ StatusCode() int }
+			if errors.As(err, &sc) {
+				status = strconv.Itoa(sc.StatusCode())
+				span.SetAttributes(semconv.HTTPResponseStatusCode(sc.StatusCode()))
+			}
+		}
+		metrics.APIRequest(metrics.ServiceWebex, endpoint, status, time.Since(start))
+		tracing.End(span, err)
+	}
+}
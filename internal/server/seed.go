@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/thecoretg/ticketbot/internal/service/exportsvc"
+)
+
+// Seed applies the SEED_FILE export document if the database has nothing configured yet, so a
+// fresh deployment starts from a known state. Boards and recipients are synced first since the
+// document refers to them by name. Once anything is configured, the file is ignored.
+func (a *App) Seed(ctx context.Context) error {
+	path := a.Creds.SeedFile
+	if path == "" {
+		return nil
+	}
+
+	empty, err := a.Svc.Export.Empty(ctx)
+	if err != nil {
+		return fmt.Errorf("checking for existing config: %w", err)
+	}
+	if !empty {
+		slog.Info("database already configured, skipping seed file", "path", path)
+		return nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading seed file: %w", err)
+	}
+
+	f, err := exportsvc.ParseFormat(formatFromPath(path))
+	if err != nil {
+		return err
+	}
+
+	doc, err := exportsvc.Decode(b, f)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Svc.Sync.SyncBoards(ctx, nil); err != nil {
+		return fmt.Errorf("syncing boards for seed: %w", err)
+	}
+	if err := a.Svc.Sync.SyncWebexRecipients(ctx, a.Config.Load().MaxConcurrentSyncs, nil); err != nil {
+		return fmt.Errorf("syncing webex recipients for seed: %w", err)
+	}
+
+	res, err := a.Svc.Export.Import(ctx, doc, false, nil)
+	if err != nil {
+		return fmt.Errorf("applying seed file: %w", err)
+	}
+
+	slog.Info("applied seed file", "path", path, "config_changes", len(res.Config), "changes", len(res.Changes))
+	return nil
+}
+
+// formatFromPath reads JSON from .json files and YAML from anything else.
+func formatFromPath(path string) string {
+	if filepath.Ext(path) == ".json" {
+		return "json"
+	}
+	return "yaml"
+}
@@ -2,17 +2,26 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/thecoretg/ticketbot/internal/logging"
 	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/internal/cwclient"
+	"github.com/thecoretg/ticketbot/internal/logging"
+	"github.com/thecoretg/ticketbot/internal/metrics"
+	"github.com/thecoretg/ticketbot/internal/oidc"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
+	"github.com/thecoretg/ticketbot/internal/secrets"
+	"github.com/thecoretg/ticketbot/internal/service/auditsvc"
 	"github.com/thecoretg/ticketbot/internal/service/authsvc"
 	"github.com/thecoretg/ticketbot/internal/service/config"
 	"github.com/thecoretg/ticketbot/internal/service/cwsvc"
+	"github.com/thecoretg/ticketbot/internal/service/exportsvc"
 	"github.com/thecoretg/ticketbot/internal/service/notifier"
+	"github.com/thecoretg/ticketbot/internal/service/searchsvc"
 	"github.com/thecoretg/ticketbot/internal/service/syncsvc"
 	"github.com/thecoretg/ticketbot/internal/service/ticketbot"
 	"github.com/thecoretg/ticketbot/internal/service/user"
@@ -25,35 +34,55 @@ type App struct {
 	Creds                   *Creds
 	TestFlags               *TestFlags
 	Stores                  *repos.AllRepos
-	CWClient                *psa.Client
+	CWClient                *cwclient.Client
 	MessageSender           repos.MessageSender
 	Pool                    *pgxpool.Pool
-	Config                  *models.Config
+	Config                  *runtimecfg.Store
 	Svc                     *Services
 	CurrentMigrationVersion int64
 	LogBuffer               *logging.BufferHandler
+	// OIDC is nil unless single sign-on is configured.
+	OIDC *oidc.Provider
+
+	// sender is MessageSender, kept concrete so StartSecretWatcher can swap in a new Webex client.
+	sender   *swapSender
+	redactor *secrets.Redactor
 }
 
 type Services struct {
+	Audit     *auditsvc.Service
 	Auth      *authsvc.Service
 	Config    *config.Service
+	Export    *exportsvc.Service
 	User      *user.Service
 	CW        *cwsvc.Service
 	Hooks     *webhooks.Service
 	Webex     *webexsvc.Service
 	Sync      *syncsvc.Service
 	Notifier  *notifier.Service
+	Search    *searchsvc.Service
 	Ticketbot *ticketbot.Service
 }
 
 const defaultStoreTTL = int64(900)
 
-func NewApp(ctx context.Context, migVersion int64, level *slog.LevelVar, logBuf *logging.BufferHandler) (*App, *logging.Persister, error) {
+// NewApp builds the app from the environment. redactor should be the one wrapping the default
+// logger's handler; the secrets loaded here are added to it.
+func NewApp(ctx context.Context, migVersion int64, level *slog.LevelVar, logBuf *logging.BufferHandler, redactor *secrets.Redactor) (*App, *logging.Persister, error) {
 	cr := getCreds()
+	redactor.Add(cr.secretValues()...)
+
 	tf := getTestFlags()
 	if err := cr.validate(tf); err != nil {
 		return nil, nil, fmt.Errorf("validating credentials: %w", err)
 	}
+	slog.Info("loaded credentials", "sources", cr)
+
+	keys, err := cr.keyring()
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Info("loaded encryption keys", "primary_key_id", keys.PrimaryID())
 
 	ttl := defaultStoreTTL
 	if tf.StoreTTLSeconds != 0 {
@@ -66,23 +95,27 @@ func NewApp(ctx context.Context, migVersion int64, level *slog.LevelVar, logBuf
 		return nil, nil, fmt.Errorf("creating connectwise client: %w", err)
 	}
 
-	ms, err := makeMessageSender(ctx, tf.MockWebex, cr.WebexAPISecret)
+	wc, err := makeMessageSender(ctx, tf.MockWebex, cr.WebexAPISecret)
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating message sender: %w", err)
 	}
+	ms := newSwapSender(wc)
 
 	s, err := CreateStores(ctx, cr, migVersion)
 	if err != nil {
 		return nil, nil, fmt.Errorf("initializing stores: %w", err)
 	}
 	r := s.Repos
+	metrics.RegisterPool(s.Pool)
 
-	cfg, err := getStartupConfig(ctx, r.Config)
+	startup, err := getStartupConfig(ctx, r.Config)
 	if err != nil {
 		return nil, nil, fmt.Errorf("getting initial config: %w", err)
 	}
+	cfg := runtimecfg.New(startup)
 
-	cws := cwsvc.New(s.Pool, r.CW, cw, ttl)
+	cwc := cwclient.New(cw, cfg)
+	cws := cwsvc.New(s.Pool, r.CW, cwc, ttl)
 	ws := webexsvc.New(s.Pool, r.WebexRecipients, ms)
 
 	nr := notifier.SvcParams{
@@ -97,8 +130,15 @@ func NewApp(ctx context.Context, migVersion int64, level *slog.LevelVar, logBuf
 	}
 
 	ns := notifier.New(nr)
+	cs := config.New(s.Pool, r.Config, r.ConfigRevisions, cfg, level, logBuf)
+
+	persister := logging.NewPersister(r.Logs, logBuf, func() logging.LogConfig { return cfg.Load() })
 
-	persister := logging.NewPersister(r.Logs, logBuf, cfg)
+	var op *oidc.Provider
+	if cr.OIDC.Enabled() {
+		op = oidc.New(cr.OIDC)
+		slog.Info("oidc single sign-on enabled", "issuer", cr.OIDC.IssuerURL, "auto_provision", cr.SSO.AutoProvision)
+	}
 
 	return &App{
 		Creds:         cr,
@@ -106,19 +146,54 @@ func NewApp(ctx context.Context, migVersion int64, level *slog.LevelVar, logBuf
 		TestFlags:     tf,
 		Stores:        r,
 		Pool:          s.Pool,
-		CWClient:      cw,
+		CWClient:      cwc,
 		MessageSender: ms,
 		LogBuffer:     logBuf,
+		OIDC:          op,
+		sender:        ms,
+		redactor:      redactor,
 		Svc: &Services{
-			Auth:      authsvc.New(r.APIUser, r.Sessions, r.TOTPPending, r.TOTPRecovery, cfg),
-			Config:    config.New(r.Config, cfg, level, logBuf),
+			Audit:     auditsvc.New(r.Audit, cfg),
+			Auth:      authsvc.New(r.APIUser, r.Sessions, r.TOTPPending, r.TOTPRecovery, r.LoginThrottle, cr.SSO, keys, cfg),
+			Config:    cs,
+			Export:    exportsvc.New(s.Pool, cs, r.NotifierRules, r.NotifierForwards, r.CW.Board, r.WebexRecipients),
 			User:      user.New(r.APIUser, r.APIKey),
-			Hooks:     webhooks.New(cw, cr.RootURL),
+			Hooks:     webhooks.New(cwc, cr.RootURL, cfg, r.HookHealth, r.WebexRecipients, ms),
 			CW:        cws,
 			Webex:     ws,
-			Sync:      syncsvc.New(s.Pool, cws, ws, ns),
+			Sync:      syncsvc.New(s.Pool, cfg, cws, ws, ns, r.SyncJobs, r.SyncState),
 			Notifier:  notifier.New(nr),
+			Search:    searchsvc.New(r.Search),
 			Ticketbot: ticketbot.New(cfg, cws, ns),
 		},
 	}, persister, nil
 }
+
+// RotateKeys re-encrypts stored secrets under the primary encryption key, for the rotate-keys
+// command. Run it after putting a new key first in ENCRYPTION_KEYS; the old keys can be removed
+// once it succeeds.
+func RotateKeys(ctx context.Context, migVersion int64) (authsvc.SecretsResult, error) {
+	cr := getCreds()
+	if len(cr.loadErrs) > 0 {
+		return authsvc.SecretsResult{}, fmt.Errorf("reading secrets: %w", errors.Join(cr.loadErrs...))
+	}
+
+	keys, err := cr.keyring()
+	if err != nil {
+		return authsvc.SecretsResult{}, err
+	}
+
+	if cr.PostgresDSN == "" {
+		return authsvc.SecretsResult{}, fmt.Errorf("POSTGRES_DSN is empty")
+	}
+
+	s, err := CreateStores(ctx, cr, migVersion)
+	if err != nil {
+		return authsvc.SecretsResult{}, fmt.Errorf("initializing stores: %w", err)
+	}
+	defer s.Pool.Close()
+
+	r := s.Repos
+	auth := authsvc.New(r.APIUser, r.Sessions, r.TOTPPending, r.TOTPRecovery, r.LoginThrottle, cr.SSO, keys, runtimecfg.New(&models.DefaultConfig))
+	return auth.EncryptSecrets(ctx, true)
+}
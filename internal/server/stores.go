@@ -7,6 +7,8 @@ import (
 	"io/fs"
 	"log/slog"
 
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -28,7 +30,31 @@ func CreateStores(ctx context.Context, creds *Creds, targetMigVersion int64) (*S
 // InitPostgresStores verifies credentials are given, runs any needed migrations, and
 // provides all repositories
 func InitPostgresStores(ctx context.Context, creds *Creds, targetMigVersion int64) (*Stores, error) {
-	pool, err := pgxpool.New(ctx, creds.PostgresDSN)
+	pc, err := pgxpool.ParseConfig(creds.PostgresDSN)
+	if err != nil {
+		return nil, fmt.Errorf("parsing POSTGRES_DSN: %w", err)
+	}
+
+	// new connections log in with the latest credentials, so a rotated database password is
+	// picked up without a restart. Connections already open keep working until they're recycled.
+	pc.BeforeConnect = func(ctx context.Context, cc *pgx.ConnConfig) error {
+		dsn := creds.currentDSN()
+		if dsn == creds.PostgresDSN {
+			return nil
+		}
+
+		next, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return fmt.Errorf("parsing rotated POSTGRES_DSN: %w", err)
+		}
+		cc.User, cc.Password = next.User, next.Password
+		return nil
+	}
+
+	// every query gets a span, so slow statements show up in ticket processing traces
+	pc.ConnConfig.Tracer = otelpgx.NewTracer()
+
+	pool, err := pgxpool.NewWithConfig(ctx, pc)
 	if err != nil {
 		return nil, fmt.Errorf("creating pgx pool: %w", err)
 	}
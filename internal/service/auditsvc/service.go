@@ -0,0 +1,110 @@
+package auditsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	// cleanupInterval is how often entries older than AuditRetentionDays are deleted.
+	cleanupInterval = 6 * time.Hour
+)
+
+type Service struct {
+	Entries repos.AuditRepository
+	cfg     *runtimecfg.Store
+}
+
+func New(entries repos.AuditRepository, cfg *runtimecfg.Store) *Service {
+	return &Service{Entries: entries, cfg: cfg}
+}
+
+// Record stores e with before and after marshaled as its JSON snapshots; either can be nil. It's
+// called after the change has already been made, so a failure is logged rather than returned.
+func (s *Service) Record(ctx context.Context, e *models.AuditEntry, before, after any) {
+	var err error
+	if e.Before, err = snapshot(before); err != nil {
+		slog.Warn("audit: marshaling before snapshot", "action", e.Action, "error", err.Error())
+	}
+	if e.After, err = snapshot(after); err != nil {
+		slog.Warn("audit: marshaling after snapshot", "action", e.Action, "error", err.Error())
+	}
+
+	// the change has happened whether or not the client is still waiting for the response
+	if _, err := s.Entries.Insert(context.WithoutCancel(ctx), e); err != nil {
+		slog.Error("audit: recording entry", "action", e.Action, "target_type", e.TargetType, "error", err.Error())
+	}
+}
+
+func (s *Service) List(ctx context.Context, f *models.AuditFilter) ([]*models.AuditEntry, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultPageSize
+	}
+	if f.Limit > maxPageSize {
+		f.Limit = maxPageSize
+	}
+
+	return s.Entries.List(ctx, f)
+}
+
+// StartCleanup deletes entries past the retention period now and every cleanupInterval until ctx
+// is done.
+func (s *Service) StartCleanup(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			s.cleanup(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	days := s.cfg.Load().AuditRetentionDays
+	if days <= 0 {
+		return
+	}
+
+	n, err := s.Entries.DeleteOlderThan(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		slog.Warn("audit: cleaning up old entries", "error", err.Error())
+		return
+	}
+	if n > 0 {
+		slog.Info("audit: cleaned up old entries", "deleted", n, "retention_days", days)
+	}
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling: %w", err)
+	}
+
+	// typed nil pointers get here as "null", which isn't worth storing
+	if string(b) == "null" {
+		return nil, nil
+	}
+
+	return b, nil
+}
@@ -0,0 +1,103 @@
+package authsvc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/thecoretg/ticketbot/internal/envelope"
+)
+
+// SecretsResult counts what EncryptSecrets changed.
+type SecretsResult struct {
+	Sealed    int `json:"sealed"`
+	Rewrapped int `json:"rewrapped"`
+	Failed    int `json:"failed"`
+}
+
+// totpAAD ties a sealed TOTP secret to its user, so it can't be copied onto another account.
+func totpAAD(userID int) string {
+	return "api_user.totp_secret:" + strconv.Itoa(userID)
+}
+
+func (s *Service) sealTOTPSecret(userID int, secret string) (string, error) {
+	sealed, err := s.keys.Seal([]byte(secret), totpAAD(userID))
+	if err != nil {
+		return "", fmt.Errorf("encrypting TOTP secret: %w", err)
+	}
+	return sealed, nil
+}
+
+// openTOTPSecret returns the usable secret from what's stored. Plaintext from before secrets were
+// encrypted is returned as is; EncryptSecrets seals it at startup.
+func (s *Service) openTOTPSecret(userID int, stored string) (string, error) {
+	if !envelope.IsSealed(stored) {
+		return stored, nil
+	}
+
+	b, err := s.keys.Open(stored, totpAAD(userID))
+	if err != nil {
+		return "", fmt.Errorf("decrypting TOTP secret: %w", err)
+	}
+	return string(b), nil
+}
+
+// EncryptSecrets seals any TOTP secrets still stored as plaintext. With rotate it also re-wraps
+// sealed secrets under the primary key, so keys listed after it can be retired afterwards.
+// Secrets that can't be processed are logged and counted, and the rest are still done.
+func (s *Service) EncryptSecrets(ctx context.Context, rotate bool) (SecretsResult, error) {
+	var res SecretsResult
+
+	stored, err := s.users.ListTOTPSecrets(ctx)
+	if err != nil {
+		return res, fmt.Errorf("listing TOTP secrets: %w", err)
+	}
+
+	for userID, old := range stored {
+		var next string
+		switch {
+		case !envelope.IsSealed(old):
+			next, err = s.sealTOTPSecret(userID, old)
+		case rotate:
+			var changed bool
+			next, changed, err = s.keys.Rewrap(old)
+			if err == nil && !changed {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			slog.Error("re-encrypting TOTP secret", "user_id", userID, "error", err.Error())
+			res.Failed++
+			continue
+		}
+
+		// a user who changed their secret meanwhile already has it sealed under the primary key
+		ok, err := s.users.ReplaceTOTPSecret(ctx, userID, old, next)
+		if err != nil {
+			return res, fmt.Errorf("storing TOTP secret for user %d: %w", userID, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if envelope.IsSealed(old) {
+			res.Rewrapped++
+		} else {
+			res.Sealed++
+		}
+	}
+
+	if res.Sealed > 0 || res.Rewrapped > 0 {
+		slog.Info("encrypted stored secrets", "key_id", s.keys.PrimaryID(), "sealed", res.Sealed, "rewrapped", res.Rewrapped)
+	}
+
+	if res.Failed > 0 {
+		return res, fmt.Errorf("%d secrets couldn't be re-encrypted", res.Failed)
+	}
+
+	return res, nil
+}
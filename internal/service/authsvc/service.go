@@ -10,13 +10,14 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/thecoretg/ticketbot/internal/envelope"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
 	"github.com/thecoretg/ticketbot/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	sessionDuration     = 24 * time.Hour
 	totpPendingDuration = 5 * time.Minute
 	recoveryCodeCount   = 10
 )
@@ -26,6 +27,9 @@ var (
 	ErrNoPassword         = errors.New("user has no password set")
 	ErrWeakPassword       = errors.New("password must be at least 8 characters and include an uppercase letter, lowercase letter, and number")
 	ErrInvalidTOTPCode    = errors.New("invalid or expired TOTP code")
+	// ErrOwnerTOTPRequired is returned for API keys whose owner hasn't set up TOTP while
+	// require_totp and require_totp_for_api_keys are both on.
+	ErrOwnerTOTPRequired = errors.New("api key owner must set up two-factor authentication")
 )
 
 // LoginResult is returned by Login. When TOTPRequired is true the caller must
@@ -68,31 +72,42 @@ type Service struct {
 	sessions     repos.SessionRepository
 	totpPending  repos.TOTPPendingRepository
 	totpRecovery repos.TOTPRecoveryRepository
-	cfg          *models.Config
+	throttle     repos.LoginThrottleRepository
+	sso          SSOPolicy
+	keys         *envelope.Keyring
+	cfg          *runtimecfg.Store
 }
 
-func New(users repos.APIUserRepository, sessions repos.SessionRepository, totpPending repos.TOTPPendingRepository, totpRecovery repos.TOTPRecoveryRepository, cfg *models.Config) *Service {
-	return &Service{users: users, sessions: sessions, totpPending: totpPending, totpRecovery: totpRecovery, cfg: cfg}
+// New returns the auth service. keys encrypts TOTP secrets at rest.
+func New(users repos.APIUserRepository, sessions repos.SessionRepository, totpPending repos.TOTPPendingRepository, totpRecovery repos.TOTPRecoveryRepository, throttle repos.LoginThrottleRepository, sso SSOPolicy, keys *envelope.Keyring, cfg *runtimecfg.Store) *Service {
+	return &Service{users: users, sessions: sessions, totpPending: totpPending, totpRecovery: totpRecovery, throttle: throttle, sso: sso, keys: keys, cfg: cfg}
 }
 
 // Login validates credentials. If the user has TOTP enabled it returns a
 // short-lived pending token that must be exchanged via VerifyTOTP; otherwise
 // it creates a full session and returns the session token.
-func (s *Service) Login(ctx context.Context, email, password string) (LoginResult, error) {
+// Failed attempts are throttled per account and client IP; see ThrottleError.
+func (s *Service) Login(ctx context.Context, email, password string, client models.SessionClient) (LoginResult, error) {
+	a, err := s.beginAttempt(ctx, throttleKeys(email, client.IP))
+	if err != nil {
+		return LoginResult{}, err
+	}
+	defer s.releaseAttempt(ctx, a)
+
 	u, err := s.users.GetForAuth(ctx, email)
 	if err != nil {
 		if errors.Is(err, models.ErrAPIUserNotFound) {
-			return LoginResult{}, ErrInvalidCredentials
+			return LoginResult{}, s.attemptFailed(ctx, a, nil, client.IP, ErrInvalidCredentials)
 		}
 		return LoginResult{}, fmt.Errorf("looking up user: %w", err)
 	}
 
 	if len(u.PasswordHash) == 0 {
-		return LoginResult{}, ErrNoPassword
+		return LoginResult{}, s.attemptFailed(ctx, a, &u.ID, client.IP, ErrNoPassword)
 	}
 
 	if err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)); err != nil {
-		return LoginResult{}, ErrInvalidCredentials
+		return LoginResult{}, s.attemptFailed(ctx, a, &u.ID, client.IP, ErrInvalidCredentials)
 	}
 
 	if u.TOTPEnabled {
@@ -113,21 +128,13 @@ func (s *Service) Login(ctx context.Context, email, password string) (LoginResul
 		return LoginResult{TOTPRequired: true, PendingToken: pendingToken}, nil
 	}
 
-	token, hash, err := generateToken()
-	if err != nil {
-		return LoginResult{}, fmt.Errorf("generating session token: %w", err)
-	}
-
-	_, err = s.sessions.Create(ctx, &models.Session{
-		UserID:    u.ID,
-		TokenHash: hash,
-		ExpiresAt: time.Now().Add(sessionDuration),
-	})
+	token, err := s.createSession(ctx, u.ID, models.SessionAuthPassword, client)
 	if err != nil {
-		return LoginResult{}, fmt.Errorf("creating session: %w", err)
+		return LoginResult{}, err
 	}
+	s.attemptSucceeded(ctx, a, u.EmailAddress)
 
-	totpSetupRequired := s.cfg.RequireTOTP && !u.TOTPEnabled
+	totpSetupRequired := s.cfg.Load().RequireTOTP && !u.TOTPEnabled
 	return LoginResult{Token: token, ResetRequired: u.ResetRequired, TOTPSetupRequired: totpSetupRequired}, nil
 }
 
@@ -166,15 +173,52 @@ func (s *Service) ChangePassword(ctx context.Context, userID int, currentPwd, ne
 	return nil
 }
 
-// ValidateToken looks up a session by the token from a cookie and returns the user ID.
-func (s *Service) ValidateToken(ctx context.Context, token string) (int, error) {
+// ValidateToken looks up a session by the token from a cookie and slides its expiry forward.
+func (s *Service) ValidateToken(ctx context.Context, token string, client models.SessionClient) (*models.Session, error) {
 	hash := hashToken(token)
 	session, err := s.sessions.GetByTokenHash(ctx, hash)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	s.touchSession(ctx, session, client)
+	return session, nil
+}
+
+// SessionAccess returns the session user's role and any setup they have to finish before the
+// session can be used for anything else. SSO sessions never need a password reset, and only need
+// TOTP set up if the config doesn't trust the identity provider's second factor.
+func (s *Service) SessionAccess(ctx context.Context, session *models.Session) (models.Role, models.Restriction, error) {
+	u, err := s.users.GetForAuthByID(ctx, session.UserID)
+	if err != nil {
+		return "", models.RestrictionNone, err
+	}
+
+	cfg := s.cfg.Load()
+	sso := session.AuthMethod == models.SessionAuthSSO
+	switch {
+	case u.ResetRequired && !sso:
+		return u.Role, models.RestrictionPasswordReset, nil
+	case cfg.RequireTOTP && !u.TOTPEnabled && !(sso && cfg.SSOSatisfiesTOTP):
+		return u.Role, models.RestrictionTOTPSetup, nil
+	}
+
+	return u.Role, models.RestrictionNone, nil
+}
+
+// KeyOwnerRole returns the role of an API key's owner. It returns ErrOwnerTOTPRequired if the
+// owner hasn't set up TOTP and the config requires it for API keys.
+func (s *Service) KeyOwnerRole(ctx context.Context, userID int) (models.Role, error) {
+	u, err := s.users.GetForAuthByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg := s.cfg.Load(); cfg.RequireTOTP && cfg.RequireTOTPForAPIKeys && !u.TOTPEnabled {
+		return u.Role, ErrOwnerTOTPRequired
 	}
 
-	return session.UserID, nil
+	return u.Role, nil
 }
 
 // Logout deletes the session for the given token.
@@ -0,0 +1,167 @@
+package authsvc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	// touchInterval limits how often a session's last-seen time and expiry are written, so a
+	// panel polling the API doesn't cause a write on every request.
+	touchInterval   = time.Minute
+	janitorInterval = 15 * time.Minute
+)
+
+// SessionMaxAge is the longest a session can last, used as the cookie's max age. The server
+// enforces the shorter idle timeout on its own.
+func (s *Service) SessionMaxAge() time.Duration {
+	return time.Duration(s.cfg.Load().SessionMaxLifetimeHours) * time.Hour
+}
+
+func (s *Service) idleTimeout() time.Duration {
+	return time.Duration(s.cfg.Load().SessionIdleTimeoutHours) * time.Hour
+}
+
+// createSession starts a session for the user. method is one of the models.SessionAuth values.
+func (s *Service) createSession(ctx context.Context, userID int, method string, client models.SessionClient) (string, error) {
+	token, hash, err := generateToken()
+	if err != nil {
+		return "", fmt.Errorf("generating session token: %w", err)
+	}
+
+	now := time.Now()
+	absolute := now.Add(s.SessionMaxAge())
+	_, err = s.sessions.Create(ctx, &models.Session{
+		UserID:            userID,
+		TokenHash:         hash,
+		ExpiresAt:         minTime(now.Add(s.idleTimeout()), absolute),
+		AbsoluteExpiresAt: absolute,
+		IP:                client.IP,
+		UserAgent:         client.UserAgent,
+		AuthMethod:        method,
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating session: %w", err)
+	}
+
+	return token, nil
+}
+
+// touchSession records activity on a session and pushes its expiry out by the idle timeout,
+// never past its absolute expiry. Failures are logged rather than failing the request.
+func (s *Service) touchSession(ctx context.Context, session *models.Session, client models.SessionClient) {
+	now := time.Now()
+	if now.Sub(session.LastSeenOn) < touchInterval && session.IP == client.IP && session.UserAgent == client.UserAgent {
+		return
+	}
+
+	expiresAt := minTime(now.Add(s.idleTimeout()), session.AbsoluteExpiresAt)
+	if err := s.sessions.Touch(ctx, session.ID, expiresAt, client); err != nil {
+		slog.Warn("touching session", "session_id", session.ID, "error", err.Error())
+		return
+	}
+
+	session.LastSeenOn = now
+	session.ExpiresAt = expiresAt
+	session.IP = client.IP
+	session.UserAgent = client.UserAgent
+}
+
+// ListSessions returns the user's active sessions, most recently used first. currentID marks
+// the session making the request, if any.
+func (s *Service) ListSessions(ctx context.Context, userID int, currentID *int) ([]models.SessionInfo, error) {
+	sessions, err := s.sessions.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	info := make([]models.SessionInfo, 0, len(sessions))
+	for _, ss := range sessions {
+		info = append(info, models.SessionInfo{
+			ID:         ss.ID,
+			CreatedOn:  ss.CreatedOn,
+			LastSeenOn: ss.LastSeenOn,
+			ExpiresAt:  ss.ExpiresAt,
+			IP:         ss.IP,
+			UserAgent:  ss.UserAgent,
+			AuthMethod: ss.AuthMethod,
+			Current:    currentID != nil && *currentID == ss.ID,
+		})
+	}
+
+	return info, nil
+}
+
+// RevokeSession ends one of the user's sessions. It returns models.ErrSessionNotFound if the
+// session doesn't exist or belongs to someone else.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	return s.sessions.DeleteForUser(ctx, sessionID, userID)
+}
+
+// RevokeOtherSessions ends all of the user's sessions except keepID, or all of them if keepID
+// is nil, and returns how many were ended.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID int, keepID *int) (int64, error) {
+	n, err := s.sessions.DeleteAllForUser(ctx, userID, keepID)
+	if err != nil {
+		return 0, fmt.Errorf("revoking sessions: %w", err)
+	}
+
+	return n, nil
+}
+
+// RevokeAllSessions signs a user out everywhere.
+func (s *Service) RevokeAllSessions(ctx context.Context, userID int) (int64, error) {
+	return s.RevokeOtherSessions(ctx, userID, nil)
+}
+
+// StartJanitor deletes expired sessions, TOTP pending tokens and sign-in failure counts now and
+// every janitorInterval until ctx is done.
+func (s *Service) StartJanitor(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(janitorInterval)
+		defer ticker.Stop()
+
+		for {
+			s.purgeExpired(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *Service) purgeExpired(ctx context.Context) {
+	if err := s.sessions.DeleteExpired(ctx); err != nil {
+		slog.Warn("auth: deleting expired sessions", "error", err.Error())
+	}
+
+	if err := s.totpPending.DeleteExpired(ctx); err != nil {
+		slog.Warn("auth: deleting expired totp pending tokens", "error", err.Error())
+	}
+
+	// rows locked out recently are kept so the next lockout can escalate from their count
+	now := time.Now()
+	if err := s.throttle.DeleteStale(ctx, now.Add(-failureWindow), now.Add(-lockoutMemory)); err != nil {
+		slog.Warn("auth: deleting stale sign-in failures", "error", err.Error())
+	}
+
+	// lockout events are kept as long as the audit log
+	if days := s.cfg.Load().AuditRetentionDays; days > 0 {
+		if err := s.throttle.DeleteLockoutsBefore(ctx, time.Now().AddDate(0, 0, -days)); err != nil {
+			slog.Warn("auth: deleting old lockout events", "error", err.Error())
+		}
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
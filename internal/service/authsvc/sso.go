@@ -0,0 +1,199 @@
+package authsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// ErrSSONotAllowed is returned when someone signs in through the identity provider but has no
+// user here and can't be provisioned.
+var ErrSSONotAllowed = errors.New("no ticketbot user for this account")
+
+// ErrSSOLinkedElsewhere is returned when the identity provider's email matches a user who is
+// already linked to a different account at the same provider.
+var ErrSSOLinkedElsewhere = errors.New("user is linked to a different identity provider account")
+
+// SSOIdentity is who the identity provider says signed in. Issuer and Subject identify the
+// account for good; Email is only used to link it to an existing user on first sign-in, and must
+// have been verified by the provider.
+type SSOIdentity struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Groups  []string
+}
+
+// SSOPolicy controls what happens when someone signs in through the identity provider.
+type SSOPolicy struct {
+	// AutoProvision creates a user on first sign-in if their email domain is in AllowedDomains.
+	AutoProvision  bool
+	AllowedDomains []string
+	// GroupRoles maps identity provider groups to roles. Users in any mapped group get the
+	// highest role among their groups on every sign-in; users in none keep their current role,
+	// or get DefaultRole when provisioned.
+	GroupRoles  map[string]models.Role
+	DefaultRole models.Role
+}
+
+// SSOResult is returned by LoginSSO. Previous is the user before sign-in, and is nil if they
+// were provisioned by it.
+type SSOResult struct {
+	Token    string
+	User     *models.APIUser
+	Previous *models.APIUser
+}
+
+// ParseGroupRoles parses a group-to-role mapping written as "group=role,group=role".
+func ParseGroupRoles(s string) (map[string]models.Role, error) {
+	m := make(map[string]models.Role)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		group, role, ok := strings.Cut(pair, "=")
+		group = strings.TrimSpace(group)
+		r := models.Role(strings.TrimSpace(role))
+		if !ok || group == "" || !r.Valid() {
+			return nil, fmt.Errorf("%w: %q is not a group=role pair", models.ErrInvalidRole, pair)
+		}
+
+		m[group] = r
+	}
+
+	return m, nil
+}
+
+func (p SSOPolicy) domainAllowed(email string) bool {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return false
+	}
+
+	for _, d := range p.AllowedDomains {
+		if strings.EqualFold(strings.TrimPrefix(d, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupRole returns the highest role mapped from groups, or "" if none are mapped.
+func (p SSOPolicy) groupRole(groups []string) models.Role {
+	var best models.Role
+	for _, g := range groups {
+		r, ok := p.GroupRoles[g]
+		if !ok {
+			continue
+		}
+		if best == "" || !best.AtLeast(r) {
+			best = r
+		}
+	}
+	return best
+}
+
+// LoginSSO signs in a user the identity provider has vouched for, provisioning them or updating
+// their role from groups as the policy allows, and returns a new session. Users are found by their
+// link to the provider account, or on first sign-in by email, after which they're linked. Local
+// TOTP isn't asked for; unless the config trusts the identity provider's second factor, a user
+// without TOTP has to set it up before the session can be used. Account and IP lockouts from
+// failed password sign-ins still apply.
+func (s *Service) LoginSSO(ctx context.Context, id SSOIdentity, client models.SessionClient) (*SSOResult, error) {
+	u, err := s.users.GetBySSOIdentity(ctx, id.Issuer, id.Subject)
+	if err != nil && !errors.Is(err, models.ErrAPIUserNotFound) {
+		return nil, fmt.Errorf("looking up linked user: %w", err)
+	}
+
+	link := u == nil
+	if link {
+		u, err = s.findSSOUser(ctx, id.Email)
+		if err != nil && !errors.Is(err, models.ErrAPIUserNotFound) {
+			return nil, fmt.Errorf("looking up user: %w", err)
+		}
+
+		if u != nil {
+			linked, err := s.users.HasSSOIdentity(ctx, u.ID, id.Issuer)
+			if err != nil {
+				return nil, fmt.Errorf("checking user's identity provider link: %w", err)
+			}
+			if linked {
+				slog.Warn("sso: email matches a user linked to another provider account", "user_id", u.ID, "issuer", id.Issuer)
+				return nil, ErrSSOLinkedElsewhere
+			}
+		}
+	}
+
+	email := id.Email
+	if u != nil {
+		email = u.EmailAddress
+	}
+	if err := s.checkLockout(ctx, throttleKeys(email, client.IP)); err != nil {
+		return nil, err
+	}
+
+	res := &SSOResult{}
+	mapped := s.sso.groupRole(id.Groups)
+
+	if u == nil {
+		if !s.sso.AutoProvision || !s.sso.domainAllowed(id.Email) {
+			return nil, ErrSSONotAllowed
+		}
+
+		role := mapped
+		if role == "" {
+			role = s.sso.DefaultRole
+		}
+
+		u, err = s.users.Insert(ctx, strings.ToLower(id.Email), role)
+		if err != nil {
+			return nil, fmt.Errorf("provisioning user: %w", err)
+		}
+	} else {
+		prev := *u
+		res.Previous = &prev
+
+		if mapped != "" && mapped != u.Role {
+			u, err = s.users.SetRole(ctx, u.ID, mapped)
+			if err != nil {
+				return nil, fmt.Errorf("updating role from groups: %w", err)
+			}
+		}
+	}
+
+	if link {
+		if err := s.users.LinkSSOIdentity(ctx, u.ID, id.Issuer, id.Subject); err != nil {
+			return nil, fmt.Errorf("linking identity provider account: %w", err)
+		}
+		slog.Info("sso: linked identity provider account", "user_id", u.ID, "issuer", id.Issuer)
+	}
+
+	token, err := s.createSession(ctx, u.ID, models.SessionAuthSSO, client)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Token = token
+	res.User = u
+	return res, nil
+}
+
+// findSSOUser matches the email from the identity provider exactly, then lowercased, since
+// providers don't always preserve the case users were created with.
+func (s *Service) findSSOUser(ctx context.Context, email string) (*models.APIUser, error) {
+	u, err := s.users.GetByEmail(ctx, email)
+	if err == nil || !errors.Is(err, models.ErrAPIUserNotFound) {
+		return u, err
+	}
+
+	if lower := strings.ToLower(email); lower != email {
+		return s.users.GetByEmail(ctx, lower)
+	}
+	return nil, err
+}
@@ -0,0 +1,322 @@
+package authsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	// failureWindow is how long a failed attempt counts for. A failure after a quiet spell this
+	// long starts the count over.
+	failureWindow = time.Hour
+	maxFailDelay  = 30 * time.Second
+	lockoutListed = 50
+	// Each lockout that follows another within lockoutMemory of it ending lasts twice as long, up
+	// to maxLockout.
+	lockoutMemory = 24 * time.Hour
+	maxLockout    = 24 * time.Hour
+)
+
+// ErrThrottled matches any ThrottleError.
+var ErrThrottled = errors.New("too many failed sign-in attempts")
+
+// ThrottleError is returned by Login and VerifyTOTP when an account or client IP has to wait
+// before trying again. Locked is set for a lockout, as opposed to a progressive delay, and
+// Lockout is set when the attempt that returned it is the one that caused the lockout.
+type ThrottleError struct {
+	RetryAfter time.Duration
+	Locked     bool
+	Lockout    *models.LoginLockout
+}
+
+func (e *ThrottleError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("%s: locked for %s", ErrThrottled, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s: retry in %s", ErrThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottleError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// throttleKey is one thing failed attempts are counted against.
+type throttleKey struct {
+	scope   string
+	subject string
+}
+
+func accountKey(email string) throttleKey {
+	return throttleKey{scope: models.ThrottleScopeAccount, subject: strings.ToLower(strings.TrimSpace(email))}
+}
+
+// throttleKeys returns the keys for a sign-in attempt. Either can be empty: TOTP verification
+// with an unknown pending token has no account, and a request may have no client IP.
+func throttleKeys(email, ip string) []throttleKey {
+	var keys []throttleKey
+	if email != "" {
+		keys = append(keys, accountKey(email))
+	}
+	if ip != "" {
+		keys = append(keys, throttleKey{scope: models.ThrottleScopeIP, subject: ip})
+	}
+	return keys
+}
+
+func (s *Service) maxFailures(scope string) int {
+	if scope == models.ThrottleScopeIP {
+		return s.cfg.Load().LoginIPMaxFailures
+	}
+	return s.cfg.Load().LoginMaxFailures
+}
+
+func (s *Service) lockoutDuration() time.Duration {
+	return time.Duration(s.cfg.Load().LoginLockoutMinutes) * time.Minute
+}
+
+// freeFailures is how many failures in a row are allowed before attempts are slowed down.
+func (s *Service) freeFailures(scope string) int {
+	return max(s.maxFailures(scope)/3, 1)
+}
+
+// failDelay is how long to wait after the given number of recent failures. The first third of
+// the allowed failures are free, then the wait doubles with each one up to maxFailDelay. The
+// throttle repository applies the same delay when an attempt is claimed.
+func (s *Service) failDelay(scope string, failures int) time.Duration {
+	free := s.freeFailures(scope)
+	if failures < free {
+		return 0
+	}
+
+	n := failures - free
+	if n >= 5 {
+		return maxFailDelay
+	}
+	return time.Second << n
+}
+
+// attempt is a sign-in attempt counted against its throttle keys. It has to be settled with
+// attemptFailed or attemptSucceeded, or else released with releaseAttempt.
+type attempt struct {
+	keys     []throttleKey
+	failures []int
+	settled  bool
+}
+
+// beginAttempt counts an attempt against keys before the credentials are checked, so parallel
+// attempts can't all get in before the first of them fails. It returns a ThrottleError if any of
+// keys is locked out, at its limit, or still waiting out the delay from its last attempt.
+func (s *Service) beginAttempt(ctx context.Context, keys []throttleKey) (*attempt, error) {
+	windowStart := time.Now().Add(-failureWindow)
+	a := &attempt{}
+	for _, k := range keys {
+		t, err := s.throttle.Attempt(ctx, k.scope, k.subject, windowStart, s.maxFailures(k.scope), s.freeFailures(k.scope), maxFailDelay)
+		if err != nil {
+			s.releaseAttempt(ctx, a)
+			if errors.Is(err, models.ErrLoginAttemptRefused) {
+				return nil, s.refused(ctx, k)
+			}
+			return nil, fmt.Errorf("checking login throttle: %w", err)
+		}
+
+		a.keys = append(a.keys, k)
+		a.failures = append(a.failures, t.Failures)
+	}
+
+	return a, nil
+}
+
+// refused returns the ThrottleError for a key that refused an attempt.
+func (s *Service) refused(ctx context.Context, k throttleKey) error {
+	t, err := s.throttle.Get(ctx, k.scope, k.subject)
+	if err != nil {
+		if errors.Is(err, models.ErrLoginThrottleNotFound) {
+			// cleared since it refused
+			return &ThrottleError{RetryAfter: time.Second}
+		}
+		return fmt.Errorf("checking login throttle: %w", err)
+	}
+
+	now := time.Now()
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return &ThrottleError{RetryAfter: t.LockedUntil.Sub(now), Locked: true}
+	}
+
+	if next := t.LastFailedOn.Add(s.failDelay(k.scope, t.Failures)); now.Before(next) {
+		return &ThrottleError{RetryAfter: next.Sub(now)}
+	}
+
+	// at the limit, with the attempt that reached it still being checked
+	return &ThrottleError{RetryAfter: maxFailDelay}
+}
+
+// checkLockout returns a ThrottleError if any of keys is locked out. It's for sign-ins that can't
+// be guessed, like SSO, which the delay after a failed password doesn't apply to.
+func (s *Service) checkLockout(ctx context.Context, keys []throttleKey) error {
+	now := time.Now()
+	for _, k := range keys {
+		t, err := s.throttle.Get(ctx, k.scope, k.subject)
+		if err != nil {
+			if errors.Is(err, models.ErrLoginThrottleNotFound) {
+				continue
+			}
+			return fmt.Errorf("checking login throttle: %w", err)
+		}
+
+		if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+			return &ThrottleError{RetryAfter: t.LockedUntil.Sub(now), Locked: true}
+		}
+	}
+
+	return nil
+}
+
+// attemptFailed settles a as failed and returns the error for the caller to return: a
+// ThrottleError if this attempt caused a lockout, otherwise cause. Problems locking out are logged
+// rather than hiding cause.
+func (s *Service) attemptFailed(ctx context.Context, a *attempt, userID *int, ip string, cause error) error {
+	a.settled = true
+
+	var lockErr error
+	for i, k := range a.keys {
+		if a.failures[i] < s.maxFailures(k.scope) {
+			continue
+		}
+
+		l, err := s.lockOut(ctx, k, a.failures[i], userID, ip)
+		if err != nil {
+			slog.Error("locking out after failed sign-ins", "scope", k.scope, "error", err.Error())
+			continue
+		}
+
+		if lockErr == nil {
+			lockErr = &ThrottleError{RetryAfter: time.Until(l.LockedUntil), Locked: true, Lockout: l}
+		}
+	}
+
+	if lockErr != nil {
+		return lockErr
+	}
+	return cause
+}
+
+func (s *Service) lockOut(ctx context.Context, k throttleKey, failures int, userID *int, ip string) (*models.LoginLockout, error) {
+	t, err := s.throttle.Lock(ctx, k.scope, k.subject, s.lockoutDuration(), maxLockout, time.Now().Add(-lockoutMemory))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &models.LoginLockout{
+		Scope:       k.scope,
+		Subject:     k.subject,
+		IP:          ip,
+		Failures:    failures,
+		LockedUntil: *t.LockedUntil,
+	}
+	if k.scope == models.ThrottleScopeAccount {
+		l.UserID = userID
+	}
+
+	l, err = s.throttle.CreateLockout(ctx, l)
+	if err != nil {
+		return nil, fmt.Errorf("recording lockout: %w", err)
+	}
+
+	slog.Warn("sign-in locked out after repeated failures", "scope", k.scope, "subject", k.subject, "ip", ip, "failures", failures, "lockouts", t.Lockouts, "until", l.LockedUntil)
+	return l, nil
+}
+
+// attemptSucceeded settles a after a complete sign-in, clearing the account's failure count and
+// any lockout history. The IP only has this attempt uncounted, so one good account can't be used
+// to reset it.
+func (s *Service) attemptSucceeded(ctx context.Context, a *attempt, email string) {
+	a.settled = true
+
+	account := accountKey(email)
+	if _, err := s.throttle.Clear(ctx, account.scope, account.subject); err != nil {
+		slog.Warn("clearing sign-in failures", "error", err.Error())
+	}
+
+	for _, k := range a.keys {
+		if k == account {
+			continue
+		}
+		if err := s.throttle.Release(ctx, k.scope, k.subject); err != nil {
+			slog.Warn("uncounting sign-in attempt", "scope", k.scope, "error", err.Error())
+		}
+	}
+}
+
+// releaseAttempt uncounts a if it hasn't been settled, for attempts that ended without the
+// credentials being found wrong, like a correct password waiting on TOTP or a database error. It's
+// meant to be deferred right after beginAttempt.
+func (s *Service) releaseAttempt(ctx context.Context, a *attempt) {
+	if a == nil || a.settled {
+		return
+	}
+	a.settled = true
+
+	ctx = context.WithoutCancel(ctx)
+	for _, k := range a.keys {
+		if err := s.throttle.Release(ctx, k.scope, k.subject); err != nil {
+			slog.Warn("uncounting sign-in attempt", "scope", k.scope, "error", err.Error())
+		}
+	}
+}
+
+// Lockouts returns the accounts and IPs locked out right now along with recent lockout events.
+func (s *Service) Lockouts(ctx context.Context) (*models.LockoutReport, error) {
+	active, err := s.throttle.ListLocked(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing active lockouts: %w", err)
+	}
+
+	recent, err := s.throttle.ListLockouts(ctx, lockoutListed)
+	if err != nil {
+		return nil, fmt.Errorf("listing lockout events: %w", err)
+	}
+
+	r := &models.LockoutReport{Active: []models.LoginThrottle{}, Recent: []models.LoginLockout{}}
+	for _, t := range active {
+		r.Active = append(r.Active, *t)
+	}
+	for _, l := range recent {
+		r.Recent = append(r.Recent, *l)
+	}
+
+	return r, nil
+}
+
+// UnlockUser clears a user's lockout and failed attempt count. It reports whether there was
+// anything to clear.
+func (s *Service) UnlockUser(ctx context.Context, userID int) (bool, error) {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	k := accountKey(u.EmailAddress)
+	cleared, err := s.throttle.Clear(ctx, k.scope, k.subject)
+	if err != nil {
+		return false, fmt.Errorf("clearing lockout: %w", err)
+	}
+
+	return cleared, nil
+}
+
+// UnlockIP clears a client IP's lockout and failed attempt count. It reports whether there was
+// anything to clear.
+func (s *Service) UnlockIP(ctx context.Context, ip string) (bool, error) {
+	cleared, err := s.throttle.Clear(ctx, models.ThrottleScopeIP, ip)
+	if err != nil {
+		return false, fmt.Errorf("clearing lockout: %w", err)
+	}
+
+	return cleared, nil
+}
@@ -42,8 +42,10 @@ func (s *Service) BeginSetup(ctx context.Context, userID int) (secret, otpauthUR
 }
 
 // ConfirmSetup validates the user's password and a TOTP code against the
-// provided secret (from BeginSetup). On success it enables TOTP, stores the
-// secret, generates fresh recovery codes, and returns them (shown once).
+// provided secret (from BeginSetup). Users who only sign in with SSO have no
+// password, so only the code is checked for them. On success it enables TOTP,
+// stores the secret encrypted, generates fresh recovery codes, and returns them
+// (shown once).
 func (s *Service) ConfirmSetup(ctx context.Context, userID int, password, code, secret string) ([]string, error) {
 	code = strings.ReplaceAll(code, " ", "")
 
@@ -52,9 +54,11 @@ func (s *Service) ConfirmSetup(ctx context.Context, userID int, password, code,
 		return nil, fmt.Errorf("looking up user: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)); err != nil {
-		slog.Error("totp confirm: password check failed", "user_id", userID)
-		return nil, ErrInvalidCredentials
+	if len(u.PasswordHash) > 0 {
+		if err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)); err != nil {
+			slog.Error("totp confirm: password check failed", "user_id", userID)
+			return nil, ErrInvalidCredentials
+		}
 	}
 
 	if !totp.Validate(code, secret) {
@@ -69,7 +73,12 @@ func (s *Service) ConfirmSetup(ctx context.Context, userID int, password, code,
 		return nil, ErrInvalidTOTPCode
 	}
 
-	if err := s.users.SetTOTPSecret(ctx, userID, &secret); err != nil {
+	sealed, err := s.sealTOTPSecret(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.users.SetTOTPSecret(ctx, userID, &sealed); err != nil {
 		return nil, fmt.Errorf("storing TOTP secret: %w", err)
 	}
 	if err := s.users.SetTOTPEnabled(ctx, userID, true); err != nil {
@@ -85,8 +94,9 @@ func (s *Service) ConfirmSetup(ctx context.Context, userID int, password, code,
 
 // VerifyTOTP validates a TOTP code (or recovery code) against a pending token
 // produced by Login. On success it deletes the pending token and creates a
-// real session.
-func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code string) (sessionToken string, resetRequired bool, recoveryCodeUsed bool, err error) {
+// real session. Wrong codes count as failed sign-ins against the account and
+// client IP, the same as wrong passwords.
+func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code string, client models.SessionClient) (sessionToken string, resetRequired bool, recoveryCodeUsed bool, err error) {
 	// Strip any spaces (some authenticator apps display codes as "123 456").
 	code = strings.ReplaceAll(code, " ", "")
 
@@ -94,7 +104,11 @@ func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code string) (se
 	pending, err := s.totpPending.GetByTokenHash(ctx, tokenHash)
 	if err != nil {
 		slog.Error("totp verify: pending token not found", "err", err)
-		return "", false, false, ErrInvalidCredentials
+		a, err := s.beginAttempt(ctx, throttleKeys("", client.IP))
+		if err != nil {
+			return "", false, false, err
+		}
+		return "", false, false, s.attemptFailed(ctx, a, nil, client.IP, ErrInvalidCredentials)
 	}
 
 	u, err := s.users.GetForAuthByID(ctx, pending.UserID)
@@ -102,19 +116,30 @@ func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code string) (se
 		return "", false, false, fmt.Errorf("looking up user: %w", err)
 	}
 
+	a, err := s.beginAttempt(ctx, throttleKeys(u.EmailAddress, client.IP))
+	if err != nil {
+		return "", false, false, err
+	}
+	defer s.releaseAttempt(ctx, a)
+
 	if !u.TOTPEnabled || u.TOTPSecret == nil {
 		slog.Error("totp verify: totp not configured on account", "user_id", pending.UserID, "enabled", u.TOTPEnabled, "has_secret", u.TOTPSecret != nil)
 		return "", false, false, ErrInvalidCredentials
 	}
 
-	if !totp.Validate(code, *u.TOTPSecret) {
+	secret, err := s.openTOTPSecret(u.ID, *u.TOTPSecret)
+	if err != nil {
+		return "", false, false, err
+	}
+
+	if !totp.Validate(code, secret) {
 		slog.Error("totp verify: TOTP code invalid, trying recovery code", "user_id", pending.UserID)
 		// Try as a recovery code.
 		codeHash := sha256Code(code)
 		rc, err := s.totpRecovery.GetUnusedByHash(ctx, pending.UserID, codeHash)
 		if err != nil {
 			slog.Error("totp verify: recovery code not found either", "user_id", pending.UserID)
-			return "", false, false, ErrInvalidCredentials
+			return "", false, false, s.attemptFailed(ctx, a, &u.ID, client.IP, ErrInvalidCredentials)
 		}
 		if err := s.totpRecovery.MarkUsed(ctx, rc.ID); err != nil {
 			return "", false, false, fmt.Errorf("marking recovery code used: %w", err)
@@ -126,19 +151,11 @@ func (s *Service) VerifyTOTP(ctx context.Context, pendingToken, code string) (se
 		return "", false, false, fmt.Errorf("deleting pending token: %w", err)
 	}
 
-	token, hash, err := generateToken()
-	if err != nil {
-		return "", false, false, fmt.Errorf("generating session token: %w", err)
-	}
-
-	_, err = s.sessions.Create(ctx, &models.Session{
-		UserID:    pending.UserID,
-		TokenHash: hash,
-		ExpiresAt: time.Now().Add(sessionDuration),
-	})
+	token, err := s.createSession(ctx, pending.UserID, models.SessionAuthPassword, client)
 	if err != nil {
-		return "", false, false, fmt.Errorf("creating session: %w", err)
+		return "", false, false, err
 	}
+	s.attemptSucceeded(ctx, a, u.EmailAddress)
 
 	return token, u.ResetRequired, recoveryCodeUsed, nil
 }
@@ -8,11 +8,16 @@ import (
 	"github.com/thecoretg/ticketbot/models"
 )
 
+// ensureConfig publishes and returns the stored config, creating the default if there is none.
 func (s *Service) ensureConfig(ctx context.Context) (*models.Config, error) {
+	// a read racing a save could otherwise publish the config from before it
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
 	c, err := s.Config.Get(ctx)
 	if err == nil {
-		s.applyChanges(c)
-		return s.ConfigRef, nil
+		s.Store.Publish(c)
+		return s.Store.Load(), nil
 	}
 
 	if !errors.Is(err, models.ErrConfigNotFound) {
@@ -25,6 +30,6 @@ func (s *Service) ensureConfig(ctx context.Context) (*models.Config, error) {
 		return nil, fmt.Errorf("creating default config: %w", err)
 	}
 
-	s.applyChanges(c)
-	return s.ConfigRef, nil
+	s.Store.Publish(c)
+	return s.Store.Load(), nil
 }
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 200
+)
+
+// save builds the next config from the stored one with change, then stores it with a revision
+// recording author, and applies it. The stored config stays locked in between, so a save by
+// another instance can't be lost.
+func (s *Service) save(ctx context.Context, author *models.Actor, rollbackOf *int, change func(current *models.Config) (*models.Config, error)) (*models.Config, error) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	current, err := s.lockConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := change(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(next); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.saveTx(ctx, tx, current, next, author, rollbackOf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing tx: %w", err)
+	}
+
+	s.Store.Publish(updated)
+	return s.Store.Load(), nil
+}
+
+// lockConfig reads the stored config in tx and locks it until tx finishes, creating the default
+// if there isn't one yet.
+func (s *Service) lockConfig(ctx context.Context, tx pgx.Tx) (*models.Config, error) {
+	cfgs := s.Config.WithTx(tx)
+	c, err := cfgs.GetForUpdate(ctx)
+	if err == nil {
+		return c, nil
+	}
+	if !errors.Is(err, models.ErrConfigNotFound) {
+		return nil, fmt.Errorf("locking config: %w", err)
+	}
+
+	// the insert's conflict update locks the row too, if another instance got there first
+	c, err = cfgs.InsertDefault(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating default config: %w", err)
+	}
+
+	return c, nil
+}
+
+// saveTx is the part of save done in tx. The caller holds saveMu and the lock on prev, and
+// publishes the result once tx has committed.
+func (s *Service) saveTx(ctx context.Context, tx pgx.Tx, prev, next *models.Config, author *models.Actor, rollbackOf *int) (*models.Config, error) {
+	revs := s.Revisions.WithTx(tx)
+	if _, err := revs.Latest(ctx); err != nil {
+		if !errors.Is(err, models.ErrConfigRevisionNotFound) {
+			return nil, fmt.Errorf("getting latest revision: %w", err)
+		}
+		if _, err := insertRevision(ctx, revs, prev, nil, nil); err != nil {
+			return nil, fmt.Errorf("recording baseline revision: %w", err)
+		}
+	}
+
+	updated, err := s.Config.WithTx(tx).Upsert(ctx, next)
+	if err != nil {
+		return nil, fmt.Errorf("upserting config in store: %w", err)
+	}
+
+	if _, err := insertRevision(ctx, revs, updated, author, rollbackOf); err != nil {
+		return nil, fmt.Errorf("recording revision: %w", err)
+	}
+
+	if err := s.Config.WithTx(tx).NotifyChanged(ctx, s.instanceID); err != nil {
+		return nil, fmt.Errorf("notifying other instances: %w", err)
+	}
+
+	return updated, nil
+}
+
+func insertRevision(ctx context.Context, revs repos.ConfigRevisionRepository, cfg *models.Config, author *models.Actor, rollbackOf *int) (*models.ConfigRevision, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+
+	r := &models.ConfigRevision{Config: b, RollbackOf: rollbackOf}
+	if author != nil {
+		if author.UserID != 0 {
+			r.AuthorUserID = &author.UserID
+		}
+		r.AuthorKeyID = author.APIKeyID
+	}
+
+	return revs.Insert(ctx, r)
+}
+
+// Rollback saves the config as it was at revision, as a new revision by author. Fields added
+// since that revision keep their current values.
+func (s *Service) Rollback(ctx context.Context, revision int, author *models.Actor) (*models.Config, error) {
+	rev, err := s.Revisions.Get(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.save(ctx, author, &rev.Revision, func(current *models.Config) (*models.Config, error) {
+		restored, err := overlay(current, rev.Config)
+		if err != nil {
+			return nil, fmt.Errorf("decoding revision %d: %w", revision, err)
+		}
+		return restored, nil
+	})
+}
+
+// Preview returns the config with the fields in raw decoded over the current one, and what that
+// would change. Nothing is saved; ReplaceTx saves the same fields over the config as it is then.
+func (s *Service) Preview(ctx context.Context, raw json.RawMessage) (*models.Config, []models.ConfigChange, error) {
+	current, err := s.ensureConfig(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting current config: %w", err)
+	}
+
+	next, err := overlay(current, raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", models.ErrInvalidConfig, err)
+	}
+
+	if err := validate(next); err != nil {
+		return nil, nil, err
+	}
+
+	from, err := json.Marshal(current)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling current config: %w", err)
+	}
+	to, err := json.Marshal(next)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling new config: %w", err)
+	}
+
+	changes, err := diffConfigs(from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("diffing config: %w", err)
+	}
+
+	return next, changes, nil
+}
+
+// ReplaceTx decodes the fields in raw over the stored config, as Preview does, and saves the
+// result as a new revision by author, in tx, so it commits or rolls back along with the caller's
+// other changes. Saves wait until the returned done is called, which the caller must do once tx
+// has finished, saying whether it committed; only then is the config published.
+func (s *Service) ReplaceTx(ctx context.Context, tx pgx.Tx, raw json.RawMessage, author *models.Actor) (done func(committed bool), err error) {
+	s.saveMu.Lock()
+	updated, err := s.replaceTx(ctx, tx, raw, author)
+	if err != nil {
+		s.saveMu.Unlock()
+		return nil, err
+	}
+
+	var once sync.Once
+	return func(committed bool) {
+		once.Do(func() {
+			if committed {
+				s.Store.Publish(updated)
+			}
+			s.saveMu.Unlock()
+		})
+	}, nil
+}
+
+func (s *Service) replaceTx(ctx context.Context, tx pgx.Tx, raw json.RawMessage, author *models.Actor) (*models.Config, error) {
+	current, err := s.lockConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := overlay(current, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidConfig, err)
+	}
+	if err := validate(next); err != nil {
+		return nil, err
+	}
+
+	return s.saveTx(ctx, tx, current, next, author, nil)
+}
+
+// overlay decodes raw over a copy of current. Fields raw leaves out keep their current values.
+func overlay(current *models.Config, raw json.RawMessage) (*models.Config, error) {
+	// current is a published snapshot, which decoding mustn't write through
+	next := current.Clone()
+	if err := json.Unmarshal(raw, next); err != nil {
+		return nil, err
+	}
+	next.ID = current.ID
+
+	return next, nil
+}
+
+// History returns revisions newest first, each with its changes from the one before it, and
+// whether there are older ones. cursor is the revision to start before, for paging.
+func (s *Service) History(ctx context.Context, cursor *int, limit int) ([]*models.ConfigRevision, bool, error) {
+	if limit <= 0 {
+		limit = defaultHistoryPageSize
+	}
+	limit = min(limit, maxHistoryPageSize)
+
+	// one extra so the oldest revision on the page can be diffed too
+	revs, err := s.Revisions.List(ctx, cursor, limit+1)
+	if err != nil {
+		return nil, false, fmt.Errorf("listing config revisions: %w", err)
+	}
+
+	for i, r := range revs {
+		r.Changes = []models.ConfigChange{}
+		if i+1 >= len(revs) {
+			break
+		}
+
+		if r.Changes, err = diffConfigs(revs[i+1].Config, r.Config); err != nil {
+			return nil, false, fmt.Errorf("diffing revision %d: %w", r.Revision, err)
+		}
+	}
+
+	more := len(revs) > limit
+	if more {
+		revs = revs[:limit]
+	}
+
+	return revs, more, nil
+}
+
+// diffConfigs compares two stored configs field by field. A field missing from one side, because
+// it was added in between, shows as null there.
+func diffConfigs(from, to json.RawMessage) ([]models.ConfigChange, error) {
+	var a, b map[string]any
+	if err := json.Unmarshal(from, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &b); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for k := range a {
+		fields = append(fields, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	slices.Sort(fields)
+
+	changes := []models.ConfigChange{}
+	for _, f := range fields {
+		if f == "id" || reflect.DeepEqual(a[f], b[f]) {
+			continue
+		}
+		changes = append(changes, models.ConfigChange{Field: f, From: a[f], To: b[f]})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// changeChannel is the channel NotifyAppConfigChanged sends on when a config change commits.
+	changeChannel = "app_config_changed"
+
+	minListenBackoff = time.Second
+	maxListenBackoff = time.Minute
+)
+
+// StartListener applies config changes saved by other instances as they're committed, using
+// Postgres LISTEN/NOTIFY on a connection of its own. It reconnects if the connection drops, and
+// reloads the config whenever it (re)connects in case a change was missed meanwhile.
+func (s *Service) StartListener(ctx context.Context) {
+	go func() {
+		backoff := minListenBackoff
+		for {
+			connected, err := s.listen(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if connected {
+				backoff = minListenBackoff
+			}
+			slog.Warn("config listener: lost connection, retrying", "error", err, "retry_in", backoff)
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, maxListenBackoff)
+		}
+	}()
+}
+
+// listen runs until the connection fails or ctx is done, reporting whether it got as far as
+// listening.
+func (s *Service) listen(ctx context.Context) (bool, error) {
+	pc, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquiring connection: %w", err)
+	}
+
+	// the connection stays subscribed, so it's taken out of the pool rather than returned to it
+	conn := pc.Hijack()
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+changeChannel); err != nil {
+		return false, fmt.Errorf("listening: %w", err)
+	}
+	slog.Debug("config listener: listening for changes from other instances")
+
+	if err := s.reload(ctx); err != nil {
+		slog.Warn("config listener: reloading config", "error", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		if n.Payload == s.instanceID {
+			continue
+		}
+
+		if err := s.reload(ctx); err != nil {
+			slog.Warn("config listener: reloading config", "error", err)
+			continue
+		}
+		slog.Info("config listener: applied config change from another instance")
+	}
+}
+
+// reload publishes the config as stored. saveMu stops it racing a save on this instance, which
+// would otherwise be able to publish an older config after a newer one.
+func (s *Service) reload(ctx context.Context) error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	c, err := s.Config.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.Store.Publish(c)
+	return nil
+}
@@ -2,29 +2,46 @@ package config
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/thecoretg/ticketbot/internal/logging"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
 	"github.com/thecoretg/ticketbot/models"
 )
 
 type Service struct {
 	Config    repos.ConfigRepository
-	ConfigRef *models.Config
+	Revisions repos.ConfigRevisionRepository
+	Store     *runtimecfg.Store
+	pool      *pgxpool.Pool
 	level     *slog.LevelVar
 	logBuf    *logging.BufferHandler
+
+	// instanceID tags the change notifications this instance sends, so it can skip its own.
+	instanceID string
+
+	// saveMu keeps revisions in the same order as the changes published to Store.
+	saveMu sync.Mutex
 }
 
-func New(c repos.ConfigRepository, cfg *models.Config, level *slog.LevelVar, logBuf *logging.BufferHandler) *Service {
+func New(pool *pgxpool.Pool, c repos.ConfigRepository, revs repos.ConfigRevisionRepository, store *runtimecfg.Store, level *slog.LevelVar, logBuf *logging.BufferHandler) *Service {
 	s := &Service{
-		Config:    c,
-		ConfigRef: cfg,
-		level:     level,
-		logBuf:    logBuf,
+		Config:     c,
+		Revisions:  revs,
+		Store:      store,
+		pool:       pool,
+		level:      level,
+		logBuf:     logBuf,
+		instanceID: rand.Text(),
 	}
-	s.applyChanges(cfg)
+	s.applyLogging(nil, store.Load())
+	store.Subscribe(s.applyLogging)
 	return s
 }
 
@@ -32,12 +49,15 @@ func (s *Service) Get(ctx context.Context) (*models.Config, error) {
 	return s.ensureConfig(ctx)
 }
 
-func (s *Service) Update(ctx context.Context, p *models.ConfigUpdateParams) (*models.Config, error) {
-	current, err := s.ensureConfig(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("getting current config: %w", err)
-	}
+// Update merges the set fields of p into the config and saves it as a new revision by author.
+func (s *Service) Update(ctx context.Context, p *models.ConfigUpdateParams, author *models.Actor) (*models.Config, error) {
+	return s.save(ctx, author, nil, func(current *models.Config) (*models.Config, error) {
+		return merge(current, p), nil
+	})
+}
 
+// merge returns current with the set fields of p applied.
+func merge(current *models.Config, p *models.ConfigUpdateParams) *models.Config {
 	merged := *current
 	if p.AttemptNotify != nil {
 		merged.AttemptNotify = *p.AttemptNotify
@@ -63,34 +83,114 @@ func (s *Service) Update(ctx context.Context, p *models.ConfigUpdateParams) (*mo
 	if p.LogBufferSize != nil {
 		merged.LogBufferSize = *p.LogBufferSize
 	}
-
-	updated, err := s.Config.Upsert(ctx, &merged)
-	if err != nil {
-		return nil, fmt.Errorf("upserting config in store: %w", err)
+	if p.SyncBoardsIntervalMinutes != nil {
+		merged.SyncBoardsIntervalMinutes = *p.SyncBoardsIntervalMinutes
+	}
+	if p.SyncRecipientsIntervalMinutes != nil {
+		merged.SyncRecipientsIntervalMinutes = *p.SyncRecipientsIntervalMinutes
+	}
+	if p.SyncOpenTicketsIntervalMinutes != nil {
+		merged.SyncOpenTicketsIntervalMinutes = *p.SyncOpenTicketsIntervalMinutes
+	}
+	if p.SyncTicketUpdatesIntervalMinutes != nil {
+		merged.SyncTicketUpdatesIntervalMinutes = *p.SyncTicketUpdatesIntervalMinutes
+	}
+	if p.HookCheckIntervalMinutes != nil {
+		merged.HookCheckIntervalMinutes = *p.HookCheckIntervalMinutes
+	}
+	if p.HookSilenceMinutes != nil {
+		merged.HookSilenceMinutes = *p.HookSilenceMinutes
+	}
+	if p.HookAlertRecipientID != nil {
+		merged.HookAlertRecipientID = p.HookAlertRecipientID
+		if *p.HookAlertRecipientID == 0 {
+			merged.HookAlertRecipientID = nil
+		}
+	}
+	if p.BusinessHoursStart != nil {
+		merged.BusinessHoursStart = *p.BusinessHoursStart
+	}
+	if p.BusinessHoursEnd != nil {
+		merged.BusinessHoursEnd = *p.BusinessHoursEnd
+	}
+	if p.BusinessTimezone != nil {
+		merged.BusinessTimezone = *p.BusinessTimezone
+	}
+	if p.CWRequestsPerMinute != nil {
+		merged.CWRequestsPerMinute = *p.CWRequestsPerMinute
+	}
+	if p.CWMaxConcurrentRequests != nil {
+		merged.CWMaxConcurrentRequests = *p.CWMaxConcurrentRequests
+	}
+	if p.AuditRetentionDays != nil {
+		merged.AuditRetentionDays = *p.AuditRetentionDays
+	}
+	if p.SessionIdleTimeoutHours != nil {
+		merged.SessionIdleTimeoutHours = *p.SessionIdleTimeoutHours
+	}
+	if p.SessionMaxLifetimeHours != nil {
+		merged.SessionMaxLifetimeHours = *p.SessionMaxLifetimeHours
+	}
+	if p.LoginMaxFailures != nil {
+		merged.LoginMaxFailures = *p.LoginMaxFailures
+	}
+	if p.LoginIPMaxFailures != nil {
+		merged.LoginIPMaxFailures = *p.LoginIPMaxFailures
+	}
+	if p.LoginLockoutMinutes != nil {
+		merged.LoginLockoutMinutes = *p.LoginLockoutMinutes
+	}
+	if p.RequireTOTPForAPIKeys != nil {
+		merged.RequireTOTPForAPIKeys = *p.RequireTOTPForAPIKeys
+	}
+	if p.SSOSatisfiesTOTP != nil {
+		merged.SSOSatisfiesTOTP = *p.SSOSatisfiesTOTP
 	}
 
-	s.applyChanges(updated)
-	return s.ConfigRef, nil
+	return &merged
 }
 
-func (s *Service) applyChanges(src *models.Config) {
-	cfg := s.ConfigRef
-	cfg.AttemptNotify = src.AttemptNotify
-	cfg.MaxConcurrentSyncs = src.MaxConcurrentSyncs
-	cfg.MaxMessageLength = src.MaxMessageLength
-	cfg.RequireTOTP = src.RequireTOTP
-	cfg.DebugLogging = src.DebugLogging
-	cfg.LogRetentionDays = src.LogRetentionDays
-	cfg.LogCleanupIntervalHours = src.LogCleanupIntervalHours
-	cfg.LogBufferSize = src.LogBufferSize
+func validate(c *models.Config) error {
+	if c.BusinessHoursStart < 0 || c.BusinessHoursEnd > 24 || c.BusinessHoursStart >= c.BusinessHoursEnd {
+		return fmt.Errorf("%w: business hours must be between 0 and 24, with the start before the end", models.ErrInvalidConfig)
+	}
+
+	if _, err := time.LoadLocation(c.BusinessTimezone); err != nil {
+		return fmt.Errorf("%w: business timezone: %w", models.ErrInvalidConfig, err)
+	}
+
+	if c.CWRequestsPerMinute < 0 || c.CWMaxConcurrentRequests < 0 {
+		return fmt.Errorf("%w: connectwise request limits cannot be negative", models.ErrInvalidConfig)
+	}
+
+	if c.AuditRetentionDays < 0 {
+		return fmt.Errorf("%w: audit retention days cannot be negative", models.ErrInvalidConfig)
+	}
+
+	if c.SessionIdleTimeoutHours < 1 || c.SessionMaxLifetimeHours < c.SessionIdleTimeoutHours {
+		return fmt.Errorf("%w: session idle timeout must be at least 1 hour, and no longer than the max lifetime", models.ErrInvalidConfig)
+	}
+
+	if c.LoginMaxFailures < 1 || c.LoginIPMaxFailures < 1 {
+		return fmt.Errorf("%w: login failure limits must be at least 1", models.ErrInvalidConfig)
+	}
+
+	if c.LoginLockoutMinutes < 1 {
+		return fmt.Errorf("%w: login lockout must be at least 1 minute", models.ErrInvalidConfig)
+	}
+
+	return nil
+}
 
-	if s.logBuf != nil && src.LogBufferSize > 0 && src.LogBufferSize != s.logBuf.Size() {
-		s.logBuf.Resize(src.LogBufferSize)
-		slog.Info("log buffer resized", "size", src.LogBufferSize)
+// applyLogging keeps the log level and buffer size in line with the config.
+func (s *Service) applyLogging(prev, next *models.Config) {
+	if s.logBuf != nil && next.LogBufferSize > 0 && next.LogBufferSize != s.logBuf.Size() {
+		s.logBuf.Resize(next.LogBufferSize)
+		slog.Info("log buffer resized", "size", next.LogBufferSize)
 	}
 
-	if s.level != nil {
-		if src.DebugLogging {
+	if s.level != nil && (prev == nil || prev.DebugLogging != next.DebugLogging) {
+		if next.DebugLogging {
 			s.level.Set(slog.LevelDebug)
 		} else {
 			s.level.Set(slog.LevelInfo)
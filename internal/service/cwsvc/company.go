@@ -0,0 +1,34 @@
+package cwsvc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+func (s *Service) ListCompanies(ctx context.Context) ([]*models.Company, error) {
+	return s.Companies.List(ctx)
+}
+
+func (s *Service) GetCompany(ctx context.Context, id int) (*models.Company, error) {
+	return s.Companies.Get(ctx, id)
+}
+
+// RefreshCompany pulls the company from Connectwise and updates the store, regardless of the
+// store TTL. If it no longer exists in Connectwise it is soft deleted.
+func (s *Service) RefreshCompany(ctx context.Context, id int) error {
+	if _, err := s.refreshCompany(ctx, id); err != nil {
+		if errors.Is(err, psa.ErrNotFound) {
+			return s.Companies.SoftDelete(ctx, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) SoftDeleteCompany(ctx context.Context, id int) error {
+	return s.Companies.SoftDelete(ctx, id)
+}
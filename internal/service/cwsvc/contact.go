@@ -0,0 +1,34 @@
+package cwsvc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+func (s *Service) ListContacts(ctx context.Context) ([]*models.Contact, error) {
+	return s.Contacts.List(ctx)
+}
+
+func (s *Service) GetContact(ctx context.Context, id int) (*models.Contact, error) {
+	return s.Contacts.Get(ctx, id)
+}
+
+// RefreshContact updates the stored contact from Connectwise immediately. Contacts that
+// were removed from Connectwise are soft deleted.
+func (s *Service) RefreshContact(ctx context.Context, id int) error {
+	if _, err := s.refreshContact(ctx, id); err != nil {
+		if errors.Is(err, psa.ErrNotFound) {
+			return s.Contacts.SoftDelete(ctx, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) SoftDeleteContact(ctx context.Context, id int) error {
+	return s.Contacts.SoftDelete(ctx, id)
+}
@@ -2,10 +2,28 @@ package cwsvc
 
 import (
 	"context"
+	"errors"
 
+	"github.com/thecoretg/tctg-go/connectwise/psa"
 	"github.com/thecoretg/ticketbot/models"
 )
 
 func (s *Service) ListMembers(ctx context.Context) ([]*models.Member, error) {
 	return s.Members.List(ctx)
 }
+
+// RefreshMember is the member equivalent of RefreshCompany.
+func (s *Service) RefreshMember(ctx context.Context, id int) error {
+	if _, err := s.refreshMember(ctx, id); err != nil {
+		if errors.Is(err, psa.ErrNotFound) {
+			return s.Members.SoftDelete(ctx, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) SoftDeleteMember(ctx context.Context, id int) error {
+	return s.Members.SoftDelete(ctx, id)
+}
@@ -5,8 +5,8 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/cwclient"
 	"github.com/thecoretg/ticketbot/internal/repos"
-	"github.com/thecoretg/tctg-go/connectwise/psa"
 )
 
 type Service struct {
@@ -19,10 +19,10 @@ type Service struct {
 	Statuses  repos.TicketStatusRepository
 	Notes     repos.TicketNoteRepository
 	pool      *pgxpool.Pool
-	CWClient  *psa.Client
+	CWClient  *cwclient.Client
 }
 
-func New(pool *pgxpool.Pool, r repos.CWRepos, cl *psa.Client, ttl int64) *Service {
+func New(pool *pgxpool.Pool, r repos.CWRepos, cl *cwclient.Client, ttl int64) *Service {
 	t := time.Second * time.Duration(ttl)
 	return &Service{
 		TTL:       t,
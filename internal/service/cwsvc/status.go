@@ -13,3 +13,7 @@ func (s *Service) ListStatuses(ctx context.Context) ([]*models.TicketStatus, err
 func (s *Service) ListStatusesByBoard(ctx context.Context, boardID int) ([]*models.TicketStatus, error) {
 	return s.Statuses.ListByBoard(ctx, boardID)
 }
+
+func (s *Service) GetStatus(ctx context.Context, id int) (*models.TicketStatus, error) {
+	return s.Statuses.Get(ctx, id)
+}
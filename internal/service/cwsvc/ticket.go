@@ -7,13 +7,21 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/thecoretg/ticketbot/models"
-	"github.com/thecoretg/ticketbot/internal/repos"
 	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/tracing"
+	"github.com/thecoretg/ticketbot/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var ErrTicketWasDeleted = errors.New("ticket was deleted from connectwise")
 
+const (
+	defaultTicketPageSize = 100
+	maxTicketPageSize     = 500
+)
+
 type Request struct {
 	*models.FullTicket
 	NoProcReason string
@@ -33,6 +41,97 @@ func (s *Service) PermDeleteTicket(ctx context.Context, id int) error {
 	return s.Tickets.Delete(ctx, id)
 }
 
+// ListTickets searches stored tickets. The limit is clamped to maxTicketPageSize.
+func (s *Service) ListTickets(ctx context.Context, f *models.TicketFilter) ([]*models.Ticket, error) {
+	if f == nil {
+		f = &models.TicketFilter{}
+	}
+
+	if f.Limit <= 0 {
+		f.Limit = defaultTicketPageSize
+	}
+	f.Limit = min(f.Limit, maxTicketPageSize)
+
+	return s.Tickets.Search(ctx, f)
+}
+
+// GetTicketDetail assembles a ticket and everything it references from the store
+// without calling Connectwise.
+func (s *Service) GetTicketDetail(ctx context.Context, id int) (*models.TicketDetail, error) {
+	t, err := s.Tickets.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := s.Boards.Get(ctx, t.BoardID)
+	if err != nil {
+		return nil, fmt.Errorf("getting board: %w", err)
+	}
+
+	status, err := s.Statuses.Get(ctx, t.StatusID)
+	if err != nil {
+		return nil, fmt.Errorf("getting status: %w", err)
+	}
+
+	company, err := s.Companies.Get(ctx, t.CompanyID)
+	if err != nil {
+		return nil, fmt.Errorf("getting company: %w", err)
+	}
+
+	d := &models.TicketDetail{
+		Ticket:  *t,
+		Board:   *board,
+		Status:  *status,
+		Company: *company,
+	}
+
+	if t.ContactID != nil {
+		d.Contact, err = s.Contacts.Get(ctx, *t.ContactID)
+		if err != nil {
+			return nil, fmt.Errorf("getting contact: %w", err)
+		}
+	}
+
+	if t.OwnerID != nil {
+		d.Owner, err = s.Members.Get(ctx, *t.OwnerID)
+		if err != nil {
+			return nil, fmt.Errorf("getting owner: %w", err)
+		}
+	}
+
+	if t.Resources != nil && *t.Resources != "" {
+		for _, i := range resourceStringToSlice(*t.Resources) {
+			m, err := s.Members.GetByIdentifier(ctx, i)
+			if err != nil {
+				if errors.Is(err, models.ErrMemberNotFound) {
+					continue
+				}
+				return nil, fmt.Errorf("getting resource member %s: %w", i, err)
+			}
+			d.Resources = append(d.Resources, m)
+		}
+	}
+
+	notes, err := s.Notes.ListByTicketID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing notes: %w", err)
+	}
+
+	for _, n := range notes {
+		if n.Deleted {
+			continue
+		}
+
+		fn, err := repos.TicketNoteToFullTicketNote(ctx, n, s.Members, s.Contacts)
+		if err != nil {
+			return nil, fmt.Errorf("getting note %d sender: %w", n.ID, err)
+		}
+		d.Notes = append(d.Notes, fn)
+	}
+
+	return d, nil
+}
+
 func (s *Service) ProcessTicket(ctx context.Context, id int, caller string) (*models.FullTicket, error) {
 	req, err := s.processTicket(ctx, id, caller)
 	if err != nil {
@@ -43,6 +142,11 @@ func (s *Service) ProcessTicket(ctx context.Context, id int, caller string) (*mo
 }
 
 func (s *Service) processTicket(ctx context.Context, id int, caller string) (req *Request, err error) {
+	ctx, span := tracing.Start(ctx, "cwsvc.ProcessTicket", trace.WithAttributes(
+		attribute.Int("ticket.id", id),
+		attribute.String("caller", caller),
+	))
+
 	req = &Request{
 		NoProcReason: "",
 		cd:           CWData{},
@@ -50,7 +154,11 @@ func (s *Service) processTicket(ctx context.Context, id int, caller string) (req
 
 	logger := slog.Default()
 	defer func() {
-		logRequest(req, err, logger)
+		logRequest(ctx, req, err, logger)
+		if req.NoProcReason != "" {
+			span.SetAttributes(attribute.String("no_process_reason", req.NoProcReason))
+		}
+		tracing.End(span, err)
 	}()
 
 	cd, err := s.getCwData(ctx, id)
@@ -253,12 +361,17 @@ func (s *Service) ensureCompany(ctx context.Context, id int) (*models.Company, e
 		return nil, fmt.Errorf("getting company from store: %w", err)
 	}
 
+	return s.refreshCompany(ctx, id)
+}
+
+// refreshCompany pulls the company from Connectwise and upserts it, ignoring the TTL.
+func (s *Service) refreshCompany(ctx context.Context, id int) (*models.Company, error) {
 	cw, err := s.CWClient.GetCompany(ctx, id, nil)
 	if err != nil {
 		return nil, fmt.Errorf("getting company from cw: %w", err)
 	}
 
-	c, err = s.Companies.Upsert(ctx, &models.Company{
+	c, err := s.Companies.Upsert(ctx, &models.Company{
 		ID:   cw.ID,
 		Name: cw.Name,
 	})
@@ -279,6 +392,11 @@ func (s *Service) ensureContact(ctx context.Context, id int) (*models.Contact, e
 		return nil, fmt.Errorf("getting contact from store: %w", err)
 	}
 
+	return s.refreshContact(ctx, id)
+}
+
+// refreshContact pulls the contact from Connectwise and upserts it, ignoring the TTL.
+func (s *Service) refreshContact(ctx context.Context, id int) (*models.Contact, error) {
 	cw, err := s.CWClient.GetContact(ctx, id, nil)
 	if err != nil {
 		return nil, fmt.Errorf("getting contact from cw: %w", err)
@@ -293,7 +411,7 @@ func (s *Service) ensureContact(ctx context.Context, id int) (*models.Contact, e
 		compID = intToPtr(comp.ID)
 	}
 
-	c, err = s.Contacts.Upsert(ctx, &models.Contact{
+	c, err := s.Contacts.Upsert(ctx, &models.Contact{
 		ID:        cw.ID,
 		FirstName: cw.FirstName,
 		LastName:  strToPtr(cw.LastName),
@@ -334,12 +452,17 @@ func (s *Service) ensureMember(ctx context.Context, id int) (*models.Member, err
 		return nil, fmt.Errorf("getting member from store: %w", err)
 	}
 
+	return s.refreshMember(ctx, id)
+}
+
+// refreshMember pulls the member from Connectwise and upserts it, ignoring the TTL.
+func (s *Service) refreshMember(ctx context.Context, id int) (*models.Member, error) {
 	cw, err := s.CWClient.GetMember(ctx, id, nil)
 	if err != nil {
 		return nil, fmt.Errorf("getting member from cw: %w", err)
 	}
 
-	m, err = s.Members.Upsert(ctx, &models.Member{
+	m, err := s.Members.Upsert(ctx, &models.Member{
 		ID:           cw.ID,
 		Identifier:   cw.Identifier,
 		FirstName:    cw.FirstName,
@@ -465,9 +588,9 @@ func strToPtr(s string) *string {
 	return &val
 }
 
-func logRequest(req *Request, err error, logger *slog.Logger) {
+func logRequest(ctx context.Context, req *Request, err error, logger *slog.Logger) {
 	if req == nil {
-		logger.Error("received nil request")
+		logger.ErrorContext(ctx, "received nil request")
 		return
 	}
 
@@ -476,9 +599,9 @@ func logRequest(req *Request, err error, logger *slog.Logger) {
 	}
 
 	if err != nil {
-		logger.Error("error occured processing ticket", "error", err.Error())
+		logger.ErrorContext(ctx, "error occured processing ticket", "error", err.Error())
 	} else {
-		logger.Info("ticket processed")
+		logger.InfoContext(ctx, "ticket processed")
 	}
 }
 
@@ -0,0 +1,103 @@
+package exportsvc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thecoretg/ticketbot/models"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat reads a format name, defaulting to YAML if s is empty.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected yaml or json", s)
+	}
+}
+
+// ContentType is the MIME type documents in the format are served as.
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "application/yaml"
+}
+
+// Encode writes doc in the format. YAML keeps the field order and names of the JSON form.
+func Encode(doc *models.ExportDocument, f Format) ([]byte, error) {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if f == FormatJSON {
+		return append(b, '\n'), nil
+	}
+
+	// JSON is YAML, so reading it as a node tree and dropping the quoting and brackets gives
+	// block-style YAML without needing yaml tags on every model
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode reads a document in the format. Unknown fields are rejected so typos don't silently
+// drop a setting.
+func Decode(b []byte, f Format) (*models.ExportDocument, error) {
+	if f == FormatYAML {
+		var v any
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("%w: %w", models.ErrInvalidImport, err)
+		}
+
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("%w: %w", models.ErrInvalidImport, err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	doc := &models.ExportDocument{}
+	if err := dec.Decode(doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidImport, err)
+	}
+
+	return doc, nil
+}
+
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
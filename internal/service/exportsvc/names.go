@@ -0,0 +1,122 @@
+package exportsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// names maps boards and recipients between their IDs and the names documents use. Room titles
+// and board names aren't unique, so a name matching more than one is an error rather than a
+// guess.
+type names struct {
+	boardNames map[int]string
+	boardIDs   map[string][]int
+	recipients map[int]*models.WebexRecipient
+	rooms      map[string][]int
+	people     map[string][]int
+}
+
+func (s *Service) loadNames(ctx context.Context) (*names, error) {
+	boards, err := s.Boards.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing boards: %w", err)
+	}
+
+	recips, err := s.Recipients.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing webex recipients: %w", err)
+	}
+
+	n := &names{
+		boardNames: make(map[int]string, len(boards)),
+		boardIDs:   make(map[string][]int, len(boards)),
+		recipients: make(map[int]*models.WebexRecipient, len(recips)),
+		rooms:      make(map[string][]int),
+		people:     make(map[string][]int),
+	}
+
+	for _, b := range boards {
+		n.boardNames[b.ID] = b.Name
+		// deleted boards can still be exported, but new rules can't point at them
+		if !b.Deleted {
+			n.boardIDs[b.Name] = append(n.boardIDs[b.Name], b.ID)
+		}
+	}
+
+	for _, r := range recips {
+		n.recipients[r.ID] = r
+		switch {
+		case r.Type == models.RecipientTypeRoom:
+			n.rooms[r.Name] = append(n.rooms[r.Name], r.ID)
+		case r.Email != nil:
+			email := strings.ToLower(*r.Email)
+			n.people[email] = append(n.people[email], r.ID)
+		}
+	}
+
+	return n, nil
+}
+
+func (n *names) boardID(name string) (int, error) {
+	return only(n.boardIDs[name], "board", name)
+}
+
+func (n *names) recipientID(ref models.RecipientRef) (int, error) {
+	switch {
+	case ref.Room != "" && ref.Person != "":
+		return 0, errors.New("recipient must be a room or a person, not both")
+	case ref.Room != "":
+		return only(n.rooms[ref.Room], "room", ref.Room)
+	case ref.Person != "":
+		return only(n.people[strings.ToLower(ref.Person)], "person", ref.Person)
+	default:
+		return 0, errors.New("recipient needs a room or a person")
+	}
+}
+
+func (n *names) recipientRef(id int) (models.RecipientRef, error) {
+	r, ok := n.recipients[id]
+	if !ok {
+		return models.RecipientRef{}, fmt.Errorf("webex recipient %d not found", id)
+	}
+
+	if r.Type == models.RecipientTypeRoom {
+		return models.RecipientRef{Room: r.Name}, nil
+	}
+
+	if r.Email == nil {
+		return models.RecipientRef{}, fmt.Errorf("webex person %q has no email to refer to them by", r.Name)
+	}
+
+	return models.RecipientRef{Person: *r.Email}, nil
+}
+
+func only(ids []int, kind, name string) (int, error) {
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("%s %q not found", kind, name)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("%s %q matches %d %ss", kind, name, len(ids), kind)
+	}
+}
+
+func refString(ref models.RecipientRef) string {
+	if ref.Person != "" {
+		return "person:" + strings.ToLower(ref.Person)
+	}
+	return "room:" + ref.Room
+}
+
+func dateString(t *time.Time) string {
+	if t == nil {
+		return "open"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,555 @@
+// Package exportsvc exports a deployment's config, notifier rules and forwards as a document
+// that refers to boards and recipients by name, and imports such a document by creating,
+// updating and deleting until the database matches it.
+package exportsvc
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/service/config"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	kindRule    = "rule"
+	kindForward = "forward"
+
+	// the config's serial recipient ID is exported under this key as a RecipientRef
+	hookRecipientKey   = "hook_alert_recipient"
+	hookRecipientIDKey = "hook_alert_recipient_id"
+)
+
+type Service struct {
+	Config     *config.Service
+	Rules      repos.NotifierRuleRepository
+	Forwards   repos.NotifierForwardRepository
+	Boards     repos.BoardRepository
+	Recipients repos.WebexRecipientRepository
+	pool       *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool, cfg *config.Service, rules repos.NotifierRuleRepository, fwds repos.NotifierForwardRepository, boards repos.BoardRepository, recips repos.WebexRecipientRepository) *Service {
+	return &Service{
+		Config:     cfg,
+		Rules:      rules,
+		Forwards:   fwds,
+		Boards:     boards,
+		Recipients: recips,
+		pool:       pool,
+	}
+}
+
+// Export returns the current config, rules and forwards, sorted so that exports of the same
+// state are identical.
+func (s *Service) Export(ctx context.Context) (*models.ExportDocument, error) {
+	n, err := s.loadNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := s.Config.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting config: %w", err)
+	}
+
+	doc := &models.ExportDocument{
+		Version:  models.ExportVersion,
+		Rules:    []models.ExportRule{},
+		Forwards: []models.ExportForward{},
+	}
+
+	if doc.Config, err = n.exportConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.Rules.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing notifier rules: %w", err)
+	}
+	for _, r := range rules {
+		e, err := n.exportRule(r)
+		if err != nil {
+			return nil, err
+		}
+		doc.Rules = append(doc.Rules, e)
+	}
+	slices.SortFunc(doc.Rules, func(a, b models.ExportRule) int { return cmp.Compare(ruleKey(a), ruleKey(b)) })
+
+	fwds, err := s.Forwards.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing forwards: %w", err)
+	}
+	for _, f := range fwds {
+		e, err := n.exportForward(f)
+		if err != nil {
+			return nil, err
+		}
+		doc.Forwards = append(doc.Forwards, e)
+	}
+	slices.SortFunc(doc.Forwards, func(a, b models.ExportForward) int { return cmp.Compare(forwardKey(a), forwardKey(b)) })
+
+	return doc, nil
+}
+
+// Empty reports whether nothing has been configured yet: there are no rules or forwards, and the
+// config has never been saved.
+func (s *Service) Empty(ctx context.Context) (bool, error) {
+	rules, err := s.Rules.ListAll(ctx)
+	if err != nil {
+		return false, fmt.Errorf("listing notifier rules: %w", err)
+	}
+
+	fwds, err := s.Forwards.ListAll(ctx)
+	if err != nil {
+		return false, fmt.Errorf("listing forwards: %w", err)
+	}
+
+	if len(rules) > 0 || len(fwds) > 0 {
+		return false, nil
+	}
+
+	if _, err := s.Config.Revisions.Latest(ctx); err != nil {
+		if errors.Is(err, models.ErrConfigRevisionNotFound) {
+			return true, nil
+		}
+		return false, fmt.Errorf("getting latest config revision: %w", err)
+	}
+
+	return false, nil
+}
+
+// Import works out what it takes to make the database match doc and, unless dryRun is set,
+// does it. Names that don't match exactly one board or recipient fail the whole import.
+func (s *Service) Import(ctx context.Context, doc *models.ExportDocument, dryRun bool, author *models.Actor) (*models.ImportResult, error) {
+	if doc.Version != models.ExportVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported, expected %d", models.ErrInvalidImport, doc.Version, models.ExportVersion)
+	}
+
+	n, err := s.loadNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		p, err := s.plan(ctx, n, doc, s.Rules, s.Forwards)
+		if err != nil {
+			return nil, err
+		}
+		return &models.ImportResult{DryRun: true, Config: p.configChanges, Changes: p.changes}, nil
+	}
+
+	p, err := s.apply(ctx, n, doc, author)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ImportResult{Config: p.configChanges, Changes: p.changes}, nil
+}
+
+type plan struct {
+	// config holds the imported config fields, which are saved over the config as it is when
+	// the import is applied
+	config        json.RawMessage
+	configChanges []models.ConfigChange
+	changes       []models.ImportChange
+
+	createRules    []*models.NotifierRule
+	updateRules    []*models.NotifierRule
+	deleteRules    []int
+	createForwards []*models.NotifierForward
+	updateForwards []*models.NotifierForward
+	deleteForwards []int
+}
+
+// plan works out the changes that make the database match doc, reading the existing rules and
+// forwards through rules and fwds.
+func (s *Service) plan(ctx context.Context, n *names, doc *models.ExportDocument, rules repos.NotifierRuleRepository, fwds repos.NotifierForwardRepository) (*plan, error) {
+	p := &plan{configChanges: []models.ConfigChange{}, changes: []models.ImportChange{}}
+	var errs []string
+
+	if doc.Config != nil {
+		raw, err := n.importConfig(doc.Config)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else if _, p.configChanges, err = s.Config.Preview(ctx, raw); err != nil {
+			if !errors.Is(err, models.ErrInvalidConfig) {
+				return nil, err
+			}
+			errs = append(errs, err.Error())
+		} else {
+			p.config = raw
+		}
+	}
+
+	if doc.Rules != nil {
+		if err := planRules(ctx, rules, n, doc.Rules, p, &errs); err != nil {
+			return nil, err
+		}
+	}
+
+	if doc.Forwards != nil {
+		if err := planForwards(ctx, fwds, n, doc.Forwards, p, &errs); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidImport, strings.Join(errs, "; "))
+	}
+
+	slices.SortStableFunc(p.changes, func(a, b models.ImportChange) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Key, b.Key))
+	})
+
+	return p, nil
+}
+
+func planRules(ctx context.Context, rules repos.NotifierRuleRepository, n *names, want []models.ExportRule, p *plan, errs *[]string) error {
+	type ruleID struct{ board, recipient int }
+
+	existing, err := rules.ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("listing notifier rules: %w", err)
+	}
+
+	have := make(map[ruleID]*models.NotifierRule, len(existing))
+	for _, r := range existing {
+		have[ruleID{r.CwBoardID, r.WebexRecipientID}] = r
+	}
+
+	seen := make(map[ruleID]bool)
+	for _, w := range want {
+		boardID, err := n.boardID(w.Board)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("rule %s: %s", ruleKey(w), err))
+			continue
+		}
+		recipID, err := n.recipientID(w.Recipient)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("rule %s: %s", ruleKey(w), err))
+			continue
+		}
+
+		id := ruleID{boardID, recipID}
+		if seen[id] {
+			*errs = append(*errs, fmt.Sprintf("rule %s is listed twice", ruleKey(w)))
+			continue
+		}
+		seen[id] = true
+
+		enabled := boolOr(w.Enabled, true)
+		after := w
+		after.Enabled = &enabled
+
+		cur, ok := have[id]
+		switch {
+		case !ok:
+			p.createRules = append(p.createRules, &models.NotifierRule{CwBoardID: boardID, WebexRecipientID: recipID, NotifyEnabled: enabled})
+			p.changes = append(p.changes, models.ImportChange{Kind: kindRule, Action: models.ImportCreate, Key: ruleKey(w), After: after})
+		case cur.NotifyEnabled != enabled:
+			before, _ := n.exportRule(cur)
+			upd := *cur
+			upd.NotifyEnabled = enabled
+			p.updateRules = append(p.updateRules, &upd)
+			p.changes = append(p.changes, models.ImportChange{Kind: kindRule, Action: models.ImportUpdate, Key: ruleKey(w), Before: before, After: after})
+		}
+	}
+
+	for id, r := range have {
+		if seen[id] {
+			continue
+		}
+		before, err := n.exportRule(r)
+		if err != nil {
+			return err
+		}
+		p.deleteRules = append(p.deleteRules, r.ID)
+		p.changes = append(p.changes, models.ImportChange{Kind: kindRule, Action: models.ImportDelete, Key: ruleKey(before), Before: before})
+	}
+
+	return nil
+}
+
+func planForwards(ctx context.Context, fwds repos.NotifierForwardRepository, n *names, want []models.ExportForward, p *plan, errs *[]string) error {
+	existing, err := fwds.ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("listing forwards: %w", err)
+	}
+
+	// forwards are unique by source, destination and dates, so the names and dates make the key
+	have := make(map[string]*models.NotifierForward, len(existing))
+	for _, f := range existing {
+		e, err := n.exportForward(f)
+		if err != nil {
+			return err
+		}
+		have[forwardKey(e)] = f
+	}
+
+	seen := make(map[string]bool)
+	for _, w := range want {
+		key := forwardKey(w)
+
+		srcID, err := n.recipientID(w.Source)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("forward %s: %s", key, err))
+			continue
+		}
+		dstID, err := n.recipientID(w.Destination)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("forward %s: %s", key, err))
+			continue
+		}
+
+		if srcID == dstID {
+			*errs = append(*errs, fmt.Sprintf("forward %s: source and destination are the same", key))
+			continue
+		}
+		if w.StartDate != nil && w.EndDate != nil && !w.StartDate.Before(*w.EndDate) {
+			*errs = append(*errs, fmt.Sprintf("forward %s: start date must be before end date", key))
+			continue
+		}
+		if seen[key] {
+			*errs = append(*errs, fmt.Sprintf("forward %s is listed twice", key))
+			continue
+		}
+		seen[key] = true
+
+		enabled, keepsCopy := boolOr(w.Enabled, true), boolOr(w.UserKeepsCopy, true)
+		after := w
+		after.Enabled, after.UserKeepsCopy = &enabled, &keepsCopy
+
+		cur, ok := have[key]
+		switch {
+		case !ok:
+			p.createForwards = append(p.createForwards, &models.NotifierForward{
+				SourceID:      srcID,
+				DestID:        dstID,
+				StartDate:     w.StartDate,
+				EndDate:       w.EndDate,
+				Enabled:       enabled,
+				UserKeepsCopy: keepsCopy,
+			})
+			p.changes = append(p.changes, models.ImportChange{Kind: kindForward, Action: models.ImportCreate, Key: key, After: after})
+		case cur.Enabled != enabled || cur.UserKeepsCopy != keepsCopy:
+			before, _ := n.exportForward(cur)
+			upd := *cur
+			upd.Enabled, upd.UserKeepsCopy = enabled, keepsCopy
+			p.updateForwards = append(p.updateForwards, &upd)
+			p.changes = append(p.changes, models.ImportChange{Kind: kindForward, Action: models.ImportUpdate, Key: key, Before: before, After: after})
+		}
+	}
+
+	for key, f := range have {
+		if seen[key] {
+			continue
+		}
+		before, _ := n.exportForward(f)
+		p.deleteForwards = append(p.deleteForwards, f.ID)
+		p.changes = append(p.changes, models.ImportChange{Kind: kindForward, Action: models.ImportDelete, Key: key, Before: before})
+	}
+
+	return nil
+}
+
+// apply plans the import again within one transaction, so the IDs it updates and deletes are
+// the ones in the database as it is changed, then makes the changes, the config included. An
+// import that fails part way changes nothing. Deletes go first so a forward can be replaced by
+// one with the same key.
+func (s *Service) apply(ctx context.Context, n *names, doc *models.ExportDocument, author *models.Actor) (*plan, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rules := s.Rules.WithTx(tx)
+	fwds := s.Forwards.WithTx(tx)
+
+	p, err := s.plan(ctx, n, doc, rules, fwds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range p.deleteRules {
+		if err := rules.Delete(ctx, id); err != nil {
+			return nil, fmt.Errorf("deleting notifier rule %d: %w", id, err)
+		}
+	}
+	for _, id := range p.deleteForwards {
+		if err := fwds.Delete(ctx, id); err != nil {
+			return nil, fmt.Errorf("deleting forward %d: %w", id, err)
+		}
+	}
+
+	for _, r := range p.updateRules {
+		if _, err := rules.Update(ctx, r); err != nil {
+			return nil, fmt.Errorf("updating notifier rule %d: %w", r.ID, err)
+		}
+	}
+	for _, f := range p.updateForwards {
+		if _, err := fwds.Update(ctx, f); err != nil {
+			return nil, fmt.Errorf("updating forward %d: %w", f.ID, err)
+		}
+	}
+
+	for _, r := range p.createRules {
+		if _, err := rules.Insert(ctx, r); err != nil {
+			return nil, fmt.Errorf("inserting notifier rule: %w", err)
+		}
+	}
+	for _, f := range p.createForwards {
+		if _, err := fwds.Insert(ctx, f); err != nil {
+			return nil, fmt.Errorf("inserting forward: %w", err)
+		}
+	}
+
+	// saved last, since other config saves wait on it until the tx is done
+	committed := false
+	if len(p.configChanges) > 0 {
+		done, err := s.Config.ReplaceTx(ctx, tx, p.config, author)
+		if err != nil {
+			return nil, fmt.Errorf("saving config: %w", err)
+		}
+		defer func() {
+			done(committed)
+		}()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing tx: %w", err)
+	}
+	committed = true
+
+	return p, nil
+}
+
+// exportConfig returns the config as a map, without its ID and with the hook alert recipient
+// referred to by name.
+func (n *names) exportConfig(cfg *models.Config) (map[string]any, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+
+	m := make(map[string]any)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("unmarshaling config: %w", err)
+	}
+
+	delete(m, "id")
+	delete(m, hookRecipientIDKey)
+	m[hookRecipientKey] = nil
+	if cfg.HookAlertRecipientID != nil {
+		ref, err := n.recipientRef(*cfg.HookAlertRecipientID)
+		if err != nil {
+			return nil, fmt.Errorf("hook alert recipient: %w", err)
+		}
+		m[hookRecipientKey] = ref
+	}
+
+	return m, nil
+}
+
+// importConfig reverses exportConfig, returning the config fields as JSON for config.Preview.
+func (n *names) importConfig(m map[string]any) (json.RawMessage, error) {
+	if _, ok := m[hookRecipientIDKey]; ok {
+		return nil, fmt.Errorf("config: use %s instead of %s", hookRecipientKey, hookRecipientIDKey)
+	}
+
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if k != "id" && k != hookRecipientKey {
+			out[k] = v
+		}
+	}
+
+	if v, ok := m[hookRecipientKey]; ok {
+		out[hookRecipientIDKey] = nil
+		if v != nil {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("config: %s: %w", hookRecipientKey, err)
+			}
+
+			var ref models.RecipientRef
+			if err := json.Unmarshal(b, &ref); err != nil {
+				return nil, fmt.Errorf("config: %s must be a room or person", hookRecipientKey)
+			}
+
+			id, err := n.recipientID(ref)
+			if err != nil {
+				return nil, fmt.Errorf("config: %s: %w", hookRecipientKey, err)
+			}
+			out[hookRecipientIDKey] = id
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+func (n *names) exportRule(r *models.NotifierRule) (models.ExportRule, error) {
+	board, ok := n.boardNames[r.CwBoardID]
+	if !ok {
+		return models.ExportRule{}, fmt.Errorf("notifier rule %d: board %d not found", r.ID, r.CwBoardID)
+	}
+
+	recip, err := n.recipientRef(r.WebexRecipientID)
+	if err != nil {
+		return models.ExportRule{}, fmt.Errorf("notifier rule %d: %w", r.ID, err)
+	}
+
+	enabled := r.NotifyEnabled
+	return models.ExportRule{Board: board, Recipient: recip, Enabled: &enabled}, nil
+}
+
+func (n *names) exportForward(f *models.NotifierForward) (models.ExportForward, error) {
+	src, err := n.recipientRef(f.SourceID)
+	if err != nil {
+		return models.ExportForward{}, fmt.Errorf("forward %d: %w", f.ID, err)
+	}
+
+	dst, err := n.recipientRef(f.DestID)
+	if err != nil {
+		return models.ExportForward{}, fmt.Errorf("forward %d: %w", f.ID, err)
+	}
+
+	enabled, keepsCopy := f.Enabled, f.UserKeepsCopy
+	return models.ExportForward{
+		Source:        src,
+		Destination:   dst,
+		StartDate:     f.StartDate,
+		EndDate:       f.EndDate,
+		Enabled:       &enabled,
+		UserKeepsCopy: &keepsCopy,
+	}, nil
+}
+
+func ruleKey(r models.ExportRule) string {
+	return r.Board + " -> " + refString(r.Recipient)
+}
+
+func forwardKey(f models.ExportForward) string {
+	key := refString(f.Source) + " -> " + refString(f.Destination)
+	if f.StartDate != nil || f.EndDate != nil {
+		key += " (" + dateString(f.StartDate) + " to " + dateString(f.EndDate) + ")"
+	}
+	return key
+}
+
+func boolOr(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
@@ -7,7 +7,11 @@ import (
 	"log/slog"
 	"strconv"
 
+	"github.com/thecoretg/ticketbot/internal/metrics"
+	"github.com/thecoretg/ticketbot/internal/tracing"
 	"github.com/thecoretg/ticketbot/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Request struct {
@@ -75,10 +79,26 @@ func (s *Service) processNotifications(ctx context.Context, t *models.FullTicket
 		return errors.New("nil ticket received")
 	}
 
+	ctx, span := tracing.Start(ctx, "notifier.Run", trace.WithAttributes(
+		attribute.Int("ticket.id", t.Ticket.ID),
+		attribute.Bool("ticket.new", isNew),
+	))
+
 	req := newRequest(t)
 	logger := slog.Default().With("ticket_id", t.Ticket.ID)
 	defer func() {
-		logRequest(req, err, logger)
+		logRequest(ctx, req, err, logger)
+		span.SetAttributes(
+			attribute.Int("messages.sent", len(req.MessagesSent)),
+			attribute.Int("messages.failed", len(req.MessagesErrored)),
+		)
+		if req.NoNotiReason != "" {
+			span.SetAttributes(attribute.String("no_notify_reason", req.NoNotiReason))
+		}
+		tracing.End(span, err)
+		if req.NoNotiReason != "" {
+			metrics.NotificationSkipped(req.NoNotiReason)
+		}
 		if req.Ticket != nil && req.NoNotiReason != "" {
 			if err := s.AddSkippedNotification(ctx, req.Ticket, fmt.Sprintf("notifier: %s", req.NoNotiReason)); err != nil {
 				logger.Error("adding skipped notification")
@@ -151,26 +171,39 @@ func (s *Service) processNotifications(ctx context.Context, t *models.FullTicket
 
 func (s *Service) sendNotification(ctx context.Context, m *Message) *Message {
 	n := m.Notification
+	rt := string(m.WebexRecipient.recipient.Type)
+	ctx, span := tracing.Start(ctx, "notifier.send", trace.WithAttributes(
+		attribute.String("recipient.type", rt),
+		attribute.Int("recipient.id", m.WebexRecipient.recipient.ID),
+	))
+	defer func() { tracing.End(span, m.SendError) }()
+
 	logger := slog.Default().With(
 		slog.Int("ticket_id", n.TicketID),
 		slog.Int("ticket_note_id", ptrToInt(n.TicketNoteID)),
 		slog.String("recipient", m.WebexRecipient.recipient.Name),
 	)
 
-	logger.Debug("notifier: sending notification")
+	logger.DebugContext(ctx, "notifier: sending notification")
 	_, err := s.MessageSender.PostMessage(ctx, &m.WebexMsg)
 	if err != nil {
 		m.SendError = fmt.Errorf("sending webex message: %w", err)
+		metrics.NotificationFailed("send error", rt)
 	}
 
-	logger.Debug("inserting notification into store")
+	logger.DebugContext(ctx, "inserting notification into store")
 	m.Notification, err = s.Notifications.Insert(ctx, m.Notification)
 	if err != nil {
 		if m.SendError == nil {
 			m.SendError = fmt.Errorf("message was sent, but error inserting record: %w", err)
+			metrics.NotificationFailed("store error", rt)
 		}
 	}
 
+	if m.SendError == nil {
+		metrics.NotificationSent(rt)
+	}
+
 	return m
 }
 
@@ -228,15 +261,15 @@ func msgsLogGroup(key string, msgs []Message) slog.Attr {
 	return slog.Group(key, msgGrps...)
 }
 
-func logRequest(req *Request, err error, logger *slog.Logger) {
+func logRequest(ctx context.Context, req *Request, err error, logger *slog.Logger) {
 	if req.NoNotiReason != "" {
 		logger = logger.With("no_noti_reason", req.NoNotiReason)
 	}
 
 	if err != nil {
-		logger.Error("error occured with notification", "error", err.Error())
+		logger.ErrorContext(ctx, "error occured with notification", "error", err.Error())
 	} else {
-		logger.Info("notification processed")
+		logger.InfoContext(ctx, "notification processed")
 	}
 }
 
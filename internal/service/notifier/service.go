@@ -2,13 +2,13 @@ package notifier
 
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/thecoretg/ticketbot/models"
 	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
 	"github.com/thecoretg/ticketbot/internal/service/webexsvc"
 )
 
 type Service struct {
-	Cfg           *models.Config
+	Cfg           *runtimecfg.Store
 	WebexSvc      *webexsvc.Service
 	NotifierRules repos.NotifierRuleRepository
 	Notifications repos.TicketNotificationRepository
@@ -19,7 +19,7 @@ type Service struct {
 }
 
 type SvcParams struct {
-	Cfg           *models.Config
+	Cfg           *runtimecfg.Store
 	WebexSvc      *webexsvc.Service
 	NotifierRules repos.NotifierRuleRepository
 	Notifications repos.TicketNotificationRepository
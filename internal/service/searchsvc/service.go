@@ -0,0 +1,38 @@
+package searchsvc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+type Service struct {
+	Search repos.SearchRepository
+}
+
+func New(r repos.SearchRepository) *Service {
+	return &Service{Search: r}
+}
+
+// Query runs a full-text search over ticket summaries and note content. The query
+// uses websearch syntax, so quoted phrases, "or" and -exclusions are supported.
+func (s *Service) Query(ctx context.Context, p *models.SearchParams) ([]*models.SearchHit, error) {
+	p.Query = strings.TrimSpace(p.Query)
+	if p.Query == "" {
+		return nil, models.ErrEmptySearchQuery
+	}
+
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	p.Limit = min(p.Limit, maxLimit)
+
+	return s.Search.Search(ctx, p)
+}
@@ -8,80 +8,177 @@ import (
 	"sync"
 	"time"
 
+	"github.com/thecoretg/ticketbot/internal/metrics"
 	"github.com/thecoretg/ticketbot/models"
 )
 
 func (s *Service) IsSyncing() bool {
-	return s.syncing.Load()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current != nil
 }
 
-func (s *Service) Sync(ctx context.Context, payload *models.SyncPayload) error {
+// Sync creates a job for the payload and runs it in the background, returning the job
+// as it was started. Only one job runs at a time; ErrSyncInProgress is returned otherwise.
+// The job stops early if ctx is cancelled or the job is cancelled with CancelJob.
+func (s *Service) Sync(ctx context.Context, payload *models.SyncPayload, trigger string) (*models.SyncJob, error) {
 	if payload == nil {
-		return errors.New("received nil payload")
+		return nil, errors.New("received nil payload")
 	}
 
 	slog.Info("received sync payload",
+		slog.String("triggered_by", trigger),
 		slog.Bool("sync_boards", payload.CWBoards),
 		slog.Bool("sync_recipients", payload.WebexRecipients),
 		slog.Bool("sync_tickets", payload.CWTickets),
+		slog.Bool("sync_ticket_updates", payload.CWTicketUpdates),
 		slog.Any("ticket_board_ids", payload.BoardIDs),
 		slog.Int("max_concurrent_syncs", payload.MaxConcurrentSyncs),
 	)
 
-	if !s.syncing.CompareAndSwap(false, true) {
-		return errors.New("sync already in progress")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		return nil, models.ErrSyncInProgress
 	}
 
-	errch := make(chan error, 3)
-	var wg sync.WaitGroup
+	job, err := s.Jobs.Create(ctx, newJob(payload, trigger))
+	if err != nil {
+		return nil, fmt.Errorf("creating sync job: %w", err)
+	}
 
-	start := time.Now()
-	errored := false
-	defer func() {
-		s.syncing.Store(false)
-		if errored {
-			slog.Error("sync complete with errors, see logs", "payload", payload, "took_seconds", time.Since(start).Seconds())
-		} else {
-			slog.Info("sync complete", "payload", payload, "took_seconds", time.Since(start).Seconds())
-		}
-	}()
+	runCtx, cancel := context.WithCancel(ctx)
+	r := &jobRun{job: job, cancel: cancel}
+	s.current = r
+
+	go s.run(runCtx, r)
+	return r.snapshot(), nil
+}
+
+func (s *Service) run(ctx context.Context, r *jobRun) {
+	defer r.cancel()
 
-	if payload.CWBoards {
+	payload := r.job.Payload
+	var wg sync.WaitGroup
+	for _, t := range payloadSyncTypes(&payload) {
+		ph := r.phase(t)
 		wg.Go(func() {
-			if err := s.SyncBoards(ctx); err != nil {
-				errch <- fmt.Errorf("syncing connectwise boards: %w", err)
-				return
-			}
+			s.runPhase(ctx, ph, t, &payload)
 		})
 	}
+	wg.Wait()
 
-	if payload.WebexRecipients {
-		wg.Go(func() {
-			if err := s.SyncWebexRecipients(ctx, payload.MaxConcurrentSyncs); err != nil {
-				errch <- fmt.Errorf("syncing webex recipients: %w", err)
-				return
-			}
-		})
+	finished := time.Now()
+	r.mu.Lock()
+	var errs []error
+	for _, p := range r.job.Phases {
+		if p.Error != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", p.Type, *p.Error))
+		}
+	}
+	err := errors.Join(errs...)
+	r.job.Status = phaseStatus(ctx, err)
+	if err != nil {
+		msg := err.Error()
+		r.job.Error = &msg
 	}
+	r.job.FinishedOn = &finished
+	r.mu.Unlock()
 
-	if payload.CWTickets {
-		wg.Go(func() {
-			if err := s.SyncOpenTickets(ctx, payload.BoardIDs, payload.MaxConcurrentSyncs); err != nil {
-				errch <- fmt.Errorf("syncing connectwise tickets: %w", err)
-				return
-			}
-		})
+	job := r.snapshot()
+	took := finished.Sub(job.StartedOn).Seconds()
+	switch job.Status {
+	case models.SyncJobSucceeded:
+		slog.Info("sync complete", "job_id", job.ID, "payload", payload, "took_seconds", took)
+	case models.SyncJobCancelled:
+		slog.Warn("sync cancelled", "job_id", job.ID, "payload", payload, "took_seconds", took)
+	default:
+		slog.Error("sync complete with errors, see job for details", "job_id", job.ID, "payload", payload, "took_seconds", took)
 	}
 
-	wg.Wait()
-	close(errch)
+	// the run context may already be cancelled, but the result still needs to be saved
+	saveCtx := context.WithoutCancel(ctx)
+	if _, err := s.Jobs.Update(saveCtx, job); err != nil {
+		slog.Error("sync: saving finished job", "job_id", job.ID, "error", err.Error())
+	}
+
+	if err := s.Jobs.DeleteOlderThan(saveCtx, finished.Add(-jobHistoryRetention)); err != nil {
+		slog.Warn("sync: cleaning up old jobs", "error", err.Error())
+	}
 
-	for err := range errch {
+	s.mu.Lock()
+	s.current = nil
+	s.mu.Unlock()
+}
+
+func (s *Service) runPhase(ctx context.Context, ph *phaseRun, t models.SyncType, p *models.SyncPayload) {
+	start := time.Now()
+	ph.update(func(sp *models.SyncPhase) { sp.StartedOn = &start })
+
+	err := s.recordRun(ctx, t, func() error {
+		switch t {
+		case models.SyncTypeBoards:
+			return s.SyncBoards(ctx, ph)
+		case models.SyncTypeRecipients:
+			return s.SyncWebexRecipients(ctx, p.MaxConcurrentSyncs, ph)
+		case models.SyncTypeOpenTickets:
+			return s.SyncOpenTickets(ctx, p.BoardIDs, p.MaxConcurrentSyncs, ph)
+		case models.SyncTypeTicketUpdates:
+			return s.SyncTicketUpdates(ctx, p.BoardIDs, p.MaxConcurrentSyncs, ph)
+		default:
+			return fmt.Errorf("unknown sync type %q", t)
+		}
+	})
+	if err != nil {
+		slog.Error("sync phase", "sync_type", t, "error", err.Error())
+	}
+
+	finish := time.Now()
+	ph.update(func(sp *models.SyncPhase) {
+		sp.Status = phaseStatus(ctx, err)
+		sp.FinishedOn = &finish
 		if err != nil {
-			errored = true
-			slog.Error("hook sync", "error", err.Error())
+			msg := err.Error()
+			sp.Error = &msg
 		}
+
+		st := string(t)
+		metrics.SyncRun(st, finish.Sub(start), string(sp.Status))
+		metrics.SyncItems(st, "fetched", sp.Fetched)
+		metrics.SyncItems(st, "upserted", sp.Upserted)
+		metrics.SyncItems(st, "soft_deleted", sp.SoftDeleted)
+		metrics.SyncItems(st, "failed", sp.Failed)
+	})
+}
+
+// ListRuns returns the last run results for every sync type that has run at least once.
+func (s *Service) ListRuns(ctx context.Context) ([]*models.SyncState, error) {
+	return s.State.List(ctx)
+}
+
+// recordRun runs fn and stores its timings and result as the last run of the sync type.
+// Failing to store the result is only logged so it never masks the sync's own error.
+func (s *Service) recordRun(ctx context.Context, t models.SyncType, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	finish := time.Now()
+
+	st := &models.SyncState{
+		SyncType:     t,
+		LastStarted:  &start,
+		LastFinished: &finish,
+	}
+
+	if err != nil {
+		msg := err.Error()
+		st.LastError = &msg
+	} else {
+		st.LastSucceeded = &finish
+	}
+
+	if _, rerr := s.State.RecordRun(context.WithoutCancel(ctx), st); rerr != nil {
+		slog.Warn("sync: recording run result", "sync_type", t, "error", rerr.Error())
 	}
 
-	return nil
+	return err
 }
@@ -6,11 +6,11 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/thecoretg/ticketbot/models"
 	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/models"
 )
 
-func (s *Service) SyncBoards(ctx context.Context) error {
+func (s *Service) SyncBoards(ctx context.Context, ph *phaseRun) error {
 	start := time.Now()
 	slog.Info("beginning connectwise board sync")
 	cwb, err := s.CW.CWClient.ListBoards(ctx, nil)
@@ -18,6 +18,7 @@ func (s *Service) SyncBoards(ctx context.Context) error {
 		return fmt.Errorf("listing connectwise boards: %w", err)
 	}
 	slog.Info("board sync: got boards from connectwise", "total_boards", len(cwb))
+	ph.addFetched(len(cwb))
 
 	sb, err := s.CW.Boards.List(ctx)
 	if err != nil {
@@ -36,13 +37,20 @@ func (s *Service) SyncBoards(ctx context.Context) error {
 	}()
 
 	for _, b := range boardsToUpsert(cwb) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if _, err := txSvc.CW.Boards.Upsert(ctx, b); err != nil {
 			slog.Error("board sync: upserting board", "board_id", b.ID, "error", err.Error())
+			ph.addFailed(fmt.Sprintf("board %d", b.ID), err)
 			continue
 		}
+		ph.addUpserted(1)
 
 		if err := txSvc.SyncBoardStatuses(ctx, b.ID); err != nil {
 			slog.Error("board sync: status sync", "board_id", b.ID, "error", err.Error())
+			ph.addFailed(fmt.Sprintf("board %d statuses", b.ID), err)
 		}
 	}
 
@@ -50,6 +58,7 @@ func (s *Service) SyncBoards(ctx context.Context) error {
 		if err := txSvc.CW.Boards.SoftDelete(ctx, b.ID); err != nil {
 			return fmt.Errorf("soft deleting board %d (%s): %w", b.ID, b.Name, err)
 		}
+		ph.addSoftDeleted(1)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
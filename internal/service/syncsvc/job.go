@@ -0,0 +1,230 @@
+package syncsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+const (
+	// maxPhaseErrors caps how many item errors are kept per phase. The failed count
+	// keeps going past this, so a bad run can't bloat the job history.
+	maxPhaseErrors = 50
+
+	// jobHistoryRetention is how long finished jobs are kept. Old jobs are cleaned up
+	// after each run.
+	jobHistoryRetention = 30 * 24 * time.Hour
+
+	defaultJobPageSize = 50
+	maxJobPageSize     = 200
+)
+
+// jobRun is the live state of the running job. All reads and writes to the job go
+// through mu, since phases run concurrently and handlers read progress mid-run.
+type jobRun struct {
+	mu     sync.Mutex
+	job    *models.SyncJob
+	cancel context.CancelFunc
+}
+
+// phaseRun records progress for one phase of a job. A nil phaseRun is valid and
+// discards everything, so the sync functions can be run outside a job.
+type phaseRun struct {
+	run   *jobRun
+	phase *models.SyncPhase
+}
+
+func (r *jobRun) phase(t models.SyncType) *phaseRun {
+	for _, p := range r.job.Phases {
+		if p.Type == t {
+			return &phaseRun{run: r, phase: p}
+		}
+	}
+
+	return nil
+}
+
+// snapshot returns a deep copy of the job that is safe to hand out while it runs.
+func (r *jobRun) snapshot() *models.SyncJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j := *r.job
+	j.Phases = make([]*models.SyncPhase, len(r.job.Phases))
+	for i, p := range r.job.Phases {
+		cp := *p
+		cp.Errors = append([]models.SyncItemError(nil), p.Errors...)
+		j.Phases[i] = &cp
+	}
+
+	return &j
+}
+
+func (p *phaseRun) update(fn func(ph *models.SyncPhase)) {
+	if p == nil {
+		return
+	}
+
+	p.run.mu.Lock()
+	defer p.run.mu.Unlock()
+	fn(p.phase)
+}
+
+func (p *phaseRun) addFetched(n int) {
+	p.update(func(ph *models.SyncPhase) { ph.Fetched += n })
+}
+
+func (p *phaseRun) addUpserted(n int) {
+	p.update(func(ph *models.SyncPhase) { ph.Upserted += n })
+}
+
+func (p *phaseRun) addSoftDeleted(n int) {
+	p.update(func(ph *models.SyncPhase) { ph.SoftDeleted += n })
+}
+
+func (p *phaseRun) addFailed(item string, err error) {
+	p.update(func(ph *models.SyncPhase) {
+		ph.Failed++
+		if len(ph.Errors) < maxPhaseErrors {
+			ph.Errors = append(ph.Errors, models.SyncItemError{
+				Item:  item,
+				Error: err.Error(),
+				Time:  time.Now(),
+			})
+		}
+	})
+}
+
+// CurrentJob returns a snapshot of the running job, or nil if nothing is running.
+func (s *Service) CurrentJob() *models.SyncJob {
+	s.mu.Lock()
+	r := s.current
+	s.mu.Unlock()
+
+	if r == nil {
+		return nil
+	}
+
+	return r.snapshot()
+}
+
+func (s *Service) ListJobs(ctx context.Context, f *models.SyncJobFilter) ([]*models.SyncJob, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultJobPageSize
+	}
+	if f.Limit > maxJobPageSize {
+		f.Limit = maxJobPageSize
+	}
+
+	jobs, err := s.Jobs.List(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("listing sync jobs from store: %w", err)
+	}
+
+	// the stored copy of the running job only has its starting state
+	if cur := s.CurrentJob(); cur != nil {
+		for i, j := range jobs {
+			if j.ID == cur.ID {
+				jobs[i] = cur
+			}
+		}
+	}
+
+	return jobs, nil
+}
+
+func (s *Service) GetJob(ctx context.Context, id int) (*models.SyncJob, error) {
+	if cur := s.CurrentJob(); cur != nil && cur.ID == id {
+		return cur, nil
+	}
+
+	return s.Jobs.Get(ctx, id)
+}
+
+// CancelJob stops the running job's workers through its context. The job is marked
+// cancelled once its phases have wound down.
+func (s *Service) CancelJob(ctx context.Context, id int) error {
+	s.mu.Lock()
+	r := s.current
+	s.mu.Unlock()
+
+	if r == nil || r.job.ID != id {
+		if _, err := s.Jobs.Get(ctx, id); err != nil {
+			return err
+		}
+		return models.ErrSyncJobNotRunning
+	}
+
+	slog.Info("sync: cancelling job", "job_id", id)
+	r.cancel()
+	return nil
+}
+
+// MarkInterruptedJobs fails any jobs left running by a previous process, which
+// can't be resumed. Call it once on startup before any syncs are started.
+func (s *Service) MarkInterruptedJobs(ctx context.Context) error {
+	n, err := s.Jobs.InterruptRunning(ctx)
+	if err != nil {
+		return fmt.Errorf("marking interrupted sync jobs: %w", err)
+	}
+
+	if n > 0 {
+		slog.Warn("sync: marked jobs interrupted by shutdown as failed", "total_jobs", n)
+	}
+
+	return nil
+}
+
+func newJob(payload *models.SyncPayload, trigger string) *models.SyncJob {
+	j := &models.SyncJob{
+		TriggeredBy: trigger,
+		Status:      models.SyncJobRunning,
+		Payload:     *payload,
+		Phases:      []*models.SyncPhase{},
+		StartedOn:   time.Now(),
+	}
+
+	for _, t := range payloadSyncTypes(payload) {
+		j.Phases = append(j.Phases, &models.SyncPhase{
+			Type:   t,
+			Status: models.SyncJobRunning,
+		})
+	}
+
+	return j
+}
+
+func payloadSyncTypes(p *models.SyncPayload) []models.SyncType {
+	var types []models.SyncType
+	if p.CWBoards {
+		types = append(types, models.SyncTypeBoards)
+	}
+	if p.WebexRecipients {
+		types = append(types, models.SyncTypeRecipients)
+	}
+	if p.CWTickets {
+		types = append(types, models.SyncTypeOpenTickets)
+	}
+	if p.CWTicketUpdates {
+		types = append(types, models.SyncTypeTicketUpdates)
+	}
+
+	return types
+}
+
+// phaseStatus maps a phase or job result to its final status.
+func phaseStatus(ctx context.Context, err error) models.SyncJobStatus {
+	switch {
+	case err == nil:
+		return models.SyncJobSucceeded
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		return models.SyncJobCancelled
+	default:
+		return models.SyncJobFailed
+	}
+}
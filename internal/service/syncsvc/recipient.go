@@ -7,12 +7,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/thecoretg/ticketbot/models"
 	"github.com/thecoretg/tctg-go/connectwise/psa"
 	"github.com/thecoretg/tctg-go/webex"
+	"github.com/thecoretg/ticketbot/models"
 )
 
-func (s *Service) SyncWebexRecipients(ctx context.Context, maxSyncs int) error {
+func (s *Service) SyncWebexRecipients(ctx context.Context, maxSyncs int, ph *phaseRun) error {
 	slog.Info("beginning webex room sync")
 	start := time.Now()
 	defer func() {
@@ -30,11 +30,11 @@ func (s *Service) SyncWebexRecipients(ctx context.Context, maxSyncs int) error {
 		_ = tx.Rollback(ctx)
 	}()
 
-	if err := txSvc.syncWebexRooms(ctx); err != nil {
+	if err := txSvc.syncWebexRooms(ctx, ph); err != nil {
 		return fmt.Errorf("syncing webex rooms: %w", err)
 	}
 
-	if err := txSvc.syncWebexPeople(ctx, maxSyncs); err != nil {
+	if err := txSvc.syncWebexPeople(ctx, maxSyncs, ph); err != nil {
 		return fmt.Errorf("syncing webex people: %w", err)
 	}
 
@@ -45,7 +45,7 @@ func (s *Service) SyncWebexRecipients(ctx context.Context, maxSyncs int) error {
 	return nil
 }
 
-func (s *Service) syncWebexRooms(ctx context.Context) error {
+func (s *Service) syncWebexRooms(ctx context.Context, ph *phaseRun) error {
 	start := time.Now()
 	defer func() {
 		slog.Info("webex room sync complete", "took_time", time.Since(start).Seconds())
@@ -56,6 +56,7 @@ func (s *Service) syncWebexRooms(ctx context.Context) error {
 		return fmt.Errorf("getting rooms from webex: %w", err)
 	}
 	slog.Info("webex room sync: got rooms from webex", "total_rooms", len(wr))
+	ph.addFetched(len(wr))
 
 	// get current rooms from store
 	sr, err := s.Webex.Recipients.ListRooms(ctx)
@@ -68,12 +69,13 @@ func (s *Service) syncWebexRooms(ctx context.Context) error {
 		if _, err := s.Webex.Recipients.Upsert(ctx, r); err != nil {
 			return fmt.Errorf("upserting room with name %s: %w", r.Name, err)
 		}
+		ph.addUpserted(1)
 	}
 
 	return nil
 }
 
-func (s *Service) syncWebexPeople(ctx context.Context, maxSyncs int) error {
+func (s *Service) syncWebexPeople(ctx context.Context, maxSyncs int, ph *phaseRun) error {
 	start := time.Now()
 	defer func() {
 		slog.Info("webex people sync complete", "took_time", time.Since(start).Seconds())
@@ -95,17 +97,20 @@ func (s *Service) syncWebexPeople(ctx context.Context, maxSyncs int) error {
 	if err != nil {
 		return fmt.Errorf("getting webex people from connectwise members: %w", err)
 	}
+	ph.addFetched(len(wp))
 
 	for _, p := range peopleToRecipients(wp) {
 		if _, err := s.Webex.Recipients.Upsert(ctx, p); err != nil {
 			return fmt.Errorf("upserting person with name %s: %w", p.Name, err)
 		}
+		ph.addUpserted(1)
 	}
 
 	for _, d := range peopleToDelete(cwm, sp) {
 		if err := s.Webex.Recipients.Delete(ctx, d.ID); err != nil {
 			return fmt.Errorf("deleting person with id %d (%s): %w", d.ID, d.Name, err)
 		}
+		ph.addSoftDeleted(1)
 	}
 
 	return nil
@@ -122,7 +127,9 @@ func (s *Service) getWxPeopleFromCwMembers(ctx context.Context, members []psa.Me
 	)
 
 	for _, m := range members {
-		sem <- struct{}{}
+		if !acquire(ctx, sem) {
+			break
+		}
 		wg.Add(1)
 		go func(member psa.Member) {
 			defer func() { <-sem }()
@@ -155,6 +162,10 @@ func (s *Service) getWxPeopleFromCwMembers(ctx context.Context, members []psa.Me
 		return nil, <-errCh
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return wp, nil
 }
 
@@ -0,0 +1,97 @@
+package syncsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// schedulerTick is how often the scheduler checks for due syncs. Intervals are
+// configured in minutes, so checking more often than this gains nothing.
+const schedulerTick = time.Minute
+
+// StartScheduler launches the goroutine that runs each sync type on the interval set in
+// the config. It returns immediately; the goroutine stops when ctx is cancelled.
+// Intervals are measured from the persisted start of each type's last run, so a restart
+// doesn't reset the schedule.
+func (s *Service) StartScheduler(ctx context.Context) {
+	go s.runScheduler(ctx)
+}
+
+func (s *Service) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runDueSyncs(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) runDueSyncs(ctx context.Context) {
+	// a manual sync is running; anything due will be picked up on a later tick
+	if s.IsSyncing() {
+		return
+	}
+
+	p, err := s.duePayload(ctx)
+	if err != nil {
+		slog.Warn("sync scheduler: checking for due syncs", "error", err.Error())
+		return
+	}
+
+	if p == nil {
+		return
+	}
+
+	// a manual sync may have started since the check above, which is fine to skip
+	if _, err := s.Sync(ctx, p, models.SyncTriggerSchedule); err != nil && !errors.Is(err, models.ErrSyncInProgress) {
+		slog.Warn("sync scheduler: starting scheduled sync", "error", err.Error())
+	}
+}
+
+// duePayload builds a sync payload for every sync type whose interval has elapsed since
+// its last run. It returns nil if nothing is due.
+func (s *Service) duePayload(ctx context.Context) (*models.SyncPayload, error) {
+	states, err := s.State.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing sync states: %w", err)
+	}
+
+	lastRun := make(map[models.SyncType]time.Time)
+	for _, st := range states {
+		if st.LastStarted != nil {
+			lastRun[st.SyncType] = *st.LastStarted
+		}
+	}
+
+	due := func(t models.SyncType, minutes int) bool {
+		if minutes <= 0 {
+			return false
+		}
+		return time.Since(lastRun[t]) >= time.Duration(minutes)*time.Minute
+	}
+
+	cfg := s.cfg.Load()
+	p := &models.SyncPayload{
+		CWBoards:           due(models.SyncTypeBoards, cfg.SyncBoardsIntervalMinutes),
+		WebexRecipients:    due(models.SyncTypeRecipients, cfg.SyncRecipientsIntervalMinutes),
+		CWTickets:          due(models.SyncTypeOpenTickets, cfg.SyncOpenTicketsIntervalMinutes),
+		CWTicketUpdates:    due(models.SyncTypeTicketUpdates, cfg.SyncTicketUpdatesIntervalMinutes),
+		MaxConcurrentSyncs: cfg.MaxConcurrentSyncs,
+	}
+
+	if !p.CWBoards && !p.WebexRecipients && !p.CWTickets && !p.CWTicketUpdates {
+		return nil, nil
+	}
+
+	return p, nil
+}
@@ -1,10 +1,12 @@
 package syncsvc
 
 import (
-	"sync/atomic"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
 	"github.com/thecoretg/ticketbot/internal/service/cwsvc"
 	"github.com/thecoretg/ticketbot/internal/service/notifier"
 	"github.com/thecoretg/ticketbot/internal/service/webexsvc"
@@ -14,15 +16,23 @@ type Service struct {
 	CW       *cwsvc.Service
 	Webex    *webexsvc.Service
 	Notifier *notifier.Service
+	Jobs     repos.SyncJobRepository
+	State    repos.SyncStateRepository
+	cfg      *runtimecfg.Store
 	pool     *pgxpool.Pool
-	syncing  atomic.Bool
+
+	mu      sync.Mutex
+	current *jobRun
 }
 
-func New(pool *pgxpool.Pool, cw *cwsvc.Service, wx *webexsvc.Service, ns *notifier.Service) *Service {
+func New(pool *pgxpool.Pool, cfg *runtimecfg.Store, cw *cwsvc.Service, wx *webexsvc.Service, ns *notifier.Service, jobs repos.SyncJobRepository, state repos.SyncStateRepository) *Service {
 	return &Service{
 		CW:       cw,
 		Webex:    wx,
 		Notifier: ns,
+		Jobs:     jobs,
+		State:    state,
+		cfg:      cfg,
 		pool:     pool,
 	}
 }
@@ -31,6 +41,9 @@ func (s *Service) withTx(tx pgx.Tx) *Service {
 	return &Service{
 		CW:    s.CW.WithTX(tx),
 		Webex: s.Webex.WithTx(tx),
+		Jobs:  s.Jobs,
+		State: s.State,
+		cfg:   s.cfg,
 		pool:  s.pool,
 	}
 }
@@ -2,16 +2,29 @@ package syncsvc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/models"
 )
 
-func (s *Service) SyncOpenTickets(ctx context.Context, boardIDs []int, maxSyncs int) error {
+const (
+	// ticketUpdateOverlap is subtracted from the watermark when querying Connectwise, so
+	// tickets updated while the previous run was starting aren't missed due to clock drift.
+	ticketUpdateOverlap = 2 * time.Minute
+
+	// ticketUpdateInitialLookback is how far back the first incremental sync looks when
+	// there is no watermark yet.
+	ticketUpdateInitialLookback = 24 * time.Hour
+)
+
+func (s *Service) SyncOpenTickets(ctx context.Context, boardIDs []int, maxSyncs int, ph *phaseRun) error {
 	start := time.Now()
 	slog.Info("cwsvc: beginning ticket sync", "board_ids", boardIDs)
 	defer func() {
@@ -35,39 +48,125 @@ func (s *Service) SyncOpenTickets(ctx context.Context, boardIDs []int, maxSyncs
 		return fmt.Errorf("getting open tickets from connectwise: %w", err)
 	}
 	slog.Info("cwsvc: open ticket sync: got open tickets from connectwise", "total_tickets", len(tix))
+	ph.addFetched(len(tix))
+
+	s.processTickets(ctx, tix, maxSyncs, "ticket sync", ph)
+	return ctx.Err()
+}
+
+// SyncTicketUpdates processes every ticket, open or closed, updated in Connectwise since the
+// last successful run. This heals anything missed by webhooks without pulling whole boards.
+// The watermark only advances when all tickets were processed and no board filter was used,
+// so failed tickets are picked up again on the next run.
+func (s *Service) SyncTicketUpdates(ctx context.Context, boardIDs []int, maxSyncs int, ph *phaseRun) error {
+	start := time.Now()
+
+	since := start.Add(-ticketUpdateInitialLookback)
+	st, err := s.State.Get(ctx, models.SyncTypeTicketUpdates)
+	if err != nil && !errors.Is(err, models.ErrSyncStateNotFound) {
+		return fmt.Errorf("getting ticket update watermark: %w", err)
+	}
+	if st != nil && st.Watermark != nil {
+		since = st.Watermark.Add(-ticketUpdateOverlap)
+	}
+
+	slog.Info("sync: beginning ticket update sync", "since", since, "board_ids", boardIDs)
+	defer func() {
+		slog.Info("sync: ticket update sync complete", "took_time", time.Since(start))
+	}()
+
+	con := fmt.Sprintf("lastUpdated > [%s]", since.UTC().Format(time.RFC3339))
+	if len(boardIDs) > 0 {
+		con += fmt.Sprintf(" AND %s", boardIDParam(boardIDs))
+	}
+
+	params := map[string]string{
+		"pageSize":   "100",
+		"conditions": con,
+	}
+
+	tix, err := s.CW.CWClient.ListTickets(ctx, params)
+	if err != nil {
+		return fmt.Errorf("getting updated tickets from connectwise: %w", err)
+	}
+	slog.Info("sync: ticket update sync: got updated tickets from connectwise", "total_tickets", len(tix))
+	ph.addFetched(len(tix))
+
+	failed := s.processTickets(ctx, tix, maxSyncs, "ticket update sync", ph)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d updated tickets failed to sync", failed, len(tix))
+	}
+
+	if len(boardIDs) > 0 {
+		return nil
+	}
+
+	if err := s.State.SetWatermark(ctx, models.SyncTypeTicketUpdates, start); err != nil {
+		return fmt.Errorf("saving ticket update watermark: %w", err)
+	}
+
+	return nil
+}
+
+// processTickets runs each ticket through the regular ticket processing with at most maxSyncs
+// at once, and marks their latest notes as skipped so syncs never send notifications. Errors
+// are logged and recorded on the phase, and the number of failed tickets is returned. No new
+// tickets are started once ctx is cancelled.
+func (s *Service) processTickets(ctx context.Context, tix []psa.Ticket, maxSyncs int, caller string, ph *phaseRun) int {
 	sem := make(chan struct{}, maxSyncs)
-	var wg sync.WaitGroup
-	errCh := make(chan error, len(tix))
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int64
+	)
+
+	fail := func(ticketID int, err error) {
+		failed.Add(1)
+		slog.Error("sync: syncing ticket", "caller", caller, "ticket_id", ticketID, "error", err.Error())
+		ph.addFailed(fmt.Sprintf("ticket %d", ticketID), err)
+	}
 
 	for _, t := range tix {
-		sem <- struct{}{}
+		if !acquire(ctx, sem) {
+			break
+		}
 		wg.Add(1)
 		go func(ticket psa.Ticket) {
 			defer func() { <-sem }()
 			defer wg.Done()
 			ft, err := s.CW.ProcessTicket(ctx, ticket.ID, "sync")
 			if err != nil {
-				errCh <- fmt.Errorf("error syncing ticket %d: %w", ticket.ID, err)
+				fail(ticket.ID, fmt.Errorf("error syncing ticket: %w", err))
 				return
 			}
 
-			if err := s.Notifier.AddSkippedNotification(ctx, ft, "ticket sync"); err != nil {
-				errCh <- fmt.Errorf("skipping notification for ticket %d note %d: %w", ft.Ticket.ID, ft.LatestNote.ID, err)
+			if err := s.Notifier.AddSkippedNotification(ctx, ft, caller); err != nil {
+				fail(ticket.ID, fmt.Errorf("skipping notification for note %d: %w", ft.LatestNote.ID, err))
 				return
 			}
+			ph.addUpserted(1)
 		}(t)
 	}
 
 	wg.Wait()
-	close(errCh)
+	return int(failed.Load())
+}
 
-	for err := range errCh {
-		if err != nil {
-			slog.Error("sync: syncing open ticket", "error", err.Error())
-		}
+// acquire takes a worker slot from sem, returning false instead if ctx is cancelled first.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
 	}
 
-	return nil
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func boardIDParam(ids []int) string {
@@ -2,24 +2,30 @@ package ticketbot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 	"time"
 
-	"github.com/thecoretg/ticketbot/models"
+	"github.com/thecoretg/ticketbot/internal/cwclient"
+	"github.com/thecoretg/ticketbot/internal/metrics"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
 	"github.com/thecoretg/ticketbot/internal/service/cwsvc"
 	"github.com/thecoretg/ticketbot/internal/service/notifier"
+	"github.com/thecoretg/ticketbot/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Service struct {
-	Cfg         *models.Config
+	Cfg         *runtimecfg.Store
 	CW          *cwsvc.Service
 	Notifier    *notifier.Service
 	ticketLocks sync.Map
 }
 
-func New(cfg *models.Config, cw *cwsvc.Service, ns *notifier.Service) *Service {
+func New(cfg *runtimecfg.Store, cw *cwsvc.Service, ns *notifier.Service) *Service {
 	return &Service{
 		Cfg:      cfg,
 		CW:       cw,
@@ -29,15 +35,26 @@ func New(cfg *models.Config, cw *cwsvc.Service, ns *notifier.Service) *Service {
 
 func (s *Service) ProcessTicket(ctx context.Context, id int) (err error) {
 	start := time.Now()
-	slog.Debug("ticketbot: request received", "ticket_id", id)
+	ctx, span := tracing.Start(ctx, "ticketbot.ProcessTicket", trace.WithAttributes(attribute.Int("ticket.id", id)))
+	slog.DebugContext(ctx, "ticketbot: request received", "ticket_id", id)
 
 	defer func() {
-		took := time.Since(start).Seconds()
+		took := time.Since(start)
+		if errors.Is(err, cwclient.ErrCircuitOpen) {
+			metrics.TicketProcessed(took, metrics.ResultDeferred)
+			span.SetAttributes(attribute.Bool("deferred", true))
+			span.End()
+			slog.WarnContext(ctx, "ticketbot: connectwise unavailable", "ticket_id", id, "took_seconds", took.Seconds())
+			return
+		}
+		tracing.End(span, err)
 		if err != nil {
-			slog.Error("ticketbot: request finished with error", "ticket_id", id, "took_seconds", took, "error", err.Error())
+			metrics.TicketProcessed(took, metrics.ResultError)
+			slog.ErrorContext(ctx, "ticketbot: request finished with error", "ticket_id", id, "took_seconds", took.Seconds(), "error", err.Error())
 			return
 		}
-		slog.Debug("ticketbot: request finished", "ticket_id", id, "took_seconds", took)
+		metrics.TicketProcessed(took, metrics.ResultOK)
+		slog.DebugContext(ctx, "ticketbot: request finished", "ticket_id", id, "took_seconds", took.Seconds())
 	}()
 
 	// Prevent a ticket from processing multiple times to prevent duplicate notifications.
@@ -57,14 +74,15 @@ func (s *Service) ProcessTicket(ctx context.Context, id int) (err error) {
 		return fmt.Errorf("processing ticket %d: %w", id, err)
 	}
 
-	if s.Cfg.AttemptNotify {
+	if s.Cfg.Load().AttemptNotify {
 		if err := s.Notifier.Run(ctx, ticket, isNew); err != nil {
 			return fmt.Errorf("running notifier for ticket %d: %w", id, err)
 		}
 		return nil
 	}
 
-	slog.Debug("ticketbot: attempt notify disabled", "ticket_id", id)
+	slog.DebugContext(ctx, "ticketbot: attempt notify disabled", "ticket_id", id)
+	metrics.NotificationSkipped("attempt notify disabled")
 	if err := s.Notifier.AddSkippedNotification(ctx, ticket, "ticketbot"); err != nil {
 		return fmt.Errorf("skipping notification for ticket %d note %d: %w", ticket.Ticket.ID, ticket.LatestNote.ID, err)
 	}
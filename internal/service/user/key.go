@@ -2,12 +2,11 @@ package user
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"log/slog"
 
+	"github.com/thecoretg/ticketbot/internal/apikey"
 	"github.com/thecoretg/ticketbot/models"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -33,7 +32,7 @@ func (s *Service) BootstrapAdmin(ctx context.Context, email string, initialPassw
 	}
 
 	slog.Info("initial admin not found; creating now", "email", email)
-	u, err := s.Users.Insert(ctx, email)
+	u, err := s.Users.Insert(ctx, email, models.RoleAdmin)
 	if err != nil {
 		return fmt.Errorf("creating user: %w", err)
 	}
@@ -60,51 +59,30 @@ func (s *Service) BootstrapAdmin(ctx context.Context, email string, initialPassw
 	return nil
 }
 
-func (s *Service) createAPIKey(ctx context.Context, email string, explicitKey *string) (string, error) {
-	u, err := s.Users.GetByEmail(ctx, email)
+// createAPIKey generates a key, stores its hash and lookup ID on k, and returns the plaintext along
+// with the stored key.
+func (s *Service) createAPIKey(ctx context.Context, k *models.APIKey) (string, *models.APIKey, error) {
+	plain, lookupID, err := apikey.Generate()
 	if err != nil {
-		if errors.Is(err, models.ErrAPIUserNotFound) {
-			return "", err
-		}
-		return "", fmt.Errorf("getting user by email: %w", err)
-	}
-
-	plain, err := generateKey()
-	if err != nil {
-		return "", err
-	}
-
-	if explicitKey != nil {
-		plain = *explicitKey
+		return "", nil, err
 	}
 
 	hash, err := hashKey(plain)
 	if err != nil {
-		return "", fmt.Errorf("hashing key: %w", err)
+		return "", nil, fmt.Errorf("hashing key: %w", err)
 	}
 
 	hint := generateKeyHint(plain)
-	p := &models.APIKey{
-		UserID:  u.ID,
-		KeyHash: hash,
-		KeyHint: &hint,
-	}
+	k.KeyHash = hash
+	k.KeyHint = &hint
+	k.LookupID = &lookupID
 
-	_, err = s.Keys.Insert(ctx, p)
+	created, err := s.Keys.Insert(ctx, k)
 	if err != nil {
-		return "", fmt.Errorf("storing key: %w", err)
-	}
-
-	return plain, nil
-}
-
-func generateKey() (string, error) {
-	raw := make([]byte, 32)
-	if _, err := rand.Read(raw); err != nil {
-		return "", fmt.Errorf("generating key: %w", err)
+		return "", nil, fmt.Errorf("storing key: %w", err)
 	}
 
-	return base64.RawURLEncoding.EncodeToString(raw), nil
+	return plain, created, nil
 }
 
 func hashKey(key string) ([]byte, error) {
@@ -2,7 +2,9 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/thecoretg/ticketbot/internal/repos"
 	"github.com/thecoretg/ticketbot/models"
@@ -23,6 +25,35 @@ func (e ErrCannotDeleteSelf) Error() string {
 	return "cannot delete your own user account"
 }
 
+type ErrCannotChangeOwnRole struct{}
+
+func (e ErrCannotChangeOwnRole) Error() string {
+	return "cannot change your own role"
+}
+
+// ErrRoleTooHigh is returned when an API key is requested with a role above its owner's, or above
+// the role of the request asking for it.
+type ErrRoleTooHigh struct {
+	Max models.Role
+}
+
+func (e ErrRoleTooHigh) Error() string {
+	return fmt.Sprintf("role cannot be higher than %s", e.Max)
+}
+
+// ErrScopesTooBroad is returned when a scoped key is used to create a key with scopes it doesn't have.
+type ErrScopesTooBroad struct{}
+
+func (e ErrScopesTooBroad) Error() string {
+	return "a key's scopes cannot exceed the scopes of the key used to create it"
+}
+
+type ErrKeyNotOwned struct{}
+
+func (e ErrKeyNotOwned) Error() string {
+	return "only admins can manage api keys for other users"
+}
+
 type Service struct {
 	Users repos.APIUserRepository
 	Keys  repos.APIKeyRepository
@@ -47,7 +78,15 @@ func (s *Service) GetUserByEmail(ctx context.Context, email string) (*models.API
 	return s.Users.GetByEmail(ctx, email)
 }
 
-func (s *Service) InsertUser(ctx context.Context, email string) (*models.APIUser, error) {
+// InsertUser creates a user with the given role, or viewer if it's empty.
+func (s *Service) InsertUser(ctx context.Context, email string, role models.Role) (*models.APIUser, error) {
+	if role == "" {
+		role = models.RoleViewer
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidRole, role)
+	}
+
 	exists, err := s.Users.Exists(ctx, email)
 	if err != nil {
 		return nil, fmt.Errorf("checking if user exists: %w", err)
@@ -57,12 +96,12 @@ func (s *Service) InsertUser(ctx context.Context, email string) (*models.APIUser
 		return nil, ErrUserAlreadyExists{Email: email}
 	}
 
-	return s.Users.Insert(ctx, email)
+	return s.Users.Insert(ctx, email, role)
 }
 
 // InsertUserWithPassword creates a user and sets a temporary password that must be changed on first login.
-func (s *Service) InsertUserWithPassword(ctx context.Context, email, password string) (*models.APIUser, error) {
-	u, err := s.InsertUser(ctx, email)
+func (s *Service) InsertUserWithPassword(ctx context.Context, email, password string, role models.Role) (*models.APIUser, error) {
+	u, err := s.InsertUser(ctx, email, role)
 	if err != nil {
 		return nil, err
 	}
@@ -90,19 +129,197 @@ func (s *Service) DeleteUser(ctx context.Context, id int, authenticatedUserID in
 	return s.Users.Delete(ctx, id)
 }
 
-func (s *Service) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
-	return s.Keys.List(ctx)
+// SetRole changes a user's role. Admins can't change their own, so there is always at least one admin.
+func (s *Service) SetRole(ctx context.Context, id int, role models.Role, authenticatedUserID int) (*models.APIUser, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidRole, role)
+	}
+	if id == authenticatedUserID {
+		return nil, ErrCannotChangeOwnRole{}
+	}
+
+	return s.Users.SetRole(ctx, id, role)
 }
 
-func (s *Service) GetAPIKey(ctx context.Context, id int) (*models.APIKey, error) {
-	return s.Keys.Get(ctx, id)
+// ListAPIKeys returns every key for admins, and only the caller's own keys for anyone else.
+func (s *Service) ListAPIKeys(ctx context.Context, actor *models.Actor) ([]*models.APIKey, error) {
+	keys, err := s.Keys.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.Role.AtLeast(models.RoleAdmin) {
+		return keys, nil
+	}
+
+	var own []*models.APIKey
+	for _, k := range keys {
+		if k.UserID == actor.UserID {
+			own = append(own, k)
+		}
+	}
+
+	return own, nil
 }
 
-// AddAPIKey creates an API key and returns the plaintext (only once)
-func (s *Service) AddAPIKey(ctx context.Context, email string) (string, error) {
-	return s.createAPIKey(ctx, email, nil)
+// GetAPIKey returns ErrAPIKeyNotFound for keys a non-admin doesn't own, rather than revealing they exist.
+func (s *Service) GetAPIKey(ctx context.Context, id int, actor *models.Actor) (*models.APIKey, error) {
+	k, err := s.Keys.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.UserID != actor.UserID && !actor.Role.AtLeast(models.RoleAdmin) {
+		return nil, models.ErrAPIKeyNotFound
+	}
+
+	return k, nil
 }
 
-func (s *Service) DeleteAPIKey(ctx context.Context, id int) error {
+// AddAPIKey creates an API key and returns the plaintext (only once). Non-admins can only create
+// keys for themselves. The key's role defaults to the owner's, and can't be higher than either the
+// owner's role or the actor's. If the actor is using a scoped key, the new key's scopes must fall
+// within it.
+func (s *Service) AddAPIKey(ctx context.Context, p *models.CreateAPIKeyPayload, actor *models.Actor) (*models.CreateAPIKeyResponse, error) {
+	var (
+		owner *models.APIUser
+		err   error
+	)
+
+	if p.Email == "" {
+		owner, err = s.Users.Get(ctx, actor.UserID)
+	} else {
+		owner, err = s.Users.GetByEmail(ctx, p.Email)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrAPIUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("getting key owner: %w", err)
+	}
+
+	if owner.ID != actor.UserID && !actor.Role.AtLeast(models.RoleAdmin) {
+		return nil, ErrKeyNotOwned{}
+	}
+
+	role := p.Role
+	if role == "" {
+		role = models.LowerRole(owner.Role, actor.Role)
+	}
+
+	k := &models.APIKey{
+		UserID:    owner.ID,
+		Role:      role,
+		Label:     p.Label,
+		Scopes:    p.Scopes,
+		ExpiresOn: p.ExpiresOn,
+	}
+	if err := checkKeyGrant(k, owner, actor); err != nil {
+		return nil, err
+	}
+
+	return s.issueAPIKey(ctx, k, owner)
+}
+
+// RotateAPIKey issues a replacement for a key with the same owner, role, label and scopes, and sets
+// the old key to expire after grace so clients can switch over. If the old key had an expiry, the new
+// one gets the same lifetime starting now.
+func (s *Service) RotateAPIKey(ctx context.Context, id int, grace time.Duration, actor *models.Actor) (*models.CreateAPIKeyResponse, error) {
+	old, err := s.GetAPIKey(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if old.Expired(now) {
+		return nil, models.ErrAPIKeyExpired
+	}
+
+	owner, err := s.Users.Get(ctx, old.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting key owner: %w", err)
+	}
+
+	k := &models.APIKey{
+		UserID: old.UserID,
+		Role:   old.Role,
+		Label:  old.Label,
+		Scopes: old.Scopes,
+	}
+	if old.ExpiresOn != nil {
+		exp := now.Add(old.ExpiresOn.Sub(old.CreatedOn))
+		k.ExpiresOn = &exp
+	}
+	if err := checkKeyGrant(k, owner, actor); err != nil {
+		return nil, err
+	}
+
+	res, err := s.issueAPIKey(ctx, k, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	oldExpiry := now.Add(grace)
+	if old.ExpiresOn != nil && old.ExpiresOn.Before(oldExpiry) {
+		oldExpiry = *old.ExpiresOn
+	}
+	if _, err := s.Keys.SetExpiry(ctx, old.ID, &oldExpiry); err != nil {
+		return nil, fmt.Errorf("setting expiry on rotated key: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s *Service) DeleteAPIKey(ctx context.Context, id int, actor *models.Actor) error {
+	if _, err := s.GetAPIKey(ctx, id, actor); err != nil {
+		return err
+	}
+
 	return s.Keys.Delete(ctx, id)
 }
+
+// checkKeyGrant makes sure a key doesn't give more access than its owner has, or than the actor
+// creating it has.
+func checkKeyGrant(k *models.APIKey, owner *models.APIUser, actor *models.Actor) error {
+	if !k.Role.Valid() {
+		return fmt.Errorf("%w: %q", models.ErrInvalidRole, k.Role)
+	}
+	if !owner.Role.AtLeast(k.Role) {
+		return ErrRoleTooHigh{Max: owner.Role}
+	}
+	if !actor.Role.AtLeast(k.Role) {
+		return ErrRoleTooHigh{Max: actor.Role}
+	}
+
+	for _, sc := range k.Scopes {
+		if !models.ValidScope(sc) {
+			return fmt.Errorf("%w: %q", models.ErrInvalidScope, sc)
+		}
+	}
+	if !models.ScopesWithin(k.Scopes, actor.Scopes) {
+		return ErrScopesTooBroad{}
+	}
+
+	if k.ExpiresOn != nil && !k.ExpiresOn.After(time.Now()) {
+		return models.ErrInvalidKeyExpiry
+	}
+
+	return nil
+}
+
+func (s *Service) issueAPIKey(ctx context.Context, k *models.APIKey, owner *models.APIUser) (*models.CreateAPIKeyResponse, error) {
+	plain, created, err := s.createAPIKey(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreateAPIKeyResponse{
+		ID:        created.ID,
+		Email:     owner.EmailAddress,
+		Key:       plain,
+		Role:      created.Role,
+		Label:     created.Label,
+		Scopes:    created.Scopes,
+		ExpiresOn: created.ExpiresOn,
+	}, nil
+}
@@ -0,0 +1,255 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thecoretg/tctg-go/webex"
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// monitorTick is how often the monitor checks whether the callbacks are due to be
+// reconciled and whether they have gone quiet.
+const monitorTick = time.Minute
+
+// MarkCWHookReceived records that a Connectwise callback was just delivered.
+func (s *Service) MarkCWHookReceived() {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health.LastReceived = &now
+	s.health.AlertSent = false
+}
+
+func (s *Service) Health() models.HookHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+func (s *Service) recordCheck(err error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health.LastChecked = &now
+	s.health.LastCheckError = nil
+	if err != nil {
+		msg := err.Error()
+		s.health.LastCheckError = &msg
+	}
+}
+
+func (s *Service) countReactivated() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health.Reactivated++
+}
+
+// StartMonitor launches the goroutine that reconciles the Connectwise callbacks on the
+// configured interval, and alerts the configured Webex recipient when no callbacks have
+// arrived for too long during business hours. It returns immediately; the goroutine
+// stops when ctx is cancelled.
+func (s *Service) StartMonitor(ctx context.Context) {
+	go s.runMonitor(ctx)
+}
+
+func (s *Service) runMonitor(ctx context.Context) {
+	s.loadLastReceived(ctx)
+
+	ticker := time.NewTicker(monitorTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.saveLastReceived(ctx)
+			s.checkCallbacks(ctx)
+			s.checkSilence(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// saveLastReceived stores when a callback was last received, so the silence check carries on
+// from it after a restart and sees callbacks delivered to other instances. It's written once a
+// tick rather than on every callback.
+func (s *Service) saveLastReceived(ctx context.Context) {
+	s.mu.Lock()
+	last := s.health.LastReceived
+	if last == nil || !last.After(s.savedReceived) {
+		s.mu.Unlock()
+		return
+	}
+	received := *last
+	s.mu.Unlock()
+
+	if err := s.HookHealth.SetLastReceived(ctx, received); err != nil {
+		slog.Error("hook monitor: saving last received callback time", "error", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.savedReceived = received
+	s.mu.Unlock()
+}
+
+// loadLastReceived picks up a later stored callback time than the one seen by this instance.
+func (s *Service) loadLastReceived(ctx context.Context) {
+	stored, err := s.HookHealth.LastReceived(ctx)
+	if err != nil {
+		slog.Error("hook monitor: loading last received callback time", "error", err.Error())
+		return
+	}
+	if stored == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// a callback delivered to another instance ends the silence here too
+	if s.health.LastReceived == nil || stored.After(*s.health.LastReceived) {
+		s.health.LastReceived = stored
+		s.health.AlertSent = false
+	}
+	if stored.After(s.savedReceived) {
+		s.savedReceived = *stored
+	}
+}
+
+func (s *Service) checkCallbacks(ctx context.Context) {
+	interval := time.Duration(s.cfg.Load().HookCheckIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		return
+	}
+
+	h := s.Health()
+	if h.LastChecked != nil && time.Since(*h.LastChecked) < interval {
+		return
+	}
+
+	if err := s.ProcessCWHooks(ctx); err != nil {
+		slog.Error("hook monitor: reconciling connectwise callbacks", "error", err.Error())
+	}
+}
+
+func (s *Service) checkSilence(ctx context.Context) {
+	cfg := s.cfg.Load()
+	silence := time.Duration(cfg.HookSilenceMinutes) * time.Minute
+	recipID := cfg.HookAlertRecipientID
+	if silence <= 0 || recipID == nil {
+		return
+	}
+
+	now := time.Now()
+	dayStart, open := businessHours(now, cfg)
+	if !open {
+		return
+	}
+
+	s.loadLastReceived(ctx)
+
+	// hooks are expected to be quiet outside business hours, so the silence is measured
+	// from the start of the day at the earliest
+	s.mu.Lock()
+	since := s.startedOn
+	if s.health.LastReceived != nil {
+		since = *s.health.LastReceived
+	}
+	if dayStart.After(since) {
+		since = dayStart
+	}
+
+	if s.health.AlertSent || now.Sub(since) < silence {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	// every instance sees the same silence, so only the one that claims it sends the alert
+	claimed, err := s.HookHealth.ClaimSilenceAlert(ctx, now, dayStart)
+	if err != nil {
+		slog.Error("hook monitor: claiming silence alert", "error", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.health.AlertSent = true
+	s.mu.Unlock()
+	if !claimed {
+		return
+	}
+
+	slog.Warn("hook monitor: no connectwise callbacks received", "since", since, "silence_minutes", cfg.HookSilenceMinutes)
+
+	// try to fix it before anyone has to look
+	checkErr := s.ProcessCWHooks(ctx)
+	if checkErr != nil {
+		slog.Error("hook monitor: reconciling connectwise callbacks", "error", checkErr.Error())
+	}
+
+	if err := s.sendSilenceAlert(ctx, *recipID, since, checkErr); err != nil {
+		slog.Error("hook monitor: sending silence alert", "recipient_id", *recipID, "error", err.Error())
+		// allow a retry on the next tick
+		if err := s.HookHealth.ReleaseSilenceAlert(ctx, now); err != nil {
+			slog.Error("hook monitor: releasing silence alert", "error", err.Error())
+		}
+		s.mu.Lock()
+		s.health.AlertSent = false
+		s.mu.Unlock()
+	}
+}
+
+func (s *Service) sendSilenceAlert(ctx context.Context, recipID int, since time.Time, checkErr error) error {
+	r, err := s.Recipients.Get(ctx, recipID)
+	if err != nil {
+		return fmt.Errorf("getting alert recipient: %w", err)
+	}
+
+	loc := businessLocation(s.cfg.Load())
+	body := fmt.Sprintf("**Ticketbot:** no Connectwise callbacks have been received since %s.", since.In(loc).Format("Jan 2 3:04 PM MST"))
+	if checkErr != nil {
+		body += fmt.Sprintf(" Checking the callbacks also failed: %s", checkErr.Error())
+	} else {
+		body += " The callbacks were checked and look correct, so Connectwise may not be delivering them."
+	}
+
+	msg := webex.NewMessageToRoom(r.WebexID, r.Name, body)
+	if r.Type == models.RecipientTypePerson && r.Email != nil {
+		msg = webex.NewMessageToPerson(*r.Email, body)
+	}
+
+	if _, err := s.MessageSender.PostMessage(ctx, &msg); err != nil {
+		return fmt.Errorf("posting webex message: %w", err)
+	}
+
+	return nil
+}
+
+// businessHours returns the start of business hours on now's day, and whether now is
+// within them. Weekends are never within business hours.
+func businessHours(now time.Time, c *models.Config) (time.Time, bool) {
+	local := now.In(businessLocation(c))
+	y, m, d := local.Date()
+	start := time.Date(y, m, d, c.BusinessHoursStart, 0, 0, 0, local.Location())
+	end := time.Date(y, m, d, c.BusinessHoursEnd, 0, 0, 0, local.Location())
+
+	if wd := local.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return start, false
+	}
+
+	return start, !local.Before(start) && local.Before(end)
+}
+
+func businessLocation(c *models.Config) *time.Location {
+	loc, err := time.LoadLocation(c.BusinessTimezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
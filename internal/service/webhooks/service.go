@@ -4,20 +4,55 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/thecoretg/tctg-go/connectwise/psa"
+	"github.com/thecoretg/ticketbot/internal/cwclient"
+	"github.com/thecoretg/ticketbot/internal/repos"
+	"github.com/thecoretg/ticketbot/internal/runtimecfg"
+	"github.com/thecoretg/ticketbot/models"
 )
 
+// cwHook is a callback ticketbot expects to have registered in Connectwise.
+type cwHook struct {
+	path     string
+	entity   string
+	level    string
+	objectID int
+}
+
+var cwHooks = []cwHook{
+	{path: "tickets", entity: "ticket", level: "owner", objectID: 1},
+	{path: "companies", entity: "company", level: "owner", objectID: 1},
+	{path: "contacts", entity: "contact", level: "owner", objectID: 1},
+	{path: "members", entity: "member", level: "owner", objectID: 1},
+}
+
 type Service struct {
-	CWClient *psa.Client
-	RootURL  string
+	CWClient      *cwclient.Client
+	RootURL       string
+	HookHealth    repos.HookHealthRepository
+	Recipients    repos.WebexRecipientRepository
+	MessageSender repos.MessageSender
+	cfg           *runtimecfg.Store
+	startedOn     time.Time
+
+	mu     sync.Mutex
+	health models.HookHealth
+	// savedReceived is the last LastReceived written to HookHealth
+	savedReceived time.Time
 }
 
-func New(cw *psa.Client, rootURL string) *Service {
+func New(cw *cwclient.Client, rootURL string, cfg *runtimecfg.Store, hh repos.HookHealthRepository, r repos.WebexRecipientRepository, ms repos.MessageSender) *Service {
 	return &Service{
-		CWClient: cw,
-		RootURL:  rootURL,
+		CWClient:      cw,
+		RootURL:       rootURL,
+		HookHealth:    hh,
+		Recipients:    r,
+		MessageSender: ms,
+		cfg:           cfg,
+		startedOn:     time.Now(),
 	}
 }
 
@@ -32,7 +67,11 @@ func (s *Service) ProcessAllHooks(ctx context.Context) error {
 	return nil
 }
 
-func (s *Service) ProcessCWHooks(ctx context.Context) error {
+func (s *Service) ProcessCWHooks(ctx context.Context) (err error) {
+	defer func() {
+		s.recordCheck(err)
+	}()
+
 	p := map[string]string{
 		"pageSize": "1000",
 	}
@@ -43,8 +82,10 @@ func (s *Service) ProcessCWHooks(ctx context.Context) error {
 	}
 	slog.Debug("hook sync: got existing connectwise callbacks", "total", len(cwh))
 
-	if err := s.processCWHook(ctx, ticketsWebhookURL(s.RootURL), "ticket", "owner", 1, cwh); err != nil {
-		return fmt.Errorf("processing ticketbot hook: %w", err)
+	for _, h := range cwHooks {
+		if err := s.processCWHook(ctx, cwWebhookURL(s.RootURL, h.path), h.entity, h.level, h.objectID, cwh); err != nil {
+			return fmt.Errorf("processing %s hook: %w", h.entity, err)
+		}
 	}
 
 	return nil
@@ -58,23 +99,43 @@ func (s *Service) processCWHook(ctx context.Context, url, entity, level string,
 		ObjectID: objectID,
 	}
 
-	found := false
-	for _, h := range currentHooks {
-		if h.URL == expected.URL {
-			if cwHooksMatch(expected, h) && !found {
-				slog.Debug("found existing callback", "id", h.ID, "entity", entity, "level", level, "url", url)
-				found = true
-				continue
-			} else {
-				if err := s.CWClient.DeleteCallback(ctx, h.ID); err != nil {
-					return fmt.Errorf("deleting callback: %w", err)
-				}
-				slog.Info("hook sync: deleted unused callback", "id", h.ID, "url", h.URL)
+	// keep an active matching callback if there is one, otherwise the first inactive one
+	keep := -1
+	for i, h := range currentHooks {
+		if h.URL == expected.URL && cwHooksMatch(expected, h) && (keep == -1 || currentHooks[keep].InactiveFlag && !h.InactiveFlag) {
+			keep = i
+		}
+	}
+
+	for i, h := range currentHooks {
+		if h.URL != expected.URL {
+			continue
+		}
+
+		if i != keep {
+			if err := s.CWClient.DeleteCallback(ctx, h.ID); err != nil {
+				return fmt.Errorf("deleting callback: %w", err)
 			}
+			slog.Info("hook sync: deleted unused callback", "id", h.ID, "url", h.URL)
+			continue
+		}
+
+		if !h.InactiveFlag {
+			slog.Debug("found existing callback", "id", h.ID, "entity", entity, "level", level, "url", url)
+			continue
+		}
+
+		// Connectwise deactivates callbacks that keep failing to deliver; turn it back on rather
+		// than replacing it, so it keeps its ID
+		slog.Warn("hook sync: connectwise callback was deactivated, reactivating it", "id", h.ID, "entity", entity, "url", h.URL)
+		h.InactiveFlag = false
+		if _, err := s.CWClient.PutCallback(ctx, h.ID, &h); err != nil {
+			return fmt.Errorf("reactivating callback: %w", err)
 		}
+		s.countReactivated()
 	}
 
-	if !found {
+	if keep == -1 {
 		newHook, err := s.CWClient.PostCallback(ctx, &expected)
 		if err != nil {
 			return fmt.Errorf("posting callback: %w", err)
@@ -85,9 +146,9 @@ func (s *Service) processCWHook(ctx context.Context, url, entity, level string,
 }
 
 func cwHooksMatch(expected, existing psa.Callback) bool {
-	return expected.Type == existing.Type && expected.Level == existing.Level && expected.InactiveFlag == existing.InactiveFlag
+	return expected.Type == existing.Type && expected.Level == existing.Level
 }
 
-func ticketsWebhookURL(rootURL string) string {
-	return fmt.Sprintf("%s/hooks/cw/tickets", rootURL)
+func cwWebhookURL(rootURL, path string) string {
+	return fmt.Sprintf("%s/hooks/cw/%s", rootURL, path)
 }
@@ -82,7 +82,11 @@ async function api(method, path, body = null) {
     }
 
     const data = await res.json()
-    if (!res.ok) throw new Error(data.error || `Request failed: ${res.status}`)
+    if (!res.ok) {
+        const err  = new Error(data.error || `Request failed: ${res.status}`)
+        err.status = res.status
+        throw err
+    }
     return data
 }
 
@@ -113,16 +117,33 @@ async function login() {
             showPasswordReset()
         } else {
             await showApp()
-            if (res?.totp_setup_required) showTOTPSetupModal(true)
         }
-    } catch {
-        showLoginErr('Invalid email or password')
+    } catch (e) {
+        showLoginErr(e.status === 429 ? e.message : 'Invalid email or password')
     } finally {
         btn.disabled    = false
         btn.textContent = 'Login'
     }
 }
 
+// initSSO offers single sign-on on the login screen when the server has it configured, and shows
+// any error the sign-in redirect came back with.
+async function initSSO() {
+    const params = new URLSearchParams(window.location.search)
+    const ssoErr = params.get('sso_error')
+    if (ssoErr) {
+        showLoginErr(ssoErr)
+        history.replaceState(null, '', window.location.pathname + window.location.hash)
+    }
+
+    try {
+        const res = await api('GET', '/auth/oidc')
+        if (!res?.enabled) return
+        document.getElementById('sso-btn').textContent = `Sign in with ${res.name}`
+        document.getElementById('sso-login').classList.remove('hidden')
+    } catch { /* older server or sso unavailable, password login still works */ }
+}
+
 function showLoginErr(msg) {
     const el = document.getElementById('login-err')
     el.textContent = msg
@@ -180,8 +201,8 @@ async function submitTOTPVerify() {
                 toast('You logged in with a recovery code. Check your 2FA setup in the account menu.', 'error')
             }
         }
-    } catch {
-        errEl.textContent = 'Invalid or expired code'
+    } catch (e) {
+        errEl.textContent = e.status === 429 ? e.message : 'Invalid or expired code'
         errEl.classList.remove('hidden')
     } finally {
         btn.disabled    = false
@@ -247,21 +268,28 @@ async function showApp() {
     document.getElementById('password-reset').classList.add('hidden')
     document.getElementById('app').classList.remove('hidden')
     try {
-        const [me, totp, cfg] = await Promise.all([
-            api('GET', '/users/me'),
-            api('GET', '/auth/totp'),
-            api('GET', '/config'),
-        ])
+        const me = await api('GET', '/users/me')
         currentUser = me
+        // the server only lets a session with unfinished setup reach the setup routes
+        if (me.restriction === 'password_reset_required') {
+            showPasswordReset()
+            return
+        }
+        const totp = await api('GET', '/auth/totp')
         totpEnabled = totp.enabled
-        requireTOTP = cfg.require_totp
         document.getElementById('header-email').textContent   = currentUser.email_address
         document.getElementById('dropdown-email').textContent = currentUser.email_address
-        updateTOTPMenuItem()
-        if (requireTOTP && !totpEnabled) {
+        document.querySelector('.nav-item[data-tab="users"]').classList.toggle('hidden', !isAdmin())
+        document.querySelector('.nav-item[data-tab="audit"]').classList.toggle('hidden', !isAdmin())
+        if (me.restriction === 'totp_setup_required') {
+            requireTOTP = true
+            updateTOTPMenuItem()
             showTOTPSetupModal(true)
             return
         }
+        const cfg = await api('GET', '/config').catch(() => null)
+        requireTOTP = !!cfg?.require_totp
+        updateTOTPMenuItem()
     } catch {}
     const hash = window.location.hash.replace('#', '')
     switchTab(tabLoaders[hash] ? hash : 'rules')
@@ -305,6 +333,41 @@ function showChangePasswordModal() {
     setTimeout(() => attachPwdReqs('f-new-pwd', 'f-pwd-reqs'), 50)
 }
 
+async function showSessionsModal() {
+    document.getElementById('account-dropdown').classList.add('hidden')
+    let sessions
+    try {
+        sessions = await api('GET', '/auth/sessions')
+    } catch (e) { toast(e.message, 'error'); return }
+
+    const thead = '<th>Device</th><th>IP</th><th>Signed In</th><th>Last Seen</th><th></th>'
+    const rows  = (sessions || []).map(s => `<tr>
+        <td class="session-agent" title="${esc(s.user_agent)}">${esc(s.user_agent || 'Unknown')}</td>
+        <td style="color:var(--muted)">${esc(s.ip || '—')}</td>
+        <td style="color:var(--muted)">${fmtDateTime(s.created_on)}</td>
+        <td style="color:var(--muted)">${fmtDateTime(s.last_seen_on)}</td>
+        <td class="actions">${s.current
+            ? '<span class="badge badge-on">This device</span>'
+            : `<button class="btn btn-danger" onclick="revokeSession(${s.id})">Revoke</button>`}</td>
+    </tr>`)
+
+    openModal('Active Sessions', tableWrap(thead, rows), async () => {
+        try {
+            const res = await api('DELETE', '/auth/sessions')
+            closeModal()
+            toast(`Signed out ${res.revoked} other session${res.revoked === 1 ? '' : 's'}`, 'success')
+        } catch (e) { toast(e.message, 'error') }
+    }, 'Sign Out Others')
+}
+
+async function revokeSession(id) {
+    try {
+        await api('DELETE', `/auth/sessions/${id}`)
+        toast('Session revoked', 'success')
+        showSessionsModal()
+    } catch (e) { toast(e.message, 'error') }
+}
+
 function updateTOTPMenuItem() {
     const btn = document.getElementById('totp-menu-btn')
     if (!btn) return
@@ -349,7 +412,7 @@ async function showTOTPSetupModal(required = false) {
             <div class="secret-display">${esc(setupData.secret)}</div>
         </div>
         <div class="form-group">
-            <label>Current Password</label>
+            <label>Current Password <span style="color:var(--muted)">(leave blank if you only sign in with SSO)</span></label>
             <input type="password" id="f-totp-pwd" autocomplete="current-password">
         </div>
         <div class="form-group">
@@ -358,7 +421,6 @@ async function showTOTPSetupModal(required = false) {
         </div>`, async () => {
         const pwd  = document.getElementById('f-totp-pwd').value
         const code = document.getElementById('f-totp-code').value.trim()
-        if (!pwd)  { toast('Password is required', 'error'); return }
         if (!code) { toast('Confirmation code is required', 'error'); return }
         try {
             const res = await api('PUT', '/auth/totp/setup', {
@@ -474,11 +536,12 @@ const tabLoaders = {
     sync:     loadSync,
     config:   loadConfig,
     logs:     loadLogs,
+    audit:    loadAudit,
 }
 
 function switchTab(tab) {
     stopSyncPoll()
-    stopLogsPoll()
+    stopLogsUpdates()
     currentTab = tab
     window.location.hash = tab
     document.querySelectorAll('.nav-item').forEach(el => {
@@ -772,30 +835,68 @@ async function deleteForward(id) {
 // ─────────────────────────────────────────────────────────
 // Users
 // ─────────────────────────────────────────────────────────
+const ROLES = ['viewer', 'operator', 'admin']
+
+function isAdmin() {
+    return currentUser?.effective_role === 'admin'
+}
+
+function roleOptions(selected, max = 'admin') {
+    return ROLES.slice(0, ROLES.indexOf(max) + 1).map(r =>
+        `<option value="${r}" ${r === selected ? 'selected' : ''}>${r}</option>`
+    ).join('')
+}
+
 async function loadUsers() {
     try {
-        const users = await api('GET', '/users')
-        renderUsers(users || [])
+        const [users, lockouts] = await Promise.all([
+            api('GET', '/users'),
+            api('GET', '/users/lockouts'),
+        ])
+        renderUsers(users || [], lockouts?.active || [])
     } catch (e) {
         setContent(`<div class="empty-state">${esc(e.message)}</div>`)
     }
 }
 
-function renderUsers(users) {
+function renderUsers(users, lockouts = []) {
+    const lockedUntil = {}
+    lockouts.filter(l => l.scope === 'account').forEach(l => { lockedUntil[l.subject] = l.locked_until })
+
     const header = `<div class="tab-header">
         <h2>Users</h2>
         <button class="btn btn-primary btn-sm" onclick="showNewUserModal()">+ New User</button>
     </div>`
 
-    const thead = '<th>ID</th><th>Email</th><th>Created</th><th></th>'
+    const thead = '<th>ID</th><th>Email</th><th>Role</th><th>Created</th><th></th>'
     const rows  = users.map(u => `<tr>
         <td style="color:var(--muted)">${u.id}</td>
-        <td>${esc(u.email_address)}</td>
+        <td>${esc(u.email_address)}${lockedUntil[u.email_address.toLowerCase()]
+            ? ` <span class="badge badge-off" title="Locked until ${esc(fmtDateTime(lockedUntil[u.email_address.toLowerCase()]))}">Locked</span>`
+            : ''}</td>
+        <td>${u.id === currentUser?.id
+            ? esc(u.role)
+            : `<select class="config-input" onchange="setUserRole(${u.id}, this.value)">${roleOptions(u.role)}</select>`}</td>
         <td style="color:var(--muted)">${fmtDateTime(u.created_on)}</td>
-        <td class="actions"><button class="btn btn-danger" onclick="deleteUser(${u.id})">Delete</button></td>
+        <td class="actions">
+            ${lockedUntil[u.email_address.toLowerCase()] ? `<button class="btn btn-ghost" onclick="unlockUser(${u.id})">Unlock</button>` : ''}
+            <button class="btn btn-ghost" onclick="revokeUserSessions(${u.id})">Sign Out</button>
+            <button class="btn btn-danger" onclick="deleteUser(${u.id})">Delete</button>
+        </td>
     </tr>`)
 
-    setContent(header + tableWrap(thead, rows))
+    const lockedIPs = lockouts.filter(l => l.scope === 'ip')
+    const ipSection = lockedIPs.length ? `<div class="tab-header"><h2>Locked IP Addresses</h2></div>` + tableWrap(
+        '<th>IP Address</th><th>Locked Until</th><th>Lockouts</th><th></th>',
+        lockedIPs.map(l => `<tr>
+            <td>${esc(l.subject)}</td>
+            <td style="color:var(--muted)">${fmtDateTime(l.locked_until)}</td>
+            <td style="color:var(--muted)">${l.lockouts}</td>
+            <td class="actions"><button class="btn btn-ghost" onclick="unlockIP('${esc(l.subject)}')">Unlock</button></td>
+        </tr>`)
+    ) : ''
+
+    setContent(header + tableWrap(thead, rows) + ipSection)
 }
 
 function showNewUserModal() {
@@ -807,13 +908,18 @@ function showNewUserModal() {
         <div class="form-group">
             <label>Temporary Password</label>
             <input type="password" id="f-temp-password" placeholder="User must change on first login">
+        </div>
+        <div class="form-group">
+            <label>Role</label>
+            <select id="f-role">${roleOptions('viewer')}</select>
         </div>`, async () => {
         const email    = document.getElementById('f-email').value.trim()
         const password = document.getElementById('f-temp-password').value
+        const role     = document.getElementById('f-role').value
         if (!email)     { toast('Email is required', 'error'); return }
         if (!password)  { toast('Temporary password is required', 'error'); return }
         try {
-            await api('POST', '/users', { email_address: email, password })
+            await api('POST', '/users', { email_address: email, password, role })
             closeModal()
             toast('User created — they must change their password on first login', 'success')
             loadUsers()
@@ -821,6 +927,40 @@ function showNewUserModal() {
     })
 }
 
+async function setUserRole(id, role) {
+    try {
+        await api('PUT', `/users/${id}/role`, { role })
+        toast('Role updated', 'success')
+    } catch (e) {
+        toast(e.message, 'error')
+        loadUsers()
+    }
+}
+
+async function revokeUserSessions(id) {
+    if (!confirm('Sign this user out of every panel session? Their API keys keep working.')) return
+    try {
+        const res = await api('DELETE', `/users/${id}/sessions`)
+        toast(`Ended ${res.revoked} session${res.revoked === 1 ? '' : 's'}`, 'success')
+    } catch (e) { toast(e.message, 'error') }
+}
+
+async function unlockUser(id) {
+    try {
+        await api('DELETE', `/users/${id}/lockout`)
+        toast('User unlocked', 'success')
+        loadUsers()
+    } catch (e) { toast(e.message, 'error') }
+}
+
+async function unlockIP(ip) {
+    try {
+        await api('DELETE', `/users/lockouts/ip/${encodeURIComponent(ip)}`)
+        toast('IP address unlocked', 'success')
+        loadUsers()
+    } catch (e) { toast(e.message, 'error') }
+}
+
 async function deleteUser(id) {
     if (!confirm('Delete this user? Their API keys will also be removed.')) return
     try {
@@ -830,14 +970,75 @@ async function deleteUser(id) {
     } catch (e) { toast(e.message, 'error') }
 }
 
+// ─────────────────────────────────────────────────────────
+// Audit
+// ─────────────────────────────────────────────────────────
+const AUDIT_TARGETS = ['config', 'user', 'api_key', 'notifier_rule', 'notifier_forward', 'sync_job', 'server', 'session', 'ip']
+let auditTarget  = ''
+let auditEntries = []
+
+async function loadAudit() {
+    try {
+        const params = new URLSearchParams({ limit: 100 })
+        if (auditTarget) params.set('target_type', auditTarget)
+        const [entries, users] = await Promise.all([
+            api('GET', `/audit?${params}`),
+            api('GET', '/users'),
+        ])
+        auditEntries = entries || []
+        renderAudit(auditEntries, users || [])
+    } catch (e) {
+        setContent(`<div class="empty-state">${esc(e.message)}</div>`)
+    }
+}
+
+function renderAudit(entries, users) {
+    const userMap = {}
+    users.forEach(u => { userMap[u.id] = u.email_address })
+
+    const targetOpts = ['', ...AUDIT_TARGETS].map(t =>
+        `<option value="${t}" ${t === auditTarget ? 'selected' : ''}>${t || 'All targets'}</option>`).join('')
+
+    const header = `<div class="tab-header">
+        <h2>Audit Log</h2>
+        <select class="config-input" onchange="auditTarget = this.value; loadAudit()">${targetOpts}</select>
+    </div>`
+
+    const thead = '<th>Time</th><th>Actor</th><th>Action</th><th>Target</th><th>IP</th><th></th>'
+    const rows  = entries.map((e, i) => {
+        const actor = e.actor_user_id ? (userMap[e.actor_user_id] || `User #${e.actor_user_id}`) : '—'
+        return `<tr>
+        <td style="color:var(--muted)">${fmtDateTime(e.created_on)}</td>
+        <td>${esc(actor)}${e.actor_key_id ? ` <span style="color:var(--muted)">(key #${e.actor_key_id})</span>` : ''}</td>
+        <td style="font-family:monospace">${esc(e.action)}</td>
+        <td>${esc(e.target_type)}${e.target_id ? ` #${esc(e.target_id)}` : ''}</td>
+        <td style="color:var(--muted)">${esc(e.remote_ip)}</td>
+        <td class="actions">${e.before || e.after ? `<button class="btn btn-ghost" onclick="showAuditEntry(${i})">Details</button>` : ''}</td>
+    </tr>`
+    })
+
+    setContent(header + tableWrap(thead, rows))
+}
+
+function showAuditEntry(i) {
+    const e   = auditEntries[i]
+    const fmt = v => v ? `<div class="key-display" style="white-space:pre-wrap">${esc(JSON.stringify(v, null, 2))}</div>` : '<p style="color:var(--muted)">—</p>'
+    openModal(`${e.action} — ${fmtDateTime(e.created_on)}`, `
+        <div class="form-group"><label>Before</label>${fmt(e.before)}</div>
+        <div class="form-group"><label>After</label>${fmt(e.after)}</div>
+        <p style="color:var(--muted);font-size:12px">${esc(e.method)} ${esc(e.path)} · ${esc(e.user_agent)}</p>`, null)
+    document.getElementById('modal-footer').innerHTML = '<button class="btn btn-primary" onclick="closeModal()">Close</button>'
+}
+
 // ─────────────────────────────────────────────────────────
 // API Keys
 // ─────────────────────────────────────────────────────────
 async function loadKeys() {
     try {
+        // only admins can list users; everyone else only sees their own keys
         const [keys, users] = await Promise.all([
             api('GET', '/users/keys'),
-            api('GET', '/users'),
+            isAdmin() ? api('GET', '/users') : [currentUser],
         ])
         renderKeys(keys || [], users || [])
     } catch (e) {
@@ -854,23 +1055,36 @@ function renderKeys(keys, users) {
         <button class="btn btn-primary btn-sm" onclick="showNewKeyModal()">+ New Key</button>
     </div>`
 
-    const thead = '<th>ID</th><th>User</th><th>Hint</th><th>Created</th><th></th>'
-    const rows  = keys.map(k => `<tr>
+    const thead = '<th>ID</th><th>User</th><th>Label</th><th>Role</th><th>Scopes</th><th>Hint</th><th>Created</th><th>Expires</th><th>Last Used</th><th></th>'
+    const rows  = keys.map(k => {
+        const expired = k.expires_on && new Date(k.expires_on) <= new Date()
+        return `<tr>
         <td style="color:var(--muted)">${k.id}</td>
         <td>${esc(userMap[k.user_id] || `User #${k.user_id}`)}</td>
+        <td>${k.label ? esc(k.label) : '—'}</td>
+        <td>${esc(k.role)}</td>
+        <td style="font-size:12px">${k.scopes?.length ? k.scopes.map(esc).join(', ') : '<span style="color:var(--muted)">all</span>'}</td>
         <td style="font-family:monospace;color:var(--muted)">${k.key_hint ? `****${esc(k.key_hint)}` : '—'}</td>
         <td style="color:var(--muted)">${fmtDateTime(k.created_on)}</td>
-        <td class="actions"><button class="btn btn-danger" onclick="deleteKey(${k.id})">Delete</button></td>
-    </tr>`)
+        <td style="color:${expired ? 'var(--danger)' : 'var(--muted)'}">${k.expires_on ? fmtDateTime(k.expires_on) : 'Never'}</td>
+        <td style="color:var(--muted)">${k.last_used_on ? `${fmtDateTime(k.last_used_on)}${k.last_used_ip ? ` from ${esc(k.last_used_ip)}` : ''}` : 'Never'}</td>
+        <td class="actions">
+            <button class="btn btn-ghost" onclick="rotateKey(${k.id})"${expired ? ' disabled' : ''}>Rotate</button>
+            <button class="btn btn-danger" onclick="deleteKey(${k.id})">Delete</button>
+        </td>
+    </tr>`
+    })
 
     setContent(header + tableWrap(thead, rows))
 }
 
 async function showNewKeyModal() {
-    let users
-    try {
-        users = await api('GET', '/users')
-    } catch (e) { toast(e.message, 'error'); return }
+    let users = [currentUser]
+    if (isAdmin()) {
+        try {
+            users = await api('GET', '/users')
+        } catch (e) { toast(e.message, 'error'); return }
+    }
 
     if (!users?.length) { toast('No users found — create a user first', 'error'); return }
 
@@ -881,20 +1095,72 @@ async function showNewKeyModal() {
         <div class="form-group">
             <label>User</label>
             <select id="f-user-email">${userOpts}</select>
+        </div>
+        <div class="form-group">
+            <label>Label</label>
+            <input id="f-key-label" type="text" placeholder="e.g. backup script">
+        </div>
+        <div class="form-group">
+            <label>Role</label>
+            <select id="f-key-role">${roleOptions(currentUser?.effective_role, currentUser?.effective_role)}</select>
+        </div>
+        <div class="form-group">
+            <label>Scopes <span style="color:var(--muted);font-weight:normal">(none selected = unrestricted)</span></label>
+            <div class="scope-grid">${scopeCheckboxes()}</div>
+        </div>
+        <div class="form-group">
+            <label>Expires After (days)</label>
+            <input id="f-key-expiry" type="number" min="1" placeholder="Never">
         </div>`, async () => {
-        const email = document.getElementById('f-user-email').value
+        const email  = document.getElementById('f-user-email').value
+        const role   = document.getElementById('f-key-role').value
+        const label  = document.getElementById('f-key-label').value.trim()
+        const scopes = [...document.querySelectorAll('.f-key-scope:checked')].map(el => el.value)
+        const days   = parseInt(document.getElementById('f-key-expiry').value)
+        const body   = { email, role, label, scopes }
+        if (days > 0) body.expires_on = new Date(Date.now() + days * 86400000).toISOString()
         try {
-            const res = await api('POST', '/users/keys', { email })
-            // Replace modal with key display — key is only shown once
-            document.getElementById('modal-body').innerHTML = `
-                <p style="color:var(--warning);font-size:13px">
-                    ⚠ Copy this key now — it will not be shown again.
-                </p>
-                <div class="key-display" id="created-key">${esc(res.key)}</div>`
-            document.getElementById('modal-footer').innerHTML = `
-                <button class="btn btn-ghost" onclick="copyCreatedKey()">Copy to Clipboard</button>
-                <button class="btn btn-primary" onclick="closeModal(); loadKeys()">Done</button>`
-            modalSubmitFn = null
+            showCreatedKey(await api('POST', '/users/keys', body))
+        } catch (e) { toast(e.message, 'error') }
+    })
+}
+
+const keyScopeAreas = ['account', 'admin', 'audit', 'config', 'cw', 'hooks', 'keys', 'logs', 'metrics', 'notifiers', 'search', 'sync', 'users', 'webex']
+
+function scopeCheckboxes() {
+    return keyScopeAreas.map(a => `<div class="scope-row">
+        <span>${a}</span>
+        <label><input type="checkbox" class="f-key-scope" value="${a}:read"> read</label>
+        <label><input type="checkbox" class="f-key-scope" value="${a}:write"> write</label>
+    </div>`).join('')
+}
+
+// Replaces the modal with the new key, which is only shown once.
+function showCreatedKey(res) {
+    document.getElementById('modal-body').innerHTML = `
+        <p style="color:var(--warning);font-size:13px">
+            ⚠ Copy this key now — it will not be shown again.
+        </p>
+        <div class="key-display" id="created-key">${esc(res.key)}</div>`
+    document.getElementById('modal-footer').innerHTML = `
+        <button class="btn btn-ghost" onclick="copyCreatedKey()">Copy to Clipboard</button>
+        <button class="btn btn-primary" onclick="closeModal(); loadKeys()">Done</button>`
+    modalSubmitFn = null
+}
+
+function rotateKey(id) {
+    openModal('Rotate API Key', `
+        <p style="font-size:13px;color:var(--muted)">
+            A new key is issued with the same role, scopes and label. The old key keeps working for the grace period.
+        </p>
+        <div class="form-group">
+            <label>Grace Period (minutes)</label>
+            <input id="f-key-grace" type="number" min="1" value="60">
+        </div>`, async () => {
+        const grace = parseInt(document.getElementById('f-key-grace').value)
+        if (!(grace > 0)) { toast('Grace period must be at least one minute', 'error'); return }
+        try {
+            showCreatedKey(await api('POST', `/users/keys/${id}/rotate`, { grace_minutes: grace }))
         } catch (e) { toast(e.message, 'error') }
     })
 }
@@ -918,24 +1184,30 @@ async function deleteKey(id) {
 // ─────────────────────────────────────────────────────────
 async function loadSync() {
     try {
-        const status = await api('GET', '/sync/status')
-        renderSync(status)
+        const [status, jobs] = await Promise.all([
+            api('GET', '/sync/status'),
+            api('GET', '/sync/jobs?limit=10'),
+        ])
+        renderSync(status, jobs || [])
         if (status?.status) startSyncPoll()
     } catch (e) {
         setContent(`<div class="empty-state">${esc(e.message)}</div>`)
     }
 }
 
-function renderSync(status) {
+function renderSync(status, jobs = []) {
     const running   = status?.status === true
     const dotClass  = running ? 'running' : 'idle'
-    const statusTxt = running ? 'Sync running…' : 'Idle'
+    const statusTxt = running ? `Sync job #${status.job?.id ?? ''} running…` : 'Idle'
 
     setContent(`<div class="tab-header">
         <h2>Sync</h2>
-        <button class="btn btn-primary btn-sm" onclick="showNewSyncModal()" ${running ? 'disabled' : ''}>
-            Run Sync
-        </button>
+        <div style="display:flex;gap:8px">
+            ${running && status.job ? `<button class="btn btn-danger btn-sm" onclick="cancelSyncJob(${status.job.id})">Cancel</button>` : ''}
+            <button class="btn btn-primary btn-sm" onclick="showNewSyncModal()" ${running ? 'disabled' : ''}>
+                Run Sync
+            </button>
+        </div>
     </div>
     <div class="sync-status">
         <div class="status-dot ${dotClass}"></div>
@@ -943,8 +1215,63 @@ function renderSync(status) {
     </div>
     <p style="color:var(--muted);font-size:13px;max-width:480px">
         Sync pulls the latest boards and Webex recipients from Connectwise and Webex.
-        Run this after adding new boards or updating room memberships.
-    </p>`)
+        Syncs also run on the schedule set in Configuration; run one here after adding
+        new boards or updating room memberships.
+    </p>
+    ${running && status.job ? syncPhasesTable(status.job.phases || []) : ''}
+    ${syncRunsTable(status?.runs || [])}
+    ${syncJobsTable(jobs)}`)
+}
+
+function syncPhasesTable(phases) {
+    const thead = '<th>Phase</th><th>Status</th><th>Fetched</th><th>Upserted</th><th>Deleted</th><th>Failed</th>'
+    const rows  = phases.map(p => `<tr>
+        <td>${esc(p.type)}</td>
+        <td>${esc(p.status)}</td>
+        <td>${p.fetched}</td>
+        <td>${p.upserted}</td>
+        <td>${p.soft_deleted}</td>
+        <td>${p.failed ? `<span style="color:var(--danger)">${p.failed}</span>` : 0}</td>
+    </tr>`)
+
+    return tableWrap(thead, rows)
+}
+
+function syncJobsTable(jobs) {
+    if (!jobs.length) return ''
+
+    const thead = '<th>Job</th><th>Trigger</th><th>Status</th><th>Started</th><th>Finished</th><th>Failed Items</th>'
+    const rows  = jobs.map(j => `<tr>
+        <td style="color:var(--muted)">#${j.id}</td>
+        <td>${esc(j.triggered_by)}</td>
+        <td title="${esc(j.error ?? '')}">${j.status === 'failed' ? `<span style="color:var(--danger)">failed</span>` : esc(j.status)}</td>
+        <td style="color:var(--muted)">${fmtDateTime(j.started_on)}</td>
+        <td style="color:var(--muted)">${fmtDateTime(j.finished_on)}</td>
+        <td>${(j.phases || []).reduce((n, p) => n + p.failed, 0)}</td>
+    </tr>`)
+
+    return tableWrap(thead, rows)
+}
+
+async function cancelSyncJob(id) {
+    try {
+        await api('POST', `/sync/jobs/${id}/cancel`)
+        toast('Cancelling sync', 'success')
+    } catch (e) { toast(e.message, 'error') }
+}
+
+function syncRunsTable(runs) {
+    if (!runs.length) return ''
+
+    const thead = '<th>Type</th><th>Last Run</th><th>Last Success</th><th>Result</th>'
+    const rows  = runs.map(r => `<tr>
+        <td>${esc(r.sync_type)}</td>
+        <td style="color:var(--muted)">${fmtDateTime(r.last_started)}</td>
+        <td style="color:var(--muted)">${fmtDateTime(r.last_succeeded)}</td>
+        <td>${r.last_error ? `<span style="color:var(--danger)">${esc(r.last_error)}</span>` : 'OK'}</td>
+    </tr>`)
+
+    return tableWrap(thead, rows)
 }
 
 async function showNewSyncModal() {
@@ -968,6 +1295,9 @@ async function showNewSyncModal() {
         <label style="display:flex;align-items:center;gap:8px">
             <input type="checkbox" id="f-sync-tickets"> Sync Tickets
         </label>
+        <label style="display:flex;align-items:center;gap:8px">
+            <input type="checkbox" id="f-sync-ticket-updates"> Sync Ticket Updates <span style="color:var(--muted)">(since last run)</span>
+        </label>
         ${boards.length ? `<div class="form-group" style="margin-top:4px">
             <label>Board filter <span style="color:var(--muted)">(empty = all boards)</span></label>
             <div class="check-list">${boardCheckboxes}</div>
@@ -980,7 +1310,8 @@ async function showNewSyncModal() {
             await api('POST', '/sync', {
                 cw_boards:        document.getElementById('f-sync-boards').checked,
                 webex_recipients: document.getElementById('f-sync-webex').checked,
-                cw_tickets:       document.getElementById('f-sync-tickets').checked,
+                cw_tickets:        document.getElementById('f-sync-tickets').checked,
+                cw_ticket_updates: document.getElementById('f-sync-ticket-updates').checked,
                 board_ids:        boardIds,
             })
             closeModal()
@@ -997,8 +1328,8 @@ function startSyncPoll() {
         if (currentTab !== 'sync') { stopSyncPoll(); return }
         try {
             const status = await api('GET', '/sync/status')
+            if (!status?.status) { stopSyncPoll(); loadSync(); return }
             renderSync(status)
-            if (!status?.status) stopSyncPoll()
         } catch { stopSyncPoll() }
     }, 3000)
 }
@@ -1013,16 +1344,28 @@ function stopSyncPoll() {
 // ─────────────────────────────────────────────────────────
 async function loadConfig() {
     try {
-        const cfg = await api('GET', '/config')
-        renderConfig(cfg)
+        const [cfg, rooms] = await Promise.all([
+            api('GET', '/config'),
+            api('GET', '/webex/rooms').catch(() => []),
+        ])
+        renderConfig(cfg, rooms || [])
     } catch (e) {
         setContent(`<div class="empty-state">${esc(e.message)}</div>`)
     }
 }
 
-function renderConfig(cfg) {
+function renderConfig(cfg, rooms = []) {
+    const alertOpts = ['<option value="0">None</option>'].concat(rooms.map(r =>
+        `<option value="${r.id}" ${cfg.hook_alert_recipient_id === r.id ? 'selected' : ''}>${esc(r.name)}</option>`
+    )).join('')
+
     setContent(`<div class="tab-header">
         <h2>Configuration</h2>
+        <div>
+            <button class="btn btn-ghost btn-sm" onclick="exportConfig()">Export</button>
+            ${isAdmin() ? '<button class="btn btn-ghost btn-sm" onclick="showImportConfig()">Import</button>' : ''}
+            ${isAdmin() ? '<button class="btn btn-ghost btn-sm" onclick="showConfigHistory()">History</button>' : ''}
+        </div>
     </div>
     <div class="config-form">
         <div class="config-row">
@@ -1049,6 +1392,86 @@ function renderConfig(cfg) {
             </div>
             <input class="config-input" type="number" id="c-max-syncs" value="${cfg.max_concurrent_syncs}" min="1">
         </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Board Sync Interval</div>
+                <div class="config-desc">How often boards and statuses are synced, in minutes (0 = disabled)</div>
+            </div>
+            <input class="config-input" type="number" id="c-sync-boards-interval" value="${cfg.sync_boards_interval_minutes}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Recipient Sync Interval</div>
+                <div class="config-desc">How often Webex rooms and people are synced, in minutes (0 = disabled)</div>
+            </div>
+            <input class="config-input" type="number" id="c-sync-recipients-interval" value="${cfg.sync_recipients_interval_minutes}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Open Ticket Sync Interval</div>
+                <div class="config-desc">How often every open ticket is synced, in minutes (0 = disabled)</div>
+            </div>
+            <input class="config-input" type="number" id="c-sync-open-tickets-interval" value="${cfg.sync_open_tickets_interval_minutes}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Ticket Update Sync Interval</div>
+                <div class="config-desc">How often tickets updated since the last run are synced, in minutes (0 = disabled)</div>
+            </div>
+            <input class="config-input" type="number" id="c-sync-ticket-updates-interval" value="${cfg.sync_ticket_updates_interval_minutes}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Callback Check Interval</div>
+                <div class="config-desc">How often Connectwise callbacks are checked and repaired, in minutes (0 = startup only)</div>
+            </div>
+            <input class="config-input" type="number" id="c-hook-check-interval" value="${cfg.hook_check_interval_minutes}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Callback Silence Alert</div>
+                <div class="config-desc">Alert when no Connectwise callbacks arrive for this many minutes during business hours (0 = disabled)</div>
+            </div>
+            <input class="config-input" type="number" id="c-hook-silence" value="${cfg.hook_silence_minutes}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Alert Room</div>
+                <div class="config-desc">Webex room that callback silence alerts are sent to</div>
+            </div>
+            <select class="config-input" id="c-hook-alert-recipient">${alertOpts}</select>
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Business Hours</div>
+                <div class="config-desc">Start and end hour, Monday to Friday</div>
+            </div>
+            <div style="display:flex;gap:8px">
+                <input class="config-input" type="number" id="c-business-start" value="${cfg.business_hours_start}" min="0" max="23">
+                <input class="config-input" type="number" id="c-business-end" value="${cfg.business_hours_end}" min="1" max="24">
+            </div>
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Business Timezone</div>
+                <div class="config-desc">IANA timezone name, e.g. America/New_York</div>
+            </div>
+            <input class="config-input" type="text" id="c-business-tz" value="${esc(cfg.business_timezone)}">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Connectwise Request Budget</div>
+                <div class="config-desc">Requests per minute shared by syncs and webhooks (0 = unlimited)</div>
+            </div>
+            <input class="config-input" type="number" id="c-cw-rpm" value="${cfg.cw_requests_per_minute}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Connectwise Concurrent Requests</div>
+                <div class="config-desc">Maximum Connectwise requests in flight at once (0 = unlimited)</div>
+            </div>
+            <input class="config-input" type="number" id="c-cw-max-concurrent" value="${cfg.cw_max_concurrent_requests}" min="0">
+        </div>
         <div class="config-row">
             <div>
                 <div class="config-label">Require 2FA</div>
@@ -1059,6 +1482,26 @@ function renderConfig(cfg) {
                 <span class="toggle-track"></span>
             </label>
         </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Require 2FA for API Keys</div>
+                <div class="config-desc">When 2FA is required, API keys stop working until their owner sets it up</div>
+            </div>
+            <label class="toggle">
+                <input type="checkbox" id="c-require-totp-keys" ${cfg.require_totp_for_api_keys ? 'checked' : ''}>
+                <span class="toggle-track"></span>
+            </label>
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">SSO Satisfies 2FA</div>
+                <div class="config-desc">When 2FA is required, SSO sign-ins rely on the identity provider's second factor instead</div>
+            </div>
+            <label class="toggle">
+                <input type="checkbox" id="c-sso-satisfies-totp" ${cfg.sso_satisfies_totp ? 'checked' : ''}>
+                <span class="toggle-track"></span>
+            </label>
+        </div>
         <div class="config-row">
             <div>
                 <div class="config-label">Debug Logging</div>
@@ -1090,12 +1533,177 @@ function renderConfig(cfg) {
             </div>
             <input class="config-input" type="number" id="c-log-cleanup-interval" value="${cfg.log_cleanup_interval_hours}" min="1">
         </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Audit Retention</div>
+                <div class="config-desc">How many days of audit log entries to keep (0 = keep forever)</div>
+            </div>
+            <input class="config-input" type="number" id="c-audit-retention" value="${cfg.audit_retention_days}" min="0">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Session Idle Timeout</div>
+                <div class="config-desc">Hours without activity before a panel session ends</div>
+            </div>
+            <input class="config-input" type="number" id="c-session-idle" value="${cfg.session_idle_timeout_hours}" min="1">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Session Max Lifetime</div>
+                <div class="config-desc">Hours after login a session ends, however active it is</div>
+            </div>
+            <input class="config-input" type="number" id="c-session-max" value="${cfg.session_max_lifetime_hours}" min="1">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Account Lockout Threshold</div>
+                <div class="config-desc">Failed sign-ins on one account before it is locked out</div>
+            </div>
+            <input class="config-input" type="number" id="c-login-max" value="${cfg.login_max_failures}" min="1">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">IP Lockout Threshold</div>
+                <div class="config-desc">Failed sign-ins from one IP address before it is locked out</div>
+            </div>
+            <input class="config-input" type="number" id="c-login-ip-max" value="${cfg.login_ip_max_failures}" min="1">
+        </div>
+        <div class="config-row">
+            <div>
+                <div class="config-label">Lockout Duration</div>
+                <div class="config-desc">Minutes an account or IP stays locked out, doubling for repeat lockouts</div>
+            </div>
+            <input class="config-input" type="number" id="c-login-lockout" value="${cfg.login_lockout_minutes}" min="1">
+        </div>
         <div class="config-row">
             <button class="btn btn-primary btn-sm" onclick="saveConfig()">Save Changes</button>
         </div>
     </div>`)
 }
 
+async function showConfigHistory() {
+    let revs, users
+    try {
+        [revs, users] = await Promise.all([
+            api('GET', '/config/history?limit=50'),
+            api('GET', '/users'),
+        ])
+    } catch (e) { toast(e.message, 'error'); return }
+
+    const userMap = {}
+    ;(users || []).forEach(u => { userMap[u.id] = u.email_address })
+    const fmtVal = v => v === null || v === undefined ? '—' : esc(String(v))
+
+    const thead = '<th>Rev</th><th>Time</th><th>Author</th><th>Changes</th><th></th>'
+    const rows  = (revs || []).map((r, i) => {
+        let author = r.author_user_id ? (userMap[r.author_user_id] || `User #${r.author_user_id}`) : '—'
+        if (r.author_key_id) author += ` (key #${r.author_key_id})`
+        let changes = r.changes.length
+            ? r.changes.map(c => `<div class="config-change"><span>${esc(c.field)}</span> ${fmtVal(c.from)} → ${fmtVal(c.to)}</div>`).join('')
+            : `<span style="color:var(--muted)">${r.rollback_of || i < revs.length - 1 ? 'No changes' : 'Earliest recorded'}</span>`
+        if (r.rollback_of) changes = `<div style="color:var(--muted);font-size:12px">Rollback to #${r.rollback_of}</div>` + changes
+        return `<tr>
+        <td style="color:var(--muted)">${r.revision}</td>
+        <td style="color:var(--muted)">${fmtDateTime(r.created_on)}</td>
+        <td>${esc(author)}</td>
+        <td>${changes}</td>
+        <td class="actions">${i === 0 ? '<span class="badge badge-on">Current</span>' : `<button class="btn btn-ghost" onclick="rollbackConfig(${r.revision})">Roll Back</button>`}</td>
+    </tr>`
+    })
+
+    openModal('Config History', tableWrap(thead, rows), null)
+    document.getElementById('modal-footer').innerHTML = '<button class="btn btn-primary" onclick="closeModal()">Close</button>'
+}
+
+async function rollbackConfig(revision) {
+    if (!confirm(`Restore the config from revision #${revision}?`)) return
+    try {
+        await api('POST', `/config/rollback/${revision}`)
+        closeModal()
+        toast(`Config restored from revision #${revision}`, 'success')
+        loadConfig()
+    } catch (e) { toast(e.message, 'error') }
+}
+
+async function exportConfig() {
+    try {
+        const res = await fetch('/export', { credentials: 'same-origin' })
+        if (!res.ok) throw new Error(`Export failed: ${res.status}`)
+        const url = URL.createObjectURL(await res.blob())
+        const a   = document.createElement('a')
+        a.href     = url
+        a.download = `ticketbot-${new Date().toISOString().slice(0, 10)}.yaml`
+        a.click()
+        URL.revokeObjectURL(url)
+    } catch (e) { toast(e.message, 'error') }
+}
+
+let importPreviewed = null
+
+function showImportConfig() {
+    importPreviewed = null
+    openModal('Import Config', `
+        <div class="form-group">
+            <label>Export file (YAML or JSON)</label>
+            <input type="file" id="import-file" accept=".yaml,.yml,.json" onchange="loadImportFile(this)">
+        </div>
+        <div class="form-group">
+            <textarea id="import-doc" class="import-doc" rows="12" spellcheck="false" placeholder="version: 1"></textarea>
+        </div>
+        <div id="import-plan"></div>`, submitImport, 'Preview')
+}
+
+async function loadImportFile(input) {
+    if (!input.files.length) return
+    document.getElementById('import-doc').value = await input.files[0].text()
+}
+
+async function sendImport(doc, dryRun) {
+    const res  = await fetch(`/import?dry_run=${dryRun}`, {
+        method:      'POST',
+        credentials: 'same-origin',
+        headers:     { 'Content-Type': 'application/yaml' },
+        body:        doc,
+    })
+    const data = await res.json()
+    if (!res.ok) throw new Error(data.error || `Import failed: ${res.status}`)
+    return data
+}
+
+// the first submit previews the import; submitting the same document again applies it
+async function submitImport() {
+    const doc = document.getElementById('import-doc').value
+    if (!doc.trim()) { toast('Paste or choose an export document', 'error'); return }
+
+    if (doc !== importPreviewed) {
+        const plan = await sendImport(doc, true).catch(e => { toast(e.message, 'error') })
+        if (!plan) return
+        renderImportPlan(plan)
+        importPreviewed = doc
+        document.getElementById('modal-submit').textContent = 'Apply'
+        return
+    }
+
+    try {
+        const res = await sendImport(doc, false)
+        closeModal()
+        toast(`Imported ${res.config.length} config and ${res.changes.length} rule/forward changes`, 'success')
+        loadConfig()
+    } catch (e) { toast(e.message, 'error') }
+}
+
+function renderImportPlan(plan) {
+    const fmtVal = v => v === null || v === undefined ? '—' : esc(typeof v === 'object' ? JSON.stringify(v) : String(v))
+    const lines  = plan.config.map(c =>
+        `<div class="config-change"><span>config.${esc(c.field)}</span> ${fmtVal(c.from)} → ${fmtVal(c.to)}</div>`
+    ).concat(plan.changes.map(c =>
+        `<div class="config-change"><span>${esc(c.action)} ${esc(c.kind)}</span> ${esc(c.key)}</div>`
+    ))
+    document.getElementById('import-plan').innerHTML = lines.length
+        ? lines.join('')
+        : '<div style="color:var(--muted)">Already up to date</div>'
+}
+
 async function saveConfig() {
     try {
         await api('PUT', '/config', {
@@ -1103,10 +1711,30 @@ async function saveConfig() {
             max_message_length:         parseInt(document.getElementById('c-max-len').value)              || 300,
             max_concurrent_syncs:       parseInt(document.getElementById('c-max-syncs').value)            || 5,
             require_totp:               document.getElementById('c-require-totp').checked,
+            require_totp_for_api_keys:  document.getElementById('c-require-totp-keys').checked,
+            sso_satisfies_totp:         document.getElementById('c-sso-satisfies-totp').checked,
             debug_logging:              document.getElementById('c-debug-logging').checked,
             log_buffer_size:            parseInt(document.getElementById('c-log-buffer-size').value)      || 500,
             log_retention_days:         parseInt(document.getElementById('c-log-retention').value)        ?? 7,
             log_cleanup_interval_hours: parseInt(document.getElementById('c-log-cleanup-interval').value) || 24,
+            sync_boards_interval_minutes:         parseInt(document.getElementById('c-sync-boards-interval').value)         ?? 1440,
+            sync_recipients_interval_minutes:     parseInt(document.getElementById('c-sync-recipients-interval').value)     ?? 1440,
+            sync_open_tickets_interval_minutes:   parseInt(document.getElementById('c-sync-open-tickets-interval').value)   ?? 0,
+            sync_ticket_updates_interval_minutes: parseInt(document.getElementById('c-sync-ticket-updates-interval').value) ?? 5,
+            hook_check_interval_minutes:          parseInt(document.getElementById('c-hook-check-interval').value)          ?? 15,
+            hook_silence_minutes:                 parseInt(document.getElementById('c-hook-silence').value)                 ?? 60,
+            hook_alert_recipient_id:              parseInt(document.getElementById('c-hook-alert-recipient').value)         || 0,
+            business_hours_start:                 parseInt(document.getElementById('c-business-start').value)               ?? 8,
+            business_hours_end:                   parseInt(document.getElementById('c-business-end').value)                 ?? 17,
+            business_timezone:                    document.getElementById('c-business-tz').value.trim()                    || 'UTC',
+            cw_requests_per_minute:               parseInt(document.getElementById('c-cw-rpm').value)                       ?? 600,
+            cw_max_concurrent_requests:           parseInt(document.getElementById('c-cw-max-concurrent').value)            ?? 10,
+            audit_retention_days:                 parseInt(document.getElementById('c-audit-retention').value)              ?? 365,
+            session_idle_timeout_hours:           parseInt(document.getElementById('c-session-idle').value)                 ?? 24,
+            session_max_lifetime_hours:           parseInt(document.getElementById('c-session-max').value)                  ?? 168,
+            login_max_failures:                   parseInt(document.getElementById('c-login-max').value)                    ?? 10,
+            login_ip_max_failures:                parseInt(document.getElementById('c-login-ip-max').value)                 ?? 50,
+            login_lockout_minutes:                parseInt(document.getElementById('c-login-lockout').value)                ?? 15,
         })
         toast('Config saved', 'success')
     } catch (e) { toast(e.message, 'error') }
@@ -1116,9 +1744,14 @@ async function saveConfig() {
 // Logs
 // ─────────────────────────────────────────────────────────
 let logsPollTimer        = null
+let logsStream           = null
+let logsRenderTimer      = null
 let logsFrozen           = false
 let logsLastEntries      = []
 
+// how many entries the logs tab keeps once the stream has added to the initial buffer
+const logsMaxEntries = 1000
+
 function logsPrefs() {
     try { return JSON.parse(localStorage.getItem('logsPrefs') || '{}') } catch { return {} }
 }
@@ -1137,7 +1770,7 @@ async function loadLogs() {
         const entries = await api('GET', '/logs')
         logsLastEntries = entries || []
         renderLogs(logsLastEntries)
-        startLogsPoll()
+        startLogsStream()
     } catch (e) {
         setContent(`<div class="empty-state">${esc(e.message)}</div>`)
     }
@@ -1255,7 +1888,7 @@ function toggleLogsOptions(e) {
 function toggleLogFreeze() {
     logsFrozen = !logsFrozen
     if (logsFrozen) {
-        stopLogsPoll()
+        stopLogsUpdates()
     } else {
         loadLogs()
     }
@@ -1283,6 +1916,52 @@ function stopLogsPoll() {
     logsPollTimer = null
 }
 
+// startLogsStream appends entries from /logs/stream as they're logged. If the stream drops, it
+// falls back to polling, since reconnecting would silently skip whatever was logged in between.
+function startLogsStream() {
+    if (logsFrozen) return
+    stopLogsUpdates()
+    if (!window.EventSource) { startLogsPoll(); return }
+
+    logsStream = new EventSource('/logs/stream')
+    logsStream.addEventListener('log', ev => {
+        if (currentTab !== 'logs' || logsFrozen) { stopLogsUpdates(); return }
+        appendLogEntry(JSON.parse(ev.data))
+    })
+    logsStream.addEventListener('dropped', ev => {
+        const { count } = JSON.parse(ev.data)
+        appendLogEntry({ time: new Date().toISOString(), level: 'WARN', message: `${count} log entries skipped because the panel fell behind` })
+    })
+    logsStream.onerror = () => {
+        stopLogsStream()
+        startLogsPoll()
+    }
+}
+
+function appendLogEntry(e) {
+    logsLastEntries.push(e)
+    if (logsLastEntries.length > logsMaxEntries) {
+        logsLastEntries.splice(0, logsLastEntries.length - logsMaxEntries)
+    }
+    // batch bursts of entries into one render
+    if (!logsRenderTimer) {
+        logsRenderTimer = setTimeout(() => {
+            logsRenderTimer = null
+            if (currentTab === 'logs') renderLogs(logsLastEntries)
+        }, 250)
+    }
+}
+
+function stopLogsStream() {
+    logsStream?.close()
+    logsStream = null
+}
+
+function stopLogsUpdates() {
+    stopLogsStream()
+    stopLogsPoll()
+}
+
 // ─────────────────────────────────────────────────────────
 // Init
 // ─────────────────────────────────────────────────────────
@@ -1312,5 +1991,6 @@ document.addEventListener('DOMContentLoaded', () => {
         document.getElementById('account-dropdown').classList.add('hidden')
         document.getElementById('logs-options-popup')?.classList.add('hidden')
     })
+    initSSO()
     checkSavedKey()
 })
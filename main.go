@@ -10,15 +10,19 @@ import (
 	"slices"
 	"syscall"
 	"time"
+	_ "time/tzdata" // the runtime image has no zoneinfo, needed for business hours
 
 	"github.com/gin-gonic/gin"
 	"github.com/thecoretg/ticketbot/internal/logging"
+	"github.com/thecoretg/ticketbot/internal/metrics"
 	"github.com/thecoretg/ticketbot/internal/middleware"
+	"github.com/thecoretg/ticketbot/internal/secrets"
 	"github.com/thecoretg/ticketbot/internal/server"
+	"github.com/thecoretg/ticketbot/internal/tracing"
 )
 
 const (
-	gooseMigrationVersion = 5
+	gooseMigrationVersion = 23
 	shutdownTimeout       = 10 * time.Second
 )
 
@@ -40,6 +44,12 @@ func Run() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if len(os.Args[1:]) > 0 && os.Args[1] == "rotate-keys" {
+		res, err := server.RotateKeys(ctx, gooseMigrationVersion)
+		fmt.Printf("sealed %d, re-wrapped %d, failed %d\n", res.Sealed, res.Rewrapped, res.Failed)
+		return err
+	}
+
 	var level slog.LevelVar
 	if os.Getenv("DEBUG") == "true" {
 		level.Set(slog.LevelDebug)
@@ -53,6 +63,14 @@ func Run() error {
 		if err != nil {
 			return fmt.Errorf("creating cloudwatch logger: %w", err)
 		}
+		// deferred first so it runs last, after everything else has logged its shutdown
+		defer func() {
+			flushCtx, flushCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer flushCancel()
+			if err := cwHandler.Close(flushCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "flushing cloudwatch logs: %v (%d records dropped)\n", err, cwHandler.Dropped())
+			}
+		}()
 	}
 
 	var baseLogger *slog.Logger
@@ -64,15 +82,35 @@ func Run() error {
 		baseLogger = logging.NewDefaultLogger(&level)
 	}
 	logBuf := logging.NewBufferHandler(baseLogger.Handler(), 500)
-	logger := slog.New(logBuf)
+
+	// redaction goes in front of the buffer, so secrets are kept out of the panel and stored logs
+	// as well as stdout and CloudWatch
+	redactor := secrets.NewRedactor()
+	logger := slog.New(logging.NewTraceHandler(redactor.Handler(logBuf)))
 	slog.SetDefault(logger)
 
-	a, persister, err := server.NewApp(ctx, gooseMigrationVersion, &level, logBuf)
+	shutdownTracing, err := tracing.Setup(ctx, serverVersion)
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+	defer func() {
+		// the run context is cancelled by now, so spans still queued need a fresh one to flush
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("flushing traces", "error", err)
+		}
+	}()
+	if tracing.Enabled() {
+		slog.Info("exporting traces over otlp")
+	}
+
+	a, persister, err := server.NewApp(ctx, gooseMigrationVersion, &level, logBuf, redactor)
 	if err != nil {
 		return fmt.Errorf("initializing app: %w", err)
 	}
 
-	logBuf.Resize(a.Config.LogBufferSize)
+	logBuf.Resize(a.Config.Load().LogBufferSize)
 	if err := persister.SeedBuffer(ctx); err != nil {
 		slog.Warn("failed to seed log buffer from db", "error", err)
 	}
@@ -91,13 +129,32 @@ func Run() error {
 		slog.Info("SKIP AUTH ENABLED")
 	}
 
+	if _, err := a.Svc.Auth.EncryptSecrets(ctx, false); err != nil {
+		slog.Error("encrypting stored secrets", "error", err)
+	}
+
+	if err := a.Seed(ctx); err != nil {
+		return fmt.Errorf("seeding database: %w", err)
+	}
+
 	if !a.TestFlags.SkipHooks {
 		if err := a.Svc.Hooks.ProcessAllHooks(ctx); err != nil {
 			return fmt.Errorf("processing connectwise hooks: %w", err)
 		}
+		a.Svc.Hooks.StartMonitor(ctx)
+	}
+
+	if err := a.Svc.Sync.MarkInterruptedJobs(ctx); err != nil {
+		slog.Warn("failed to mark interrupted sync jobs", "error", err)
 	}
+	a.Svc.Config.StartListener(ctx)
+	a.StartSecretWatcher(ctx)
+	a.Svc.Sync.StartScheduler(ctx)
+	a.Svc.Audit.StartCleanup(ctx)
+	a.Svc.Auth.StartJanitor(ctx)
 
 	srv := gin.New()
+	srv.Use(middleware.Trace())
 	slogWriter := middleware.NewSlogWriter(logger)
 	srv.Use(gin.LoggerWithConfig(gin.LoggerConfig{Output: slogWriter}))
 	srv.Use(gin.RecoveryWithWriter(slogWriter))
@@ -111,6 +168,23 @@ func Run() error {
 		Addr:    ":" + port,
 		Handler: srv,
 	}
+	// log streams never finish on their own, so end them when shutdown starts
+	httpSrv.RegisterOnShutdown(logBuf.CloseSubscribers)
+
+	// metrics get their own server when they're kept off the main port
+	var metricsSrv *http.Server
+	if mp := a.Creds.MetricsPort; mp != "" {
+		metricsSrv = &http.Server{
+			Addr:    ":" + mp,
+			Handler: metrics.Handler(),
+		}
+		go func() {
+			slog.Info("metrics server starting", "port", mp)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("metrics server error", "error", err)
+			}
+		}()
+	}
 
 	// listen for OS signals (SIGTERM from Docker, SIGINT from Ctrl+C)
 	sigCh := make(chan os.Signal, 1)
@@ -143,6 +217,11 @@ func Run() error {
 	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
 		slog.Error("error during shutdown", "error", err)
 	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("error during metrics server shutdown", "error", err)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Expression indexes are maintained by postgres on every insert/update, so rows
+-- written through the regular upserts are searchable immediately.
+CREATE INDEX IF NOT EXISTS idx_cw_ticket_summary_fts
+    ON cw_ticket USING GIN (to_tsvector('english', summary));
+
+CREATE INDEX IF NOT EXISTS idx_cw_ticket_note_content_fts
+    ON cw_ticket_note USING GIN (to_tsvector('english', coalesce(content, '')));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_cw_ticket_note_content_fts;
+DROP INDEX IF EXISTS idx_cw_ticket_summary_fts;
+-- +goose StatementEnd
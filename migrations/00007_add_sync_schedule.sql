@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sync_state (
+    sync_type      TEXT        PRIMARY KEY,
+    watermark      TIMESTAMPTZ,
+    last_started   TIMESTAMPTZ,
+    last_finished  TIMESTAMPTZ,
+    last_succeeded TIMESTAMPTZ,
+    last_error     TEXT,
+    updated_on     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE app_config ADD COLUMN sync_boards_interval_minutes         INT NOT NULL DEFAULT 1440;
+ALTER TABLE app_config ADD COLUMN sync_recipients_interval_minutes     INT NOT NULL DEFAULT 1440;
+ALTER TABLE app_config ADD COLUMN sync_open_tickets_interval_minutes   INT NOT NULL DEFAULT 0;
+ALTER TABLE app_config ADD COLUMN sync_ticket_updates_interval_minutes INT NOT NULL DEFAULT 5;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN sync_ticket_updates_interval_minutes;
+ALTER TABLE app_config DROP COLUMN sync_open_tickets_interval_minutes;
+ALTER TABLE app_config DROP COLUMN sync_recipients_interval_minutes;
+ALTER TABLE app_config DROP COLUMN sync_boards_interval_minutes;
+DROP TABLE IF EXISTS sync_state;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sync_job (
+    id           SERIAL      PRIMARY KEY,
+    triggered_by TEXT        NOT NULL,
+    status       TEXT        NOT NULL,
+    payload      JSONB       NOT NULL,
+    phases       JSONB       NOT NULL DEFAULT '[]',
+    error        TEXT,
+    started_on   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_on  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_sync_job_started_on ON sync_job(started_on);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sync_job_started_on;
+DROP TABLE IF EXISTS sync_job;
+-- +goose StatementEnd
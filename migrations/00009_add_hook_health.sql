@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE app_config ADD COLUMN hook_check_interval_minutes INT  NOT NULL DEFAULT 15;
+ALTER TABLE app_config ADD COLUMN hook_silence_minutes        INT  NOT NULL DEFAULT 60;
+ALTER TABLE app_config ADD COLUMN hook_alert_recipient_id     INT  REFERENCES webex_recipient(id) ON DELETE SET NULL;
+ALTER TABLE app_config ADD COLUMN business_hours_start        INT  NOT NULL DEFAULT 8;
+ALTER TABLE app_config ADD COLUMN business_hours_end          INT  NOT NULL DEFAULT 17;
+ALTER TABLE app_config ADD COLUMN business_timezone           TEXT NOT NULL DEFAULT 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN business_timezone;
+ALTER TABLE app_config DROP COLUMN business_hours_end;
+ALTER TABLE app_config DROP COLUMN business_hours_start;
+ALTER TABLE app_config DROP COLUMN hook_alert_recipient_id;
+ALTER TABLE app_config DROP COLUMN hook_silence_minutes;
+ALTER TABLE app_config DROP COLUMN hook_check_interval_minutes;
+-- +goose StatementEnd
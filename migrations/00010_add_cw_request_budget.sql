@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE app_config ADD COLUMN cw_requests_per_minute     INT NOT NULL DEFAULT 600;
+ALTER TABLE app_config ADD COLUMN cw_max_concurrent_requests INT NOT NULL DEFAULT 10;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN cw_max_concurrent_requests;
+ALTER TABLE app_config DROP COLUMN cw_requests_per_minute;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE api_user ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('admin', 'operator', 'viewer'));
+ALTER TABLE api_key  ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('admin', 'operator', 'viewer'));
+
+-- everyone had full access before roles existed, so keep it that way until an admin says otherwise
+UPDATE api_user SET role = 'admin';
+UPDATE api_key  SET role = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_key  DROP COLUMN role;
+ALTER TABLE api_user DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE api_key ADD COLUMN label        TEXT   NOT NULL DEFAULT '';
+ALTER TABLE api_key ADD COLUMN scopes       TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_key ADD COLUMN expires_on   TIMESTAMPTZ;
+ALTER TABLE api_key ADD COLUMN last_used_on TIMESTAMPTZ;
+ALTER TABLE api_key ADD COLUMN last_used_ip TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_key DROP COLUMN last_used_ip;
+ALTER TABLE api_key DROP COLUMN last_used_on;
+ALTER TABLE api_key DROP COLUMN expires_on;
+ALTER TABLE api_key DROP COLUMN scopes;
+ALTER TABLE api_key DROP COLUMN label;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_log (
+    id            SERIAL PRIMARY KEY,
+    actor_user_id INT,
+    actor_key_id  INT,
+    actor_role    TEXT        NOT NULL DEFAULT '',
+    action        TEXT        NOT NULL,
+    target_type   TEXT        NOT NULL,
+    target_id     TEXT,
+    before        JSONB,
+    after         JSONB,
+    method        TEXT        NOT NULL DEFAULT '',
+    path          TEXT        NOT NULL DEFAULT '',
+    remote_ip     TEXT        NOT NULL DEFAULT '',
+    user_agent    TEXT        NOT NULL DEFAULT '',
+    created_on    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_on ON audit_log (created_on);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_user_id ON audit_log (actor_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_type, target_id);
+
+ALTER TABLE app_config ADD COLUMN audit_retention_days INT NOT NULL DEFAULT 365;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN audit_retention_days;
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE session ADD COLUMN last_seen_on        TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE session ADD COLUMN absolute_expires_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE session ADD COLUMN ip                  TEXT        NOT NULL DEFAULT '';
+ALTER TABLE session ADD COLUMN user_agent          TEXT        NOT NULL DEFAULT '';
+
+-- existing sessions keep the fixed expiry they were issued with
+UPDATE session SET absolute_expires_at = expires_at, last_seen_on = created_on;
+
+CREATE INDEX IF NOT EXISTS idx_session_user_id ON session (user_id);
+
+ALTER TABLE app_config ADD COLUMN session_idle_timeout_hours INT NOT NULL DEFAULT 24;
+ALTER TABLE app_config ADD COLUMN session_max_lifetime_hours INT NOT NULL DEFAULT 168;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN session_max_lifetime_hours;
+ALTER TABLE app_config DROP COLUMN session_idle_timeout_hours;
+DROP INDEX IF EXISTS idx_session_user_id;
+ALTER TABLE session DROP COLUMN user_agent;
+ALTER TABLE session DROP COLUMN ip;
+ALTER TABLE session DROP COLUMN absolute_expires_at;
+ALTER TABLE session DROP COLUMN last_seen_on;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS login_throttle (
+    scope          TEXT        NOT NULL,
+    subject        TEXT        NOT NULL,
+    failures       INT         NOT NULL DEFAULT 0,
+    last_failed_on TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until   TIMESTAMPTZ,
+    PRIMARY KEY (scope, subject)
+);
+
+CREATE TABLE IF NOT EXISTS login_lockout (
+    id           SERIAL PRIMARY KEY,
+    scope        TEXT        NOT NULL,
+    subject      TEXT        NOT NULL,
+    user_id      INT REFERENCES api_user(id) ON DELETE SET NULL,
+    ip           TEXT        NOT NULL DEFAULT '',
+    failures     INT         NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL,
+    created_on   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_lockout_created_on ON login_lockout (created_on);
+
+ALTER TABLE app_config ADD COLUMN login_max_failures INT NOT NULL DEFAULT 10;
+ALTER TABLE app_config ADD COLUMN login_ip_max_failures INT NOT NULL DEFAULT 50;
+ALTER TABLE app_config ADD COLUMN login_lockout_minutes INT NOT NULL DEFAULT 15;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN login_lockout_minutes;
+ALTER TABLE app_config DROP COLUMN login_ip_max_failures;
+ALTER TABLE app_config DROP COLUMN login_max_failures;
+DROP TABLE IF EXISTS login_lockout;
+DROP TABLE IF EXISTS login_throttle;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE session ADD COLUMN auth_method TEXT NOT NULL DEFAULT 'password';
+
+ALTER TABLE app_config ADD COLUMN require_totp_for_api_keys BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app_config DROP COLUMN require_totp_for_api_keys;
+ALTER TABLE session DROP COLUMN auth_method;
+-- +goose StatementEnd
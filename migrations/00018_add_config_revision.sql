@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS config_revision (
+    id             SERIAL PRIMARY KEY,
+    config         JSONB       NOT NULL,
+    author_user_id INT,
+    author_key_id  INT,
+    rollback_of    INT,
+    created_on     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS config_revision;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Indexes for GET /logs/query. Attribute filters are jsonb containment, which
+-- jsonb_path_ops supports with a smaller index than the default operator class.
+CREATE INDEX IF NOT EXISTS idx_app_log_attrs
+    ON app_log USING GIN (attrs jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS idx_app_log_message_fts
+    ON app_log USING GIN (to_tsvector('simple', message));
+
+-- warnings and errors are a small share of the table, so filtering on level
+-- is worth an index of its own
+CREATE INDEX IF NOT EXISTS idx_app_log_level
+    ON app_log (level, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_app_log_level;
+DROP INDEX IF EXISTS idx_app_log_message_fts;
+DROP INDEX IF EXISTS idx_app_log_attrs;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Links a user to their account at an identity provider, so sign-ins after the first are matched
+-- on the provider's stable subject rather than on email.
+CREATE TABLE IF NOT EXISTS user_sso_identity (
+    issuer     TEXT        NOT NULL,
+    subject    TEXT        NOT NULL,
+    user_id    INT         NOT NULL REFERENCES api_user(id) ON DELETE CASCADE,
+    created_on TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (issuer, subject),
+    UNIQUE (user_id, issuer)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_sso_identity;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE hook_health (
+    id            INT         PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    last_received TIMESTAMPTZ,
+    alert_sent_on TIMESTAMPTZ,
+    updated_on    TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS hook_health;
+-- +goose StatementEnd
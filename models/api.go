@@ -7,13 +7,41 @@ import (
 
 var ErrAPIKeyNotFound = errors.New("api key not found")
 
+var (
+	ErrAPIKeyExpired    = errors.New("api key expired")
+	ErrInvalidKeyExpiry = errors.New("api key expiry must be in the future")
+)
+
 type CreateAPIKeyPayload struct {
 	Email string `json:"email"`
+
+	// Role defaults to the owner's role, and can't be higher than it.
+	Role Role `json:"role,omitempty"`
+
+	// Label is a human-readable name to tell keys apart, like "grafana" or "deploy script".
+	Label string `json:"label,omitempty"`
+
+	// Scopes limits the key to parts of the API, like "sync:write". Empty means unrestricted.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ExpiresOn is when the key stops working. Nil means it never expires.
+	ExpiresOn *time.Time `json:"expires_on,omitempty"`
 }
 
 type CreateAPIKeyResponse struct {
-	Email string `json:"email"`
-	Key   string `json:"key"`
+	ID        int        `json:"id"`
+	Email     string     `json:"email"`
+	Key       string     `json:"key"`
+	Role      Role       `json:"role"`
+	Label     string     `json:"label"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresOn *time.Time `json:"expires_on"`
+}
+
+// RotateAPIKeyPayload controls how long the old key keeps working after a rotation.
+type RotateAPIKeyPayload struct {
+	// GraceMinutes defaults to 60. 0 isn't allowed; delete the key instead to revoke it immediately.
+	GraceMinutes *int `json:"grace_minutes"`
 }
 
 type APIKey struct {
@@ -21,8 +49,35 @@ type APIKey struct {
 	UserID    int       `json:"user_id"`
 	KeyHash   []byte    `json:"key_hash"`
 	KeyHint   *string   `json:"key_hint,omitempty"`
+	Role      Role      `json:"role"`
+	Label     string    `json:"label"`
+	Scopes    []string  `json:"scopes"`
 	CreatedOn time.Time `json:"created_on"`
 	UpdatedOn time.Time `json:"updated_on"`
+
+	ExpiresOn  *time.Time `json:"expires_on"`
+	LastUsedOn *time.Time `json:"last_used_on"`
+	LastUsedIP *string    `json:"last_used_ip"`
+
+	// LookupID is the public part of the key used to find it without checking every hash. Keys
+	// created before lookup IDs existed have none until they're first used.
+	LookupID *string `json:"lookup_id,omitempty"`
+}
+
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresOn != nil && !now.Before(*k.ExpiresOn)
+}
+
+// Actor is who a request is acting as, as set by the auth middleware. APIKeyID and Scopes are only
+// set when the request used an API key, and SessionID only when it used a session cookie.
+type Actor struct {
+	UserID    int
+	Role      Role
+	APIKeyID  *int
+	Scopes    []string
+	SessionID *int
+
+	Restriction Restriction
 }
 
 var ErrAPIUserNotFound = errors.New("api user not found")
@@ -30,10 +85,24 @@ var ErrAPIUserNotFound = errors.New("api user not found")
 type APIUser struct {
 	ID           int       `json:"id"`
 	EmailAddress string    `json:"email_address"`
+	Role         Role      `json:"role"`
 	CreatedOn    time.Time `json:"created_on"`
 	UpdatedOn    time.Time `json:"updated_on"`
 }
 
+// CurrentUser is the authenticated user along with the role their request actually has, which is
+// lower than their own role if they authenticated with a key issued at a lower role. Restriction
+// is set if their session is limited until they finish account setup.
+type CurrentUser struct {
+	APIUser
+	EffectiveRole Role        `json:"effective_role"`
+	Restriction   Restriction `json:"restriction,omitempty"`
+}
+
+type SetRolePayload struct {
+	Role Role `json:"role"`
+}
+
 // UserAuth is a restricted view of APIUser used only during login.
 // It is separate from APIUser to preserve comparability ([]byte is not comparable).
 type UserAuth struct {
@@ -43,4 +112,15 @@ type UserAuth struct {
 	ResetRequired bool
 	TOTPSecret    *string
 	TOTPEnabled   bool
+	Role          Role
 }
+
+// Restriction is account setup a user has to finish before they can use anything else. Sessions
+// with a restriction can only reach the routes needed to finish it.
+type Restriction string
+
+const (
+	RestrictionNone          Restriction = ""
+	RestrictionPasswordReset Restriction = "password_reset_required"
+	RestrictionTOTPSetup     Restriction = "totp_setup_required"
+)
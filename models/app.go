@@ -2,7 +2,10 @@ package models
 
 import "errors"
 
-var ErrConfigNotFound = errors.New("config not found")
+var (
+	ErrConfigNotFound = errors.New("config not found")
+	ErrInvalidConfig  = errors.New("invalid config")
+)
 
 type Config struct {
 	// The app will only ever have one config in the config table, so this will always just be 1.
@@ -23,6 +26,14 @@ type Config struct {
 	// RequireTOTP enforces that all users must have TOTP enabled to access the application.
 	RequireTOTP bool `json:"require_totp"`
 
+	// RequireTOTPForAPIKeys extends RequireTOTP to API keys, which stop working until their owner
+	// enables TOTP.
+	RequireTOTPForAPIKeys bool `json:"require_totp_for_api_keys"`
+
+	// SSOSatisfiesTOTP exempts SSO sessions from RequireTOTP, trusting the identity provider to
+	// handle its own second factor.
+	SSOSatisfiesTOTP bool `json:"sso_satisfies_totp"`
+
 	// DebugLogging enables debug-level log output at runtime without a server restart.
 	DebugLogging bool `json:"debug_logging"`
 
@@ -34,6 +45,71 @@ type Config struct {
 
 	// LogBufferSize is how many log entries to keep in the in-memory ring buffer.
 	LogBufferSize int `json:"log_buffer_size"`
+
+	// SyncBoardsIntervalMinutes is how often boards and their statuses are synced from Connectwise.
+	// 0 disables the scheduled sync.
+	SyncBoardsIntervalMinutes int `json:"sync_boards_interval_minutes"`
+
+	// SyncRecipientsIntervalMinutes is how often Webex rooms and people are synced. 0 disables it.
+	SyncRecipientsIntervalMinutes int `json:"sync_recipients_interval_minutes"`
+
+	// SyncOpenTicketsIntervalMinutes is how often every open ticket is pulled from Connectwise.
+	// This is expensive on large boards, so it is disabled (0) by default.
+	SyncOpenTicketsIntervalMinutes int `json:"sync_open_tickets_interval_minutes"`
+
+	// SyncTicketUpdatesIntervalMinutes is how often tickets updated since the last successful run
+	// are pulled from Connectwise, to catch anything missed by webhooks. 0 disables it.
+	SyncTicketUpdatesIntervalMinutes int `json:"sync_ticket_updates_interval_minutes"`
+
+	// HookCheckIntervalMinutes is how often the Connectwise callbacks are checked, and recreated or
+	// reactivated if needed. 0 disables the check after startup.
+	HookCheckIntervalMinutes int `json:"hook_check_interval_minutes"`
+
+	// HookSilenceMinutes is how long no Connectwise callbacks can arrive during business hours
+	// before an alert is sent to HookAlertRecipientID. 0 disables the alert.
+	HookSilenceMinutes int `json:"hook_silence_minutes"`
+
+	// HookAlertRecipientID is the Webex recipient that hook silence alerts are sent to. No alerts
+	// are sent if it is nil.
+	HookAlertRecipientID *int `json:"hook_alert_recipient_id"`
+
+	// BusinessHoursStart and BusinessHoursEnd are the hours of the day (0-24) in BusinessTimezone,
+	// Monday to Friday, that hook silence alerts are sent during.
+	BusinessHoursStart int    `json:"business_hours_start"`
+	BusinessHoursEnd   int    `json:"business_hours_end"`
+	BusinessTimezone   string `json:"business_timezone"`
+
+	// CWRequestsPerMinute is the request budget shared by everything that calls the Connectwise API,
+	// including syncs and webhooks. 0 removes the limit.
+	CWRequestsPerMinute int `json:"cw_requests_per_minute"`
+
+	// CWMaxConcurrentRequests caps how many Connectwise requests can be in flight at once. 0 removes the cap.
+	CWMaxConcurrentRequests int `json:"cw_max_concurrent_requests"`
+
+	// AuditRetentionDays is how many days of audit log entries to keep. 0 keeps them forever.
+	AuditRetentionDays int `json:"audit_retention_days"`
+
+	// SessionIdleTimeoutHours is how long a panel session lasts without activity. Each request pushes
+	// its expiry forward, up to SessionMaxLifetimeHours after login.
+	SessionIdleTimeoutHours int `json:"session_idle_timeout_hours"`
+	SessionMaxLifetimeHours int `json:"session_max_lifetime_hours"`
+
+	// LoginMaxFailures and LoginIPMaxFailures are how many failed sign-in attempts an account or
+	// a client IP gets before it's locked out for LoginLockoutMinutes. Attempts are slowed down
+	// progressively before that, and a lockout soon after another lasts twice as long, up to a day.
+	LoginMaxFailures    int `json:"login_max_failures"`
+	LoginIPMaxFailures  int `json:"login_ip_max_failures"`
+	LoginLockoutMinutes int `json:"login_lockout_minutes"`
+}
+
+// Clone returns a copy of c that shares no memory with it.
+func (c *Config) Clone() *Config {
+	n := *c
+	if c.HookAlertRecipientID != nil {
+		id := *c.HookAlertRecipientID
+		n.HookAlertRecipientID = &id
+	}
+	return &n
 }
 
 // ConfigUpdateParams is used for partial updates to Config. Pointer fields allow
@@ -47,6 +123,35 @@ type ConfigUpdateParams struct {
 	LogRetentionDays        *int  `json:"log_retention_days"`
 	LogCleanupIntervalHours *int  `json:"log_cleanup_interval_hours"`
 	LogBufferSize           *int  `json:"log_buffer_size"`
+
+	SyncBoardsIntervalMinutes        *int `json:"sync_boards_interval_minutes"`
+	SyncRecipientsIntervalMinutes    *int `json:"sync_recipients_interval_minutes"`
+	SyncOpenTicketsIntervalMinutes   *int `json:"sync_open_tickets_interval_minutes"`
+	SyncTicketUpdatesIntervalMinutes *int `json:"sync_ticket_updates_interval_minutes"`
+
+	HookCheckIntervalMinutes *int `json:"hook_check_interval_minutes"`
+	HookSilenceMinutes       *int `json:"hook_silence_minutes"`
+
+	// HookAlertRecipientID clears the alert recipient when set to 0.
+	HookAlertRecipientID *int    `json:"hook_alert_recipient_id"`
+	BusinessHoursStart   *int    `json:"business_hours_start"`
+	BusinessHoursEnd     *int    `json:"business_hours_end"`
+	BusinessTimezone     *string `json:"business_timezone"`
+
+	CWRequestsPerMinute     *int `json:"cw_requests_per_minute"`
+	CWMaxConcurrentRequests *int `json:"cw_max_concurrent_requests"`
+
+	AuditRetentionDays *int `json:"audit_retention_days"`
+
+	SessionIdleTimeoutHours *int `json:"session_idle_timeout_hours"`
+	SessionMaxLifetimeHours *int `json:"session_max_lifetime_hours"`
+
+	LoginMaxFailures    *int `json:"login_max_failures"`
+	LoginIPMaxFailures  *int `json:"login_ip_max_failures"`
+	LoginLockoutMinutes *int `json:"login_lockout_minutes"`
+
+	RequireTOTPForAPIKeys *bool `json:"require_totp_for_api_keys"`
+	SSOSatisfiesTOTP      *bool `json:"sso_satisfies_totp"`
 }
 
 var DefaultConfig = Config{
@@ -55,8 +160,32 @@ var DefaultConfig = Config{
 	MaxMessageLength:        300,
 	MaxConcurrentSyncs:      5,
 	RequireTOTP:             false,
+	SSOSatisfiesTOTP:        true,
 	DebugLogging:            false,
 	LogRetentionDays:        7,
 	LogCleanupIntervalHours: 24,
 	LogBufferSize:           500,
+
+	SyncBoardsIntervalMinutes:        1440,
+	SyncRecipientsIntervalMinutes:    1440,
+	SyncOpenTicketsIntervalMinutes:   0,
+	SyncTicketUpdatesIntervalMinutes: 5,
+
+	HookCheckIntervalMinutes: 15,
+	HookSilenceMinutes:       60,
+	BusinessHoursStart:       8,
+	BusinessHoursEnd:         17,
+	BusinessTimezone:         "UTC",
+
+	CWRequestsPerMinute:     600,
+	CWMaxConcurrentRequests: 10,
+
+	AuditRetentionDays: 365,
+
+	SessionIdleTimeoutHours: 24,
+	SessionMaxLifetimeHours: 168,
+
+	LoginMaxFailures:    10,
+	LoginIPMaxFailures:  50,
+	LoginLockoutMinutes: 15,
 }
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is what an audited request did, as <target>.<verb>.
+type AuditAction string
+
+const (
+	AuditConfigUpdate   AuditAction = "config.update"
+	AuditConfigRollback AuditAction = "config.rollback"
+	AuditConfigImport   AuditAction = "config.import"
+
+	AuditUserCreate  AuditAction = "user.create"
+	AuditUserDelete  AuditAction = "user.delete"
+	AuditUserSetRole AuditAction = "user.set_role"
+
+	AuditAPIKeyCreate AuditAction = "api_key.create"
+	AuditAPIKeyRotate AuditAction = "api_key.rotate"
+	AuditAPIKeyDelete AuditAction = "api_key.delete"
+
+	AuditNotifierRuleCreate AuditAction = "notifier_rule.create"
+	AuditNotifierRuleDelete AuditAction = "notifier_rule.delete"
+	AuditForwardCreate      AuditAction = "notifier_forward.create"
+	AuditForwardDelete      AuditAction = "notifier_forward.delete"
+
+	AuditSyncStart  AuditAction = "sync_job.start"
+	AuditSyncCancel AuditAction = "sync_job.cancel"
+
+	AuditPasswordChange AuditAction = "user.password_change"
+	AuditTOTPEnable     AuditAction = "user.totp_enable"
+	AuditTOTPDisable    AuditAction = "user.totp_disable"
+
+	AuditSessionRevoke      AuditAction = "session.revoke"
+	AuditUserSessionsRevoke AuditAction = "user.sessions_revoke"
+	AuditUserUnlock         AuditAction = "user.unlock"
+	AuditIPUnlock           AuditAction = "ip.unlock"
+
+	AuditServerRestart AuditAction = "server.restart"
+)
+
+const (
+	AuditTargetConfig          = "config"
+	AuditTargetUser            = "user"
+	AuditTargetAPIKey          = "api_key"
+	AuditTargetNotifierRule    = "notifier_rule"
+	AuditTargetNotifierForward = "notifier_forward"
+	AuditTargetSyncJob         = "sync_job"
+	AuditTargetServer          = "server"
+	AuditTargetSession         = "session"
+	AuditTargetIP              = "ip"
+)
+
+// AuditEntry is a record of a change made through the API. ActorKeyID is only set if the request
+// used an API key. Before and After are the target as JSON, and either is empty if there was
+// nothing to record, like Before for a create.
+type AuditEntry struct {
+	ID          int             `json:"id"`
+	ActorUserID *int            `json:"actor_user_id"`
+	ActorKeyID  *int            `json:"actor_key_id"`
+	ActorRole   Role            `json:"actor_role"`
+	Action      AuditAction     `json:"action"`
+	TargetType  string          `json:"target_type"`
+	TargetID    *string         `json:"target_id"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	RemoteIP    string          `json:"remote_ip"`
+	UserAgent   string          `json:"user_agent"`
+	CreatedOn   time.Time       `json:"created_on"`
+}
+
+// AuditFilter narrows GET /audit. Nil fields are ignored; Since and Until filter on created_on.
+type AuditFilter struct {
+	ActorUserID *int
+	ActorKeyID  *int
+	Action      *string
+	TargetType  *string
+	TargetID    *string
+	Since       *time.Time
+	Until       *time.Time
+	Cursor      *int
+	Limit       int
+}
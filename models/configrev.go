@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrConfigRevisionNotFound = errors.New("config revision not found")
+
+// ConfigRevision is the whole config as it was saved by one change. The author fields are unset
+// for the baseline revision recorded before the first tracked change, and RollbackOf is the
+// revision a rollback restored.
+type ConfigRevision struct {
+	Revision     int             `json:"revision"`
+	Config       json.RawMessage `json:"config"`
+	AuthorUserID *int            `json:"author_user_id"`
+	AuthorKeyID  *int            `json:"author_key_id"`
+	RollbackOf   *int            `json:"rollback_of"`
+	CreatedOn    time.Time       `json:"created_on"`
+	// Changes is what differs from the revision before it, and is empty for the first one.
+	Changes []ConfigChange `json:"changes"`
+}
+
+// ConfigChange is one field that differs between two revisions, by its JSON name.
+type ConfigChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
@@ -78,6 +78,33 @@ type FullTicket struct {
 	Resources  []*Member
 }
 
+// TicketFilter narrows a ticket search over the local store. Nil fields are ignored.
+// Results are ordered newest-first by ticket ID; Cursor is the last ID of the previous page.
+type TicketFilter struct {
+	BoardID      *int
+	StatusID     *int
+	Closed       *bool
+	CompanyID    *int
+	OwnerID      *int
+	ResourceID   *int
+	UpdatedSince *time.Time
+	Search       *string
+	Cursor       *int
+	Limit        int
+}
+
+// TicketDetail is a stored ticket with its related entities and all of its notes.
+type TicketDetail struct {
+	Ticket    Ticket            `json:"ticket"`
+	Board     Board             `json:"board"`
+	Status    TicketStatus      `json:"status"`
+	Company   Company           `json:"company"`
+	Contact   *Contact          `json:"contact"`
+	Owner     *Member           `json:"owner"`
+	Resources []*Member         `json:"resources"`
+	Notes     []*FullTicketNote `json:"notes"`
+}
+
 var ErrTicketNoteNotFound = errors.New("ticket note not found")
 
 type TicketNote struct {
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// CWClientStats reports the state of the shared Connectwise client: its circuit breaker, backoff,
+// and per-endpoint request counts and latencies.
+type CWClientStats struct {
+	Circuit             string            `json:"circuit"`
+	ConsecutiveFailures int               `json:"consecutive_failures"`
+	OpenedOn            *time.Time        `json:"opened_on"`
+	BackoffUntil        *time.Time        `json:"backoff_until"`
+	InFlight            int               `json:"in_flight"`
+	Deferred            int               `json:"deferred"`
+	RequestsPerMinute   int               `json:"requests_per_minute"`
+	MaxConcurrent       int               `json:"max_concurrent_requests"`
+	Endpoints           []CWEndpointStats `json:"endpoints"`
+}
+
+type CWEndpointStats struct {
+	Endpoint     string     `json:"endpoint"`
+	Requests     int64      `json:"requests"`
+	Errors       int64      `json:"errors"`
+	Throttled    int64      `json:"throttled"`
+	Rejected     int64      `json:"rejected"`
+	AvgLatencyMS float64    `json:"avg_latency_ms"`
+	MaxLatencyMS float64    `json:"max_latency_ms"`
+	LastRequest  *time.Time `json:"last_request"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidImport = errors.New("invalid import document")
+
+// ExportVersion is the document version Export writes and Import accepts.
+const ExportVersion = 1
+
+// ExportDocument is a deployment's config, notifier rules and forwards, with boards and
+// recipients referred to by name rather than by ID so it can be applied to another database.
+// On import, a section that is left out is not touched, while an empty one removes everything
+// in it.
+type ExportDocument struct {
+	Version  int             `json:"version"`
+	Config   map[string]any  `json:"config,omitempty"`
+	Rules    []ExportRule    `json:"rules"`
+	Forwards []ExportForward `json:"forwards"`
+}
+
+// RecipientRef names a Webex recipient: a room by its title, or a person by their email.
+type RecipientRef struct {
+	Room   string `json:"room,omitempty"`
+	Person string `json:"person,omitempty"`
+}
+
+// ExportRule is a notifier rule, identified by its board and recipient. Enabled defaults to
+// true if it is left out.
+type ExportRule struct {
+	Board     string       `json:"board"`
+	Recipient RecipientRef `json:"recipient"`
+	Enabled   *bool        `json:"enabled,omitempty"`
+}
+
+// ExportForward is a forward, identified by its source, destination and dates. Enabled and
+// UserKeepsCopy default to true if they are left out.
+type ExportForward struct {
+	Source        RecipientRef `json:"source"`
+	Destination   RecipientRef `json:"destination"`
+	StartDate     *time.Time   `json:"start_date,omitempty"`
+	EndDate       *time.Time   `json:"end_date,omitempty"`
+	Enabled       *bool        `json:"enabled,omitempty"`
+	UserKeepsCopy *bool        `json:"user_keeps_copy,omitempty"`
+}
+
+type ImportAction string
+
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+	ImportDelete ImportAction = "delete"
+)
+
+// ImportChange is a rule or forward that an import creates, updates or deletes. Before is empty
+// for a create and After for a delete.
+type ImportChange struct {
+	Kind   string       `json:"kind"`
+	Action ImportAction `json:"action"`
+	Key    string       `json:"key"`
+	Before any          `json:"before,omitempty"`
+	After  any          `json:"after,omitempty"`
+}
+
+// ImportResult is what an import changed, or would change if it was a dry run.
+type ImportResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Config  []ConfigChange `json:"config"`
+	Changes []ImportChange `json:"changes"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// HookHealth reports when Connectwise callbacks were last received and reconciled.
+type HookHealth struct {
+	LastReceived   *time.Time `json:"last_received"`
+	LastChecked    *time.Time `json:"last_checked"`
+	LastCheckError *string    `json:"last_check_error"`
+	Reactivated    int        `json:"reactivated"`
+	AlertSent      bool       `json:"alert_sent"`
+}
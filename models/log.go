@@ -1,5 +1,39 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidLogLevel = errors.New("invalid log level")
+
+// LogLevels are the levels persisted logs are recorded with, from least to most severe.
+var LogLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+// LevelsAtLeast returns min and every level more severe than it.
+func LevelsAtLeast(min string) ([]string, error) {
+	i := slices.Index(LogLevels, strings.ToUpper(min))
+	if i == -1 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLogLevel, min)
+	}
+	return LogLevels[i:], nil
+}
+
+// LogFilter narrows GET /logs/query. Nil and empty fields are ignored. Text matches words in the
+// message, and Attrs matches attribute values by key, with dots for nested groups like board.id.
+type LogFilter struct {
+	Levels []string
+	Since  *time.Time
+	Until  *time.Time
+	Text   *string
+	Attrs  map[string]string
+	Cursor *int
+	Limit  int
+}
+
 // Config implements logging.LogConfig so it can be passed directly to the persister.
 func (c *Config) GetLogRetentionDays() int        { return c.LogRetentionDays }
 func (c *Config) GetLogCleanupIntervalHours() int { return c.LogCleanupIntervalHours }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrLoginThrottleNotFound = errors.New("login throttle not found")
+	// ErrLoginAttemptRefused is returned when an attempt can't be counted because the account or IP
+	// is locked out, at its limit, or still waiting after its last attempt.
+	ErrLoginAttemptRefused = errors.New("login attempt refused")
+)
+
+// Failed sign-in attempts are counted against both the account and the client IP they came from.
+const (
+	ThrottleScopeAccount = "account"
+	ThrottleScopeIP      = "ip"
+)
+
+// LoginThrottle is the failed-attempt count for one account (by email address) or client IP.
+// Attempts are counted when they start, so Failures includes any still being checked, and
+// LastFailedOn is when the last attempt started. Lockouts counts recent lockouts, each of which
+// lasts twice as long as the one before.
+type LoginThrottle struct {
+	Scope        string     `json:"scope"`
+	Subject      string     `json:"subject"`
+	Failures     int        `json:"failures"`
+	LastFailedOn time.Time  `json:"last_failed_on"`
+	LockedUntil  *time.Time `json:"locked_until"`
+	Lockouts     int        `json:"lockouts"`
+}
+
+// LoginLockout records an account or IP being locked out. UserID is set for account lockouts of
+// users that exist.
+type LoginLockout struct {
+	ID          int       `json:"id"`
+	Scope       string    `json:"scope"`
+	Subject     string    `json:"subject"`
+	UserID      *int      `json:"user_id"`
+	IP          string    `json:"ip"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+	CreatedOn   time.Time `json:"created_on"`
+}
+
+// LockoutReport is what admins see: everything locked right now, and recent lockout events.
+type LockoutReport struct {
+	Active []LoginThrottle `json:"active"`
+	Recent []LoginLockout  `json:"recent"`
+}
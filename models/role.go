@@ -0,0 +1,47 @@
+package models
+
+import "errors"
+
+var ErrInvalidRole = errors.New("invalid role")
+
+// Role controls what a user or API key can do. Each role includes everything the roles below it can do.
+type Role string
+
+const (
+	// RoleViewer can read everything except users, keys and other admin-only data.
+	RoleViewer Role = "viewer"
+	// RoleOperator can also run syncs and manage notifier rules and forwards.
+	RoleOperator Role = "operator"
+	// RoleAdmin can do anything, including changing config and managing users and keys.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// AtLeast reports whether r grants everything min does.
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && r.rank() >= min.rank()
+}
+
+// LowerRole returns whichever of a and b grants less.
+func LowerRole(a, b Role) Role {
+	if a.rank() <= b.rank() {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidScope = errors.New("invalid scope")
+
+// ScopeAreas are the parts of the API a key can be scoped to. A scope is an area and an access level,
+// like "sync:write" or "notifiers:read". Write access includes read access.
+var ScopeAreas = []string{
+	"account",
+	"admin",
+	"audit",
+	"config",
+	"cw",
+	"hooks",
+	"keys",
+	"logs",
+	"metrics",
+	"notifiers",
+	"search",
+	"sync",
+	"users",
+	"webex",
+}
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// ValidScope reports whether s is a known area followed by read or write.
+func ValidScope(s string) bool {
+	area, level, ok := strings.Cut(s, ":")
+	return ok && slices.Contains(ScopeAreas, area) && (level == ScopeRead || level == ScopeWrite)
+}
+
+// ScopeAllows reports whether scopes grant access to area. An empty list is unrestricted, since keys
+// created before scopes existed have none.
+func ScopeAllows(scopes []string, area string, write bool) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	if slices.Contains(scopes, area+":"+ScopeWrite) {
+		return true
+	}
+
+	return !write && slices.Contains(scopes, area+":"+ScopeRead)
+}
+
+// ScopesWithin reports whether every scope in requested is granted by granted, so a scoped key
+// can't be used to create a key with more access than itself.
+func ScopesWithin(requested, granted []string) bool {
+	if len(granted) == 0 {
+		return true
+	}
+	if len(requested) == 0 {
+		return false
+	}
+
+	for _, s := range requested {
+		area, level, _ := strings.Cut(s, ":")
+		if !ScopeAllows(granted, area, level == ScopeWrite) {
+			return false
+		}
+	}
+
+	return true
+}
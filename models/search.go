@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrEmptySearchQuery = errors.New("search query cannot be empty")
+
+type SearchHitKind string
+
+const (
+	SearchHitTicket SearchHitKind = "ticket"
+	SearchHitNote   SearchHitKind = "note"
+)
+
+// SearchParams is a full-text query over ticket summaries and note content.
+// Since and Until filter on the hit's updated_on; nil fields are ignored.
+type SearchParams struct {
+	Query     string
+	BoardID   *int
+	CompanyID *int
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+}
+
+// SearchHit is a single ranked match. NoteID is only set for note hits.
+//
+// Snippet is HTML: the matched text escaped, with matched terms wrapped in <mark></mark>. It's the
+// only field that is. Summary and the rest are plain text straight from Connectwise, so clients
+// must not put them in the page with innerHTML or the like; set them as text or escape them.
+type SearchHit struct {
+	Kind      SearchHitKind `json:"kind"`
+	TicketID  int           `json:"ticket_id"`
+	NoteID    *int          `json:"note_id"`
+	Summary   string        `json:"summary"`
+	BoardID   int           `json:"board_id"`
+	CompanyID int           `json:"company_id"`
+	Snippet   string        `json:"snippet"`
+	Rank      float32       `json:"rank"`
+	UpdatedOn time.Time     `json:"updated_on"`
+}
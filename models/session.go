@@ -7,10 +7,41 @@ import (
 
 var ErrSessionNotFound = errors.New("session not found")
 
+// How a session was signed in. SSO sessions aren't held to local password or TOTP requirements,
+// since the identity provider owns those.
+const (
+	SessionAuthPassword = "password"
+	SessionAuthSSO      = "sso"
+)
+
 type Session struct {
-	ID        int
-	UserID    int
-	TokenHash []byte
-	ExpiresAt time.Time
-	CreatedOn time.Time
+	ID                int
+	UserID            int
+	TokenHash         []byte
+	ExpiresAt         time.Time
+	AbsoluteExpiresAt time.Time
+	LastSeenOn        time.Time
+	IP                string
+	UserAgent         string
+	AuthMethod        string
+	CreatedOn         time.Time
+}
+
+// SessionClient describes the browser a session was issued to or last seen from.
+type SessionClient struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionInfo is the view of a session returned to its owner. Current marks the session
+// making the request.
+type SessionInfo struct {
+	ID         int       `json:"id"`
+	CreatedOn  time.Time `json:"created_on"`
+	LastSeenOn time.Time `json:"last_seen_on"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	AuthMethod string    `json:"auth_method"`
+	Current    bool      `json:"current"`
 }
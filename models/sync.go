@@ -1,13 +1,104 @@
 package models
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSyncStateNotFound = errors.New("sync state not found")
+	ErrSyncJobNotFound   = errors.New("sync job not found")
+	ErrSyncJobNotRunning = errors.New("sync job is not running")
+	ErrSyncInProgress    = errors.New("sync already in progress")
+)
+
+// SyncType identifies a kind of sync for scheduling and run history.
+type SyncType string
+
+const (
+	SyncTypeBoards        SyncType = "cw_boards"
+	SyncTypeRecipients    SyncType = "webex_recipients"
+	SyncTypeOpenTickets   SyncType = "cw_open_tickets"
+	SyncTypeTicketUpdates SyncType = "cw_ticket_updates"
+)
+
+// SyncJobStatus is used for both whole jobs and their individual phases.
+type SyncJobStatus string
+
+const (
+	SyncJobRunning   SyncJobStatus = "running"
+	SyncJobSucceeded SyncJobStatus = "succeeded"
+	SyncJobFailed    SyncJobStatus = "failed"
+	SyncJobCancelled SyncJobStatus = "cancelled"
+)
+
+const (
+	SyncTriggerManual   = "manual"
+	SyncTriggerSchedule = "schedule"
+)
+
 type SyncStatusResponse struct {
-	Status bool `json:"status"`
+	Status bool         `json:"status"`
+	Job    *SyncJob     `json:"job,omitempty"`
+	Runs   []*SyncState `json:"runs,omitempty"`
 }
 
 type SyncPayload struct {
 	WebexRecipients    bool  `json:"webex_recipients"`
 	CWBoards           bool  `json:"cw_boards"`
 	CWTickets          bool  `json:"cw_tickets"`
+	CWTicketUpdates    bool  `json:"cw_ticket_updates"`
 	BoardIDs           []int `json:"board_ids"`
 	MaxConcurrentSyncs int   `json:"max_concurrent_syncs"`
 }
+
+// SyncState holds the last run results for a sync type. Watermark is only used by
+// the incremental ticket sync, and marks the start of its last successful run.
+type SyncState struct {
+	SyncType      SyncType   `json:"sync_type"`
+	Watermark     *time.Time `json:"watermark"`
+	LastStarted   *time.Time `json:"last_started"`
+	LastFinished  *time.Time `json:"last_finished"`
+	LastSucceeded *time.Time `json:"last_succeeded"`
+	LastError     *string    `json:"last_error"`
+	UpdatedOn     time.Time  `json:"updated_on"`
+}
+
+// SyncJob is a single sync run, started manually or by the scheduler. Each sync type
+// requested in the payload runs as its own phase.
+type SyncJob struct {
+	ID          int           `json:"id"`
+	TriggeredBy string        `json:"triggered_by"`
+	Status      SyncJobStatus `json:"status"`
+	Payload     SyncPayload   `json:"payload"`
+	Phases      []*SyncPhase  `json:"phases"`
+	Error       *string       `json:"error"`
+	StartedOn   time.Time     `json:"started_on"`
+	FinishedOn  *time.Time    `json:"finished_on"`
+}
+
+type SyncPhase struct {
+	Type        SyncType        `json:"type"`
+	Status      SyncJobStatus   `json:"status"`
+	Fetched     int             `json:"fetched"`
+	Upserted    int             `json:"upserted"`
+	SoftDeleted int             `json:"soft_deleted"`
+	Failed      int             `json:"failed"`
+	Errors      []SyncItemError `json:"errors,omitempty"`
+	Error       *string         `json:"error"`
+	StartedOn   *time.Time      `json:"started_on"`
+	FinishedOn  *time.Time      `json:"finished_on"`
+}
+
+// SyncItemError is a failure for a single item (ticket, board, etc.) within a phase.
+// The phase keeps going after these.
+type SyncItemError struct {
+	Item  string    `json:"item"`
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+type SyncJobFilter struct {
+	Cursor *int
+	Limit  int
+}
@@ -2,16 +2,47 @@
 SELECT * FROM api_key
 WHERE id = $1;
 
+-- name: GetAPIKeyByLookupID :one
+SELECT * FROM api_key
+WHERE lookup_id = $1;
+
 -- name: ListAPIKeys :many
 SELECT * FROM api_key
 ORDER BY created_on;
 
+-- name: ListLegacyAPIKeys :many
+SELECT * FROM api_key
+WHERE lookup_id IS NULL
+ORDER BY created_on;
+
 -- name: InsertAPIKey :one
 INSERT INTO api_key
-(user_id, key_hash, key_hint)
-VALUES ($1, $2, $3)
+(user_id, key_hash, key_hint, role, label, scopes, expires_on, lookup_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: SetAPIKeyExpiry :one
+UPDATE api_key
+SET
+    expires_on = $2,
+    updated_on = NOW()
+WHERE id = $1
 RETURNING *;
 
+-- name: SetAPIKeyLastUsed :exec
+UPDATE api_key
+SET
+    last_used_on = NOW(),
+    last_used_ip = $2
+WHERE id = $1;
+
+-- name: SetAPIKeyLookupID :exec
+UPDATE api_key
+SET
+    lookup_id = $2,
+    updated_on = NOW()
+WHERE id = $1;
+
 -- name: SoftDeleteAPIKey :one
 UPDATE api_key
 SET
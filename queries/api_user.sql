@@ -19,8 +19,8 @@ ORDER BY email_address;
 
 -- name: InsertUser :one
 INSERT INTO api_user
-(email_address)
-VALUES ($1)
+(email_address, role)
+VALUES ($1, $2)
 RETURNING *;
 
 -- name: UpdateUser :one
@@ -31,6 +31,31 @@ SET
 WHERE id = $1
 RETURNING *;
 
+-- name: SetUserRole :one
+UPDATE api_user
+SET
+    role = $2,
+    updated_on = NOW()
+WHERE id = $1
+RETURNING *;
+
 -- name: DeleteUser :exec
 DELETE FROM api_user
 WHERE id = $1;
+
+-- name: GetUserBySSOIdentity :one
+SELECT api_user.* FROM api_user
+JOIN user_sso_identity i ON i.user_id = api_user.id
+WHERE i.issuer = $1 AND i.subject = $2;
+
+-- name: CheckUserSSOIdentityExists :one
+SELECT EXISTS(
+    SELECT 1
+    FROM user_sso_identity
+    WHERE user_id = $1 AND issuer = $2
+) as exists;
+
+-- name: InsertUserSSOIdentity :exec
+INSERT INTO user_sso_identity
+(issuer, subject, user_id)
+VALUES ($1, $2, $3);
@@ -2,14 +2,22 @@
 SELECT * FROM app_config
 WHERE id = 1;
 
+-- name: GetAppConfigForUpdate :one
+SELECT * FROM app_config
+WHERE id = 1
+FOR UPDATE;
+
 -- name: InsertDefaultAppConfig :one
 INSERT INTO app_config (id) VALUES (1)
 ON CONFLICT (id) DO UPDATE SET id = EXCLUDED.id
 RETURNING *;
 
+-- name: NotifyAppConfigChanged :exec
+SELECT pg_notify('app_config_changed', sqlc.arg('origin')::text);
+
 -- name: UpsertAppConfig :one
-INSERT INTO app_config(id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size)
-VALUES(1, $1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO app_config(id, attempt_notify, max_message_length, max_concurrent_syncs, require_totp, debug_logging, log_retention_days, log_cleanup_interval_hours, log_buffer_size, sync_boards_interval_minutes, sync_recipients_interval_minutes, sync_open_tickets_interval_minutes, sync_ticket_updates_interval_minutes, hook_check_interval_minutes, hook_silence_minutes, hook_alert_recipient_id, business_hours_start, business_hours_end, business_timezone, cw_requests_per_minute, cw_max_concurrent_requests, audit_retention_days, session_idle_timeout_hours, session_max_lifetime_hours, login_max_failures, login_ip_max_failures, login_lockout_minutes, require_totp_for_api_keys, sso_satisfies_totp)
+VALUES(1, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28)
 ON CONFLICT (id) DO UPDATE SET
     attempt_notify = EXCLUDED.attempt_notify,
     max_message_length = EXCLUDED.max_message_length,
@@ -18,6 +26,26 @@ ON CONFLICT (id) DO UPDATE SET
     debug_logging = EXCLUDED.debug_logging,
     log_retention_days = EXCLUDED.log_retention_days,
     log_cleanup_interval_hours = EXCLUDED.log_cleanup_interval_hours,
-    log_buffer_size = EXCLUDED.log_buffer_size
+    log_buffer_size = EXCLUDED.log_buffer_size,
+    sync_boards_interval_minutes = EXCLUDED.sync_boards_interval_minutes,
+    sync_recipients_interval_minutes = EXCLUDED.sync_recipients_interval_minutes,
+    sync_open_tickets_interval_minutes = EXCLUDED.sync_open_tickets_interval_minutes,
+    sync_ticket_updates_interval_minutes = EXCLUDED.sync_ticket_updates_interval_minutes,
+    hook_check_interval_minutes = EXCLUDED.hook_check_interval_minutes,
+    hook_silence_minutes = EXCLUDED.hook_silence_minutes,
+    hook_alert_recipient_id = EXCLUDED.hook_alert_recipient_id,
+    business_hours_start = EXCLUDED.business_hours_start,
+    business_hours_end = EXCLUDED.business_hours_end,
+    business_timezone = EXCLUDED.business_timezone,
+    cw_requests_per_minute = EXCLUDED.cw_requests_per_minute,
+    cw_max_concurrent_requests = EXCLUDED.cw_max_concurrent_requests,
+    audit_retention_days = EXCLUDED.audit_retention_days,
+    session_idle_timeout_hours = EXCLUDED.session_idle_timeout_hours,
+    session_max_lifetime_hours = EXCLUDED.session_max_lifetime_hours,
+    login_max_failures = EXCLUDED.login_max_failures,
+    login_ip_max_failures = EXCLUDED.login_ip_max_failures,
+    login_lockout_minutes = EXCLUDED.login_lockout_minutes,
+    require_totp_for_api_keys = EXCLUDED.require_totp_for_api_keys,
+    sso_satisfies_totp = EXCLUDED.sso_satisfies_totp
 RETURNING *;
 
@@ -0,0 +1,21 @@
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg('actor_user_id')::int IS NULL OR actor_user_id = sqlc.narg('actor_user_id')::int)
+    AND (sqlc.narg('actor_key_id')::int IS NULL OR actor_key_id = sqlc.narg('actor_key_id')::int)
+    AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action')::text)
+    AND (sqlc.narg('target_type')::text IS NULL OR target_type = sqlc.narg('target_type')::text)
+    AND (sqlc.narg('target_id')::text IS NULL OR target_id = sqlc.narg('target_id')::text)
+    AND (sqlc.narg('since')::timestamptz IS NULL OR created_on >= sqlc.narg('since')::timestamptz)
+    AND (sqlc.narg('until')::timestamptz IS NULL OR created_on < sqlc.narg('until')::timestamptz)
+    AND (sqlc.narg('cursor')::int IS NULL OR id < sqlc.narg('cursor')::int)
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit')::int;
+
+-- name: InsertAuditEntry :one
+INSERT INTO audit_log
+(actor_user_id, actor_key_id, actor_role, action, target_type, target_id, before, after, method, path, remote_ip, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
+-- name: DeleteAuditEntriesBefore :execrows
+DELETE FROM audit_log WHERE created_on < $1;
@@ -1,10 +1,10 @@
 -- name: GetUserForAuth :one
-SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, created_on, updated_on
+SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, role, created_on, updated_on
 FROM api_user
 WHERE email_address = $1 LIMIT 1;
 
 -- name: GetUserForAuthByID :one
-SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, created_on, updated_on
+SELECT id, email_address, password_hash, password_reset_required, totp_secret, totp_enabled, role, created_on, updated_on
 FROM api_user
 WHERE id = $1 LIMIT 1;
 
@@ -16,6 +16,16 @@ WHERE id = $1;
 -- name: SetTOTPSecret :exec
 UPDATE api_user SET totp_secret = $2, updated_on = NOW() WHERE id = $1;
 
+-- name: ListTOTPSecrets :many
+SELECT id, totp_secret FROM api_user
+WHERE totp_secret IS NOT NULL
+ORDER BY id;
+
+-- name: ReplaceTOTPSecret :execrows
+UPDATE api_user
+SET totp_secret = sqlc.arg('new_secret'), updated_on = NOW()
+WHERE id = sqlc.arg('id') AND totp_secret = sqlc.arg('old_secret');
+
 -- name: SetTOTPEnabled :exec
 UPDATE api_user SET totp_enabled = $2, updated_on = NOW() WHERE id = $1;
 
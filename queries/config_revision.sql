@@ -0,0 +1,17 @@
+-- name: GetConfigRevision :one
+SELECT * FROM config_revision WHERE id = $1;
+
+-- name: GetLatestConfigRevision :one
+SELECT * FROM config_revision ORDER BY id DESC LIMIT 1;
+
+-- name: ListConfigRevisions :many
+SELECT * FROM config_revision
+WHERE sqlc.narg('cursor')::int IS NULL OR id < sqlc.narg('cursor')::int
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit')::int;
+
+-- name: InsertConfigRevision :one
+INSERT INTO config_revision
+(config, author_user_id, author_key_id, rollback_of)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
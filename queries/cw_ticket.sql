@@ -6,6 +6,26 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM cw_ticket
 ORDER BY id;
 
+-- name: SearchTickets :many
+SELECT t.* FROM cw_ticket t
+JOIN cw_ticket_status s ON s.id = t.status_id
+WHERE t.deleted = FALSE
+    AND (sqlc.narg('board_id')::int IS NULL OR t.board_id = sqlc.narg('board_id')::int)
+    AND (sqlc.narg('status_id')::int IS NULL OR t.status_id = sqlc.narg('status_id')::int)
+    AND (sqlc.narg('closed')::boolean IS NULL OR s.closed = sqlc.narg('closed')::boolean)
+    AND (sqlc.narg('company_id')::int IS NULL OR t.company_id = sqlc.narg('company_id')::int)
+    AND (sqlc.narg('owner_id')::int IS NULL OR t.owner_id = sqlc.narg('owner_id')::int)
+    AND (sqlc.narg('resource_id')::int IS NULL OR EXISTS (
+        SELECT 1 FROM cw_member m
+        WHERE m.id = sqlc.narg('resource_id')::int
+            AND m.identifier = ANY(string_to_array(replace(t.resources, ' ', ''), ','))
+    ))
+    AND (sqlc.narg('updated_since')::timestamp IS NULL OR t.updated_on >= sqlc.narg('updated_since')::timestamp)
+    AND (sqlc.narg('search')::text IS NULL OR t.summary ILIKE '%' || sqlc.narg('search')::text || '%')
+    AND (sqlc.narg('cursor')::int IS NULL OR t.id < sqlc.narg('cursor')::int)
+ORDER BY t.id DESC
+LIMIT sqlc.arg('page_limit')::int;
+
 -- name: CheckTicketExists :one
 SELECT EXISTS (
     SELECT 1
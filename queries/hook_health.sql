@@ -0,0 +1,25 @@
+-- name: ClaimHookSilenceAlert :execrows
+-- Claims the silence alert unless one was already sent since the last callback or day_start.
+INSERT INTO hook_health (id, alert_sent_on)
+VALUES (1, sqlc.arg('sent_on'))
+ON CONFLICT (id) DO UPDATE SET
+    alert_sent_on = EXCLUDED.alert_sent_on,
+    updated_on = NOW()
+WHERE hook_health.alert_sent_on IS NULL
+   OR hook_health.alert_sent_on < GREATEST(hook_health.last_received, sqlc.arg('day_start')::timestamptz);
+
+-- name: GetHookLastReceived :one
+SELECT last_received FROM hook_health
+WHERE id = 1 LIMIT 1;
+
+-- name: ReleaseHookSilenceAlert :exec
+UPDATE hook_health
+SET alert_sent_on = NULL, updated_on = NOW()
+WHERE id = 1 AND alert_sent_on = $1;
+
+-- name: SetHookLastReceived :exec
+INSERT INTO hook_health (id, last_received)
+VALUES (1, $1)
+ON CONFLICT (id) DO UPDATE SET
+    last_received = GREATEST(hook_health.last_received, EXCLUDED.last_received),
+    updated_on = NOW();
@@ -0,0 +1,82 @@
+-- name: GetLoginThrottle :one
+SELECT * FROM login_throttle
+WHERE scope = $1 AND subject = $2
+LIMIT 1;
+
+-- name: ListLockedLoginThrottles :many
+SELECT * FROM login_throttle
+WHERE locked_until > NOW()
+ORDER BY locked_until DESC;
+
+-- name: ClaimLoginAttempt :one
+-- Counts an attempt before it's checked, so parallel attempts can't all get in under the limit.
+-- Nothing is counted, and no row returned, if the key is locked, already has max_failures
+-- attempts in the window, or is still waiting out the delay after the last one. The delay
+-- doubles from one second after free_failures attempts, up to max_delay_seconds.
+INSERT INTO login_throttle (scope, subject, failures, last_failed_on)
+VALUES (sqlc.arg('scope'), sqlc.arg('subject'), 1, NOW())
+ON CONFLICT (scope, subject) DO UPDATE SET
+    failures = CASE
+        WHEN login_throttle.last_failed_on < sqlc.arg('window_start')::timestamptz THEN 1
+        ELSE login_throttle.failures + 1
+    END,
+    last_failed_on = NOW()
+WHERE (login_throttle.locked_until IS NULL OR login_throttle.locked_until <= NOW())
+    AND (
+        login_throttle.last_failed_on < sqlc.arg('window_start')::timestamptz
+        OR (
+            login_throttle.failures < sqlc.arg('max_failures')::int
+            AND login_throttle.last_failed_on + make_interval(secs => CASE
+                WHEN login_throttle.failures < sqlc.arg('free_failures')::int THEN 0
+                ELSE LEAST(power(2, login_throttle.failures - sqlc.arg('free_failures')::int), sqlc.arg('max_delay_seconds')::float8)
+            END) <= NOW()
+        )
+    )
+RETURNING *;
+
+-- name: ReleaseLoginAttempt :exec
+UPDATE login_throttle
+SET failures = GREATEST(failures - 1, 0)
+WHERE scope = $1 AND subject = $2;
+
+-- name: LockLoginThrottle :one
+-- Locks for base_seconds, doubled for each lockout that ended after escalate_since, up to
+-- max_seconds. The failure count starts over, but the lockout count doesn't.
+UPDATE login_throttle
+SET lockouts = CASE
+        WHEN locked_until IS NULL OR locked_until < sqlc.arg('escalate_since')::timestamptz THEN 1
+        ELSE lockouts + 1
+    END,
+    locked_until = NOW() + make_interval(secs => LEAST(
+        sqlc.arg('base_seconds')::float8 * power(2, CASE
+            WHEN locked_until IS NULL OR locked_until < sqlc.arg('escalate_since')::timestamptz THEN 0
+            ELSE lockouts
+        END),
+        sqlc.arg('max_seconds')::float8
+    )),
+    failures = 0
+WHERE scope = sqlc.arg('scope') AND subject = sqlc.arg('subject')
+RETURNING *;
+
+-- name: DeleteLoginThrottle :execrows
+DELETE FROM login_throttle
+WHERE scope = $1 AND subject = $2;
+
+-- name: DeleteStaleLoginThrottles :exec
+-- Keeps rows locked since locked_before, so their lockout count can still escalate the next one.
+DELETE FROM login_throttle
+WHERE last_failed_on < sqlc.arg('failed_before')::timestamptz
+  AND (locked_until IS NULL OR locked_until < sqlc.arg('locked_before')::timestamptz);
+
+-- name: InsertLoginLockout :one
+INSERT INTO login_lockout (scope, subject, user_id, ip, failures, locked_until)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListLoginLockouts :many
+SELECT * FROM login_lockout
+ORDER BY created_on DESC
+LIMIT sqlc.arg('page_limit')::int;
+
+-- name: DeleteLoginLockoutsBefore :exec
+DELETE FROM login_lockout WHERE created_on < $1;
@@ -129,3 +129,12 @@ RETURNING *;
 -- name: DeleteNotifierForward :exec
 DELETE FROM notifier_forward
 WHERE id = $1;
+
+-- name: UpdateNotifierForward :one
+UPDATE notifier_forward
+SET
+    enabled = $2,
+    user_keeps_copy = $3,
+    updated_on = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,46 @@
+-- name: SearchTicketText :many
+-- Matches the expressions of the GIN indexes added in migration 00006, so both branches of the
+-- union are index scans. Highlight sentinels are stripped from the text before ts_headline adds
+-- its own, so the caller can tell them apart from content.
+WITH q AS (SELECT websearch_to_tsquery('english', sqlc.arg('query')::text) AS query)
+SELECT kind, ticket_id, note_id, summary, board_id, company_id, snippet, rank, updated_on FROM (
+    SELECT
+        'ticket'::text AS kind,
+        t.id AS ticket_id,
+        NULL::int AS note_id,
+        t.summary,
+        t.board_id,
+        t.company_id,
+        ts_headline('english', translate(t.summary, E'\x02\x03', ''), q.query, sqlc.arg('headline_opts')::text) AS snippet,
+        ts_rank(to_tsvector('english', t.summary), q.query) AS rank,
+        t.updated_on
+    FROM cw_ticket t
+    CROSS JOIN q
+    WHERE to_tsvector('english', t.summary) @@ q.query
+        AND t.deleted = FALSE
+
+    UNION ALL
+
+    SELECT
+        'note',
+        n.ticket_id,
+        n.id,
+        t.summary,
+        t.board_id,
+        t.company_id,
+        ts_headline('english', translate(coalesce(n.content, ''), E'\x02\x03', ''), q.query, sqlc.arg('headline_opts')::text),
+        ts_rank(to_tsvector('english', coalesce(n.content, '')), q.query),
+        n.updated_on
+    FROM cw_ticket_note n
+    JOIN cw_ticket t ON t.id = n.ticket_id
+    CROSS JOIN q
+    WHERE to_tsvector('english', coalesce(n.content, '')) @@ q.query
+        AND n.deleted = FALSE
+        AND t.deleted = FALSE
+) hits
+WHERE (sqlc.narg('board_id')::int IS NULL OR board_id = sqlc.narg('board_id')::int)
+    AND (sqlc.narg('company_id')::int IS NULL OR company_id = sqlc.narg('company_id')::int)
+    AND (sqlc.narg('since')::timestamp IS NULL OR updated_on >= sqlc.narg('since')::timestamp)
+    AND (sqlc.narg('until')::timestamp IS NULL OR updated_on < sqlc.narg('until')::timestamp)
+ORDER BY rank DESC, updated_on DESC
+LIMIT sqlc.arg('page_limit')::int;
@@ -1,6 +1,6 @@
 -- name: CreateSession :one
-INSERT INTO session (user_id, token_hash, expires_at)
-VALUES ($1, $2, $3)
+INSERT INTO session (user_id, token_hash, expires_at, absolute_expires_at, ip, user_agent, auth_method)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetSessionByTokenHash :one
@@ -8,8 +8,29 @@ SELECT * FROM session
 WHERE token_hash = $1 AND expires_at > NOW()
 LIMIT 1;
 
+-- name: ListSessionsByUser :many
+SELECT * FROM session
+WHERE user_id = $1 AND expires_at > NOW()
+ORDER BY last_seen_on DESC;
+
+-- name: TouchSession :exec
+UPDATE session
+SET last_seen_on = NOW(),
+    expires_at = $2,
+    ip = $3,
+    user_agent = $4
+WHERE id = $1;
+
 -- name: DeleteSession :exec
 DELETE FROM session WHERE id = $1;
 
+-- name: DeleteUserSession :execrows
+DELETE FROM session WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteUserSessions :execrows
+DELETE FROM session
+WHERE user_id = $1
+  AND (sqlc.narg('keep_id')::int IS NULL OR id <> sqlc.narg('keep_id')::int);
+
 -- name: DeleteExpiredSessions :exec
 DELETE FROM session WHERE expires_at < NOW();
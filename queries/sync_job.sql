@@ -0,0 +1,35 @@
+-- name: ListSyncJobs :many
+SELECT * FROM sync_job
+WHERE sqlc.narg('cursor')::int IS NULL OR id < sqlc.narg('cursor')::int
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit')::int;
+
+-- name: GetSyncJob :one
+SELECT * FROM sync_job
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateSyncJob :one
+INSERT INTO sync_job (triggered_by, status, payload, phases)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateSyncJob :one
+UPDATE sync_job
+SET
+    status = $2,
+    phases = $3,
+    error = $4,
+    finished_on = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: InterruptRunningSyncJobs :execrows
+UPDATE sync_job
+SET
+    status = 'failed',
+    error = 'interrupted by server shutdown',
+    finished_on = NOW()
+WHERE status = 'running';
+
+-- name: DeleteSyncJobsBefore :exec
+DELETE FROM sync_job WHERE started_on < $1;
@@ -0,0 +1,25 @@
+-- name: ListSyncStates :many
+SELECT * FROM sync_state
+ORDER BY sync_type;
+
+-- name: GetSyncState :one
+SELECT * FROM sync_state
+WHERE sync_type = $1 LIMIT 1;
+
+-- name: RecordSyncRun :one
+INSERT INTO sync_state (sync_type, last_started, last_finished, last_succeeded, last_error)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (sync_type) DO UPDATE SET
+    last_started = EXCLUDED.last_started,
+    last_finished = EXCLUDED.last_finished,
+    last_succeeded = COALESCE(EXCLUDED.last_succeeded, sync_state.last_succeeded),
+    last_error = EXCLUDED.last_error,
+    updated_on = NOW()
+RETURNING *;
+
+-- name: SetSyncWatermark :exec
+INSERT INTO sync_state (sync_type, watermark)
+VALUES ($1, $2)
+ON CONFLICT (sync_type) DO UPDATE SET
+    watermark = EXCLUDED.watermark,
+    updated_on = NOW();
@@ -0,0 +1,9 @@
+package sdk
+
+import "github.com/thecoretg/ticketbot/models"
+
+// ListAuditEntries returns audit log entries, newest first. params can filter by actor_user_id,
+// actor_key_id, action, target_type, target_id, since and until (RFC3339). limit sets the page size; every page is fetched.
+func (c *Client) ListAuditEntries(params map[string]string) ([]models.AuditEntry, error) {
+	return GetMany[models.AuditEntry](c, "audit", params)
+}
@@ -18,3 +18,39 @@ func (c *Client) UpdateConfig(params *models.Config) (*models.Config, error) {
 
 	return cfg, nil
 }
+
+// GetConfigHistory lists config revisions newest first, with what each changed. Admin only.
+func (c *Client) GetConfigHistory(params map[string]string) ([]models.ConfigRevision, error) {
+	return GetMany[models.ConfigRevision](c, "config/history", params)
+}
+
+// RollbackConfig restores the config from an earlier revision. Admin only.
+func (c *Client) RollbackConfig(revision int) (*models.Config, error) {
+	cfg := &models.Config{}
+	if err := c.Post(fmt.Sprintf("config/rollback/%d", revision), nil, cfg); err != nil {
+		return nil, fmt.Errorf("sending rollback request: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Export returns the config, notifier rules and forwards as a document that Import accepts.
+func (c *Client) Export() (*models.ExportDocument, error) {
+	return GetOne[models.ExportDocument](c, "export", map[string]string{"format": "json"})
+}
+
+// Import changes the config, notifier rules and forwards to match doc. With dryRun, nothing is
+// changed and the result is what would be. Admin only.
+func (c *Client) Import(doc *models.ExportDocument, dryRun bool) (*models.ImportResult, error) {
+	endpoint := "import"
+	if dryRun {
+		endpoint += "?dry_run=true"
+	}
+
+	res := &models.ImportResult{}
+	if err := c.Post(endpoint, doc, res); err != nil {
+		return nil, fmt.Errorf("sending import request: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,7 @@
+package sdk
+
+import "github.com/thecoretg/ticketbot/models"
+
+func (c *Client) GetCWClientStats() (*models.CWClientStats, error) {
+	return GetOne[models.CWClientStats](c, "cw/client/stats", nil)
+}
@@ -0,0 +1,15 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+func (c *Client) ListCompanies() ([]models.Company, error) {
+	return GetMany[models.Company](c, "cw/companies", nil)
+}
+
+func (c *Client) GetCompany(id int) (*models.Company, error) {
+	return GetOne[models.Company](c, fmt.Sprintf("cw/companies/%d", id), nil)
+}
@@ -0,0 +1,15 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+func (c *Client) ListContacts() ([]models.Contact, error) {
+	return GetMany[models.Contact](c, "cw/contacts", nil)
+}
+
+func (c *Client) GetContact(id int) (*models.Contact, error) {
+	return GetOne[models.Contact](c, fmt.Sprintf("cw/contacts/%d", id), nil)
+}
@@ -0,0 +1,20 @@
+package sdk
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+func (c *Client) ListStatuses() ([]models.TicketStatus, error) {
+	return GetMany[models.TicketStatus](c, "cw/statuses", nil)
+}
+
+func (c *Client) ListStatusesByBoard(boardID int) ([]models.TicketStatus, error) {
+	return GetMany[models.TicketStatus](c, "cw/statuses", map[string]string{"board_id": strconv.Itoa(boardID)})
+}
+
+func (c *Client) GetStatus(id int) (*models.TicketStatus, error) {
+	return GetOne[models.TicketStatus](c, fmt.Sprintf("cw/statuses/%d", id), nil)
+}
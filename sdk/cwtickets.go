@@ -0,0 +1,18 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// ListTickets searches stored tickets, following pagination until all pages are read.
+// Supported params: board_id, status_id, closed, company_id, owner_id, resource_id,
+// updated_since (RFC3339), q, and limit (page size).
+func (c *Client) ListTickets(params map[string]string) ([]models.Ticket, error) {
+	return GetMany[models.Ticket](c, "cw/tickets", params)
+}
+
+func (c *Client) GetTicket(id int) (*models.TicketDetail, error) {
+	return GetOne[models.TicketDetail](c, fmt.Sprintf("cw/tickets/%d", id), nil)
+}
@@ -0,0 +1,7 @@
+package sdk
+
+import "github.com/thecoretg/ticketbot/models"
+
+func (c *Client) GetHookHealth() (*models.HookHealth, error) {
+	return GetOne[models.HookHealth](c, "hooks/health", nil)
+}
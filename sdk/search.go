@@ -0,0 +1,22 @@
+package sdk
+
+import (
+	"errors"
+
+	"github.com/thecoretg/ticketbot/models"
+)
+
+// Search runs a full-text query over ticket summaries and notes. Supported params:
+// board_id, company_id, since and until (RFC3339), and limit.
+func (c *Client) Search(query string, params map[string]string) ([]models.SearchHit, error) {
+	if query == "" {
+		return nil, errors.New("no query provided")
+	}
+
+	p := map[string]string{"q": query}
+	for k, v := range params {
+		p[k] = v
+	}
+
+	return GetMany[models.SearchHit](c, "search", p)
+}
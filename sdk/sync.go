@@ -15,6 +15,39 @@ func (c *Client) GetSyncStatus() (bool, error) {
 	return s.Status, nil
 }
 
+// GetSyncRuns returns the last run results for each sync type.
+func (c *Client) GetSyncRuns() ([]*models.SyncState, error) {
+	s, err := GetOne[models.SyncStatusResponse](c, "sync/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting sync status: %w", err)
+	}
+
+	return s.Runs, nil
+}
+
 func (c *Client) Sync(payload *models.SyncPayload) error {
-	return c.Post("sync", payload, nil)
+	_, err := c.StartSync(payload)
+	return err
+}
+
+// StartSync starts a sync and returns its job, which can be polled with GetSyncJob.
+func (c *Client) StartSync(payload *models.SyncPayload) (*models.SyncJob, error) {
+	j := &models.SyncJob{}
+	if err := c.Post("sync", payload, j); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (c *Client) ListSyncJobs(params map[string]string) ([]models.SyncJob, error) {
+	return GetMany[models.SyncJob](c, "sync/jobs", params)
+}
+
+func (c *Client) GetSyncJob(id int) (*models.SyncJob, error) {
+	return GetOne[models.SyncJob](c, fmt.Sprintf("sync/jobs/%d", id), nil)
+}
+
+func (c *Client) CancelSyncJob(id int) error {
+	return c.Post(fmt.Sprintf("sync/jobs/%d/cancel", id), nil, nil)
 }
@@ -3,12 +3,14 @@ package sdk
 import (
 	"errors"
 	"fmt"
+	"net/url"
 
 	"github.com/thecoretg/ticketbot/models"
 )
 
-func (c *Client) GetCurrentUser() (*models.APIUser, error) {
-	return GetOne[models.APIUser](c, "users/me", nil)
+// GetCurrentUser returns the authenticated user, including the effective role of the client's key.
+func (c *Client) GetCurrentUser() (*models.CurrentUser, error) {
+	return GetOne[models.CurrentUser](c, "users/me", nil)
 }
 
 func (c *Client) ListUsers() ([]models.APIUser, error) {
@@ -20,8 +22,14 @@ func (c *Client) GetUser(email string) (*models.APIUser, error) {
 }
 
 func (c *Client) CreateUser(email string) (*models.APIUser, error) {
+	return c.CreateUserWithRole(email, "")
+}
+
+// CreateUserWithRole creates a user with the given role. An empty role creates a viewer.
+func (c *Client) CreateUserWithRole(email string, role models.Role) (*models.APIUser, error) {
 	p := &models.APIUser{
 		EmailAddress: email,
+		Role:         role,
 	}
 
 	u := &models.APIUser{}
@@ -33,6 +41,20 @@ func (c *Client) CreateUser(email string) (*models.APIUser, error) {
 	return u, nil
 }
 
+func (c *Client) SetUserRole(id int, role models.Role) (*models.APIUser, error) {
+	if id == 0 {
+		return nil, errors.New("no id provided")
+	}
+
+	p := &models.SetRolePayload{Role: role}
+	u := &models.APIUser{}
+	if err := c.Put(fmt.Sprintf("users/%d/role", id), p, u); err != nil {
+		return nil, fmt.Errorf("putting to server: %w", err)
+	}
+
+	return u, nil
+}
+
 func (c *Client) DeleteUser(id int) error {
 	if id == 0 {
 		return errors.New("no id provided")
@@ -41,21 +63,104 @@ func (c *Client) DeleteUser(id int) error {
 	return c.Delete(fmt.Sprintf("users/%d", id))
 }
 
+// ListSessions returns the panel sessions of the key's owner.
+func (c *Client) ListSessions() ([]models.SessionInfo, error) {
+	return GetMany[models.SessionInfo](c, "auth/sessions", nil)
+}
+
+func (c *Client) RevokeSession(id int) error {
+	if id == 0 {
+		return errors.New("no id provided")
+	}
+
+	return c.Delete(fmt.Sprintf("auth/sessions/%d", id))
+}
+
+// RevokeAllSessions signs the key's owner out of every panel session. API key requests have no
+// session of their own to keep.
+func (c *Client) RevokeAllSessions() error {
+	return c.Delete("auth/sessions")
+}
+
+// RevokeUserSessions signs another user out of every panel session. Admin only.
+func (c *Client) RevokeUserSessions(userID int) error {
+	if userID == 0 {
+		return errors.New("no id provided")
+	}
+
+	return c.Delete(fmt.Sprintf("users/%d/sessions", userID))
+}
+
+// GetLockouts returns current sign-in lockouts and recent lockout events. Admin only.
+func (c *Client) GetLockouts() (*models.LockoutReport, error) {
+	return GetOne[models.LockoutReport](c, "users/lockouts", nil)
+}
+
+// UnlockUser clears a user's sign-in lockout. Admin only.
+func (c *Client) UnlockUser(userID int) error {
+	if userID == 0 {
+		return errors.New("no id provided")
+	}
+
+	return c.Delete(fmt.Sprintf("users/%d/lockout", userID))
+}
+
+// UnlockIP clears a client IP's sign-in lockout. Admin only.
+func (c *Client) UnlockIP(ip string) error {
+	if ip == "" {
+		return errors.New("no ip provided")
+	}
+
+	return c.Delete("users/lockouts/ip/" + url.PathEscape(ip))
+}
+
 func (c *Client) ListAPIKeys() ([]models.APIKey, error) {
 	return GetMany[models.APIKey](c, "users/keys", nil)
 }
 
 func (c *Client) CreateAPIKey(email string) (string, error) {
-	p := &models.CreateAPIKeyPayload{
-		Email: email,
+	k, err := c.CreateAPIKeyWithRole(email, "")
+	if err != nil {
+		return "", err
 	}
 
+	return k.Key, nil
+}
+
+// CreateAPIKeyWithRole creates a key limited to role, which can't be higher than the owner's.
+// An empty role gives the key the owner's role.
+func (c *Client) CreateAPIKeyWithRole(email string, role models.Role) (*models.CreateAPIKeyResponse, error) {
+	return c.CreateAPIKeyWithOptions(&models.CreateAPIKeyPayload{
+		Email: email,
+		Role:  role,
+	})
+}
+
+// CreateAPIKeyWithOptions creates a key with any of the payload's optional settings: a label,
+// scopes such as "sync:write", and an expiry.
+func (c *Client) CreateAPIKeyWithOptions(p *models.CreateAPIKeyPayload) (*models.CreateAPIKeyResponse, error) {
 	k := &models.CreateAPIKeyResponse{}
 	if err := c.Post("users/keys", p, k); err != nil {
-		return "", fmt.Errorf("posting to server: %w", err)
+		return nil, fmt.Errorf("posting to server: %w", err)
 	}
 
-	return k.Key, nil
+	return k, nil
+}
+
+// RotateAPIKey issues a replacement for a key with the same role, scopes and label. The old key keeps
+// working for graceMinutes, or the server default if nil, so clients can switch over.
+func (c *Client) RotateAPIKey(id int, graceMinutes *int) (*models.CreateAPIKeyResponse, error) {
+	if id == 0 {
+		return nil, errors.New("no id provided")
+	}
+
+	p := &models.RotateAPIKeyPayload{GraceMinutes: graceMinutes}
+	k := &models.CreateAPIKeyResponse{}
+	if err := c.Post(fmt.Sprintf("users/keys/%d/rotate", id), p, k); err != nil {
+		return nil, fmt.Errorf("posting to server: %w", err)
+	}
+
+	return k, nil
 }
 
 func (c *Client) DeleteAPIKey(id int) error {